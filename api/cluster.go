@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"syspeek/config"
+)
+
+// PeerStatus is one peer's entry in /api/cluster/summary
+type PeerStatus struct {
+	Name      string          `json:"name"`
+	URL       string          `json:"url"`
+	Online    bool            `json:"online"`
+	Error     string          `json:"error,omitempty"`
+	Summary   json.RawMessage `json:"summary,omitempty"` // The peer's /api/summary, passed through as-is
+	FetchedAt time.Time       `json:"fetchedAt"`
+}
+
+type ClusterSummary struct {
+	Peers   map[string]PeerStatus `json:"peers"` // Keyed by peer hostname
+	Online  int                   `json:"online"`
+	Offline int                   `json:"offline"`
+}
+
+// clusterClient polls peer syspeek instances. Each peer keeps its own
+// session token and a short-lived cached result.
+type clusterClient struct {
+	cfg    config.ClusterConfig
+	client *http.Client
+	// insecure is used for peers with self-signed certificates
+	insecure *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]string     // peer URL -> session token
+	cache  map[string]PeerStatus // peer URL -> last result
+}
+
+func newClusterClient(cfg config.ClusterConfig) *clusterClient {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &clusterClient{
+		cfg:    cfg,
+		client: &http.Client{Timeout: timeout},
+		insecure: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+		tokens: make(map[string]string),
+		cache:  make(map[string]PeerStatus),
+	}
+}
+
+func (c *clusterClient) httpClient(peer config.PeerConfig) *http.Client {
+	if peer.InsecureTLS {
+		return c.insecure
+	}
+	return c.client
+}
+
+// login authenticates against the peer and returns its session token
+func (c *clusterClient) login(peer config.PeerConfig) (string, error) {
+	body, _ := json.Marshal(LoginRequest{Username: peer.Username, Password: peer.Password})
+	resp, err := c.httpClient(peer).Post(peer.URL+"/api/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("login failed: %s", resp.Status)
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "session" {
+			return cookie.Value, nil
+		}
+	}
+	return "", fmt.Errorf("login failed: no session returned")
+}
+
+func (c *clusterClient) get(peer config.PeerConfig, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, peer.URL+"/api/summary", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return c.httpClient(peer).Do(req)
+}
+
+// fetchSummary gets the peer's /api/summary, logging in first (or again,
+// if the session expired) when credentials are configured.
+func (c *clusterClient) fetchSummary(peer config.PeerConfig) (json.RawMessage, error) {
+	c.mu.Lock()
+	token := c.tokens[peer.URL]
+	c.mu.Unlock()
+
+	resp, err := c.get(peer, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && peer.Username != "" {
+		resp.Body.Close()
+		if token, err = c.login(peer); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.tokens[peer.URL] = token
+		c.mu.Unlock()
+
+		if resp, err = c.get(peer, token); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var summary json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("invalid summary: %v", err)
+	}
+	return summary, nil
+}
+
+func (c *clusterClient) peerStatus(peer config.PeerConfig) PeerStatus {
+	cacheTTL := time.Duration(c.cfg.CacheSeconds) * time.Second
+
+	c.mu.Lock()
+	cached, ok := c.cache[peer.URL]
+	c.mu.Unlock()
+	if ok && time.Since(cached.FetchedAt) < cacheTTL {
+		return cached
+	}
+
+	status := PeerStatus{Name: peer.Name, URL: peer.URL, FetchedAt: time.Now()}
+	if summary, err := c.fetchSummary(peer); err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Online = true
+		status.Summary = summary
+	}
+
+	c.mu.Lock()
+	c.cache[peer.URL] = status
+	c.mu.Unlock()
+
+	return status
+}
+
+// Summary queries all peers concurrently
+func (c *clusterClient) Summary() ClusterSummary {
+	result := ClusterSummary{Peers: make(map[string]PeerStatus)}
+
+	statuses := make([]PeerStatus, len(c.cfg.Peers))
+	var wg sync.WaitGroup
+	for i, peer := range c.cfg.Peers {
+		wg.Add(1)
+		go func(i int, peer config.PeerConfig) {
+			defer wg.Done()
+			statuses[i] = c.peerStatus(peer)
+		}(i, peer)
+	}
+	wg.Wait()
+
+	for _, status := range statuses {
+		result.Peers[peerKey(status, result.Peers)] = status
+		if status.Online {
+			result.Online++
+		} else {
+			result.Offline++
+		}
+	}
+
+	return result
+}
+
+// peerKey prefers the hostname the peer reports about itself, then the
+// configured name, then the host part of its URL, skipping any key already
+// taken so two peers reporting the same hostname both stay listed.
+func peerKey(status PeerStatus, taken map[string]PeerStatus) string {
+	var candidates []string
+	if status.Online {
+		var s struct {
+			Hostname string `json:"hostname"`
+		}
+		if json.Unmarshal(status.Summary, &s) == nil && s.Hostname != "" {
+			candidates = append(candidates, s.Hostname)
+		}
+	}
+	if status.Name != "" {
+		candidates = append(candidates, status.Name)
+	}
+	if u, err := url.Parse(status.URL); err == nil && u.Host != "" {
+		candidates = append(candidates, u.Host)
+	}
+	candidates = append(candidates, status.URL)
+
+	for _, key := range candidates {
+		if _, ok := taken[key]; !ok {
+			return key
+		}
+	}
+	// The same URL configured twice
+	for n := 2; ; n++ {
+		key := fmt.Sprintf("%s#%d", status.URL, n)
+		if _, ok := taken[key]; !ok {
+			return key
+		}
+	}
+}
+
+// HandleClusterSummary returns the combined summary of all configured peers.
+// Peers keep their startup values across reloads, so the check goes by the
+// peers the client actually polls.
+func (a *API) HandleClusterSummary(w http.ResponseWriter, r *http.Request) {
+	if len(a.cluster.cfg.Peers) == 0 {
+		writeJSON(w, http.StatusNotFound, ActionResponse{
+			Success: false,
+			Message: "No cluster peers configured",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, a.cluster.Summary())
+}
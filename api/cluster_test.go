@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"syspeek/config"
+)
+
+func TestClusterSummaryDuplicateHostnames(t *testing.T) {
+	// Two peers cloned from one image, both calling themselves "node"
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hostname":"node"}`))
+	}))
+	defer peer.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+
+	c := newClusterClient(config.ClusterConfig{Peers: []config.PeerConfig{
+		{Name: "a", URL: peer.URL},
+		{Name: "b", URL: peer.URL + "/"},
+		{URL: down.URL},
+	}})
+	summary := c.Summary()
+
+	if summary.Online != 2 || summary.Offline != 1 {
+		t.Errorf("online %d, offline %d, want 2 and 1", summary.Online, summary.Offline)
+	}
+	if len(summary.Peers) != 3 {
+		t.Fatalf("%d peers listed, want 3: %v", len(summary.Peers), summary.Peers)
+	}
+	if _, ok := summary.Peers["node"]; !ok {
+		t.Error("first peer not keyed by its hostname")
+	}
+	if _, ok := summary.Peers["b"]; !ok {
+		t.Error("second peer with the same hostname not keyed by its name")
+	}
+}
+
+func TestHandleClusterSummaryUsesStartupPeers(t *testing.T) {
+	a := NewAPI(config.DefaultConfig(), nil, true)
+
+	// A reload adds peers, but the client still polls none
+	cfg := config.DefaultConfig()
+	cfg.Cluster.Peers = []config.PeerConfig{{Name: "new", URL: "http://192.0.2.1:9876"}}
+	a.ReloadConfig(cfg)
+
+	rec := httptest.NewRecorder()
+	a.HandleClusterSummary(rec, httptest.NewRequest(http.MethodGet, "/api/cluster/summary", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"syspeek/config"
+)
+
+// maxCommandOutput caps the output returned by a command run
+const maxCommandOutput = 1024 * 1024
+
+// cappedBuffer keeps the first max bytes written to it and discards the
+// rest, so a chatty command can't grow the response without bound. Writes
+// never fail, as that would break the command's pipe.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:room])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// CommandInfo is a configured command as listed by /api/commands. The
+// command line itself is not exposed.
+type CommandInfo struct {
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	RequireConfirm bool   `json:"requireConfirm"`
+}
+
+type CommandRunRequest struct {
+	Confirm bool `json:"confirm"`
+}
+
+type CommandRunResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message,omitempty"`
+	ExitCode  int    `json:"exitCode"`
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Duration  string `json:"duration"`
+}
+
+// HandleCommands lists the commands allowed by the config
+func (a *API) HandleCommands(w http.ResponseWriter, r *http.Request) {
+	commands := []CommandInfo{}
+	for _, c := range a.cfg().Commands {
+		commands = append(commands, CommandInfo{
+			Name:           c.Name,
+			Description:    c.Description,
+			RequireConfirm: c.RequireConfirm,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"commands": commands})
+}
+
+func (a *API) findCommand(name string) (config.CommandConfig, bool) {
+	for _, c := range a.cfg().Commands {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return config.CommandConfig{}, false
+}
+
+// HandleCommandRun runs a configured command by name. Only the command and
+// arguments from the config are ever executed; nothing from the request
+// reaches the command line.
+func (a *API) HandleCommandRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract command name from path: /api/commands/{name}/run
+	path := strings.TrimPrefix(r.URL.Path, "/api/commands/")
+	name := strings.TrimSuffix(path, "/run")
+
+	cmdCfg, ok := a.findCommand(name)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, ActionResponse{
+			Success: false,
+			Message: "Unknown command: " + name,
+		})
+		return
+	}
+
+	var req CommandRunRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	if cmdCfg.RequireConfirm && !req.Confirm {
+		writeJSON(w, http.StatusPreconditionRequired, ActionResponse{
+			Success: false,
+			Message: "Command " + name + " requires confirmation",
+		})
+		return
+	}
+
+	timeout := time.Duration(cmdCfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	// The same writer for both makes exec share one pipe, so Write is
+	// never called concurrently
+	output := &cappedBuffer{max: maxCommandOutput}
+	cmd := exec.CommandContext(ctx, cmdCfg.Cmd, cmdCfg.Args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Killing the command on timeout doesn't kill its children, which can
+	// hold the output pipe open; stop waiting for them shortly after
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+
+	resp := CommandRunResponse{
+		Success:   err == nil,
+		Output:    output.buf.String(),
+		Truncated: output.truncated,
+		Duration:  time.Since(start).Round(time.Millisecond).String(),
+	}
+
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			resp.ExitCode = exitErr.ExitCode()
+		} else {
+			resp.ExitCode = -1
+		}
+		resp.Message = err.Error()
+		if ctx.Err() == context.DeadlineExceeded {
+			resp.Message = "Command timed out after " + timeout.String()
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"syspeek/config"
+)
+
+func runCommand(t *testing.T, cmd config.CommandConfig) CommandRunResponse {
+	t.Helper()
+	if _, err := exec.LookPath(cmd.Cmd); err != nil {
+		t.Skipf("%s not available", cmd.Cmd)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Commands = []config.CommandConfig{cmd}
+	a := NewAPI(cfg, nil, true)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/commands/"+cmd.Name+"/run", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+	a.HandleCommandRun(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp CommandRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{max: 10}
+	for _, chunk := range []string{"1234", "5678", "9abc", "def"} {
+		if n, err := b.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if got := b.buf.String(); got != "123456789a" {
+		t.Errorf("kept %q, want %q", got, "123456789a")
+	}
+	if !b.truncated {
+		t.Error("truncated not set")
+	}
+
+	b = &cappedBuffer{max: 10}
+	b.Write([]byte("1234567890"))
+	if b.truncated {
+		t.Error("truncated set at exactly max bytes")
+	}
+}
+
+func TestHandleCommandRunCapsOutput(t *testing.T) {
+	resp := runCommand(t, config.CommandConfig{
+		Name: "flood",
+		Cmd:  "sh",
+		Args: []string{"-c", "head -c 3000000 /dev/zero; echo err >&2"},
+	})
+
+	if !resp.Success {
+		t.Errorf("command failed: %s", resp.Message)
+	}
+	if len(resp.Output) != maxCommandOutput {
+		t.Errorf("output is %d bytes, want %d", len(resp.Output), maxCommandOutput)
+	}
+	if !resp.Truncated {
+		t.Error("truncated not set")
+	}
+}
+
+func TestHandleCommandRunCombinesOutput(t *testing.T) {
+	resp := runCommand(t, config.CommandConfig{
+		Name: "both",
+		Cmd:  "sh",
+		Args: []string{"-c", "echo out; echo err >&2; exit 3"},
+	})
+
+	if resp.Success || resp.ExitCode != 3 {
+		t.Errorf("Success %v, ExitCode %d, want false and 3", resp.Success, resp.ExitCode)
+	}
+	if resp.Output != "out\nerr\n" {
+		t.Errorf("output %q, want stdout and stderr", resp.Output)
+	}
+	if resp.Truncated {
+		t.Error("truncated set")
+	}
+}
+
+func TestHandleCommandRunTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		args []string
+	}{
+		{"command", "sleep", []string{"30"}},
+		// The shell forks sleep, which keeps the output pipe open after
+		// the shell itself is killed
+		{"child holding output", "sh", []string{"-c", "echo started; sleep 30; echo done"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			resp := runCommand(t, config.CommandConfig{
+				Name:    "slow",
+				Cmd:     tt.cmd,
+				Args:    tt.args,
+				Timeout: 1,
+			})
+
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("returned after %v with a 1s timeout", elapsed)
+			}
+			if resp.Success {
+				t.Error("timed out command reported success")
+			}
+			if resp.Message != "Command timed out after 1s" {
+				t.Errorf("message %q", resp.Message)
+			}
+			if strings.Contains(resp.Output, "done") {
+				t.Errorf("output %q from after the timeout", resp.Output)
+			}
+		})
+	}
+}
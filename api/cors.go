@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// allowedOrigin returns the value for Access-Control-Allow-Origin, or ""
+// when origin may not use the API. A "*" entry allows any origin without
+// credentials; config validation refuses it together with
+// allowCredentials, and browsers ignore a wildcard sent with cookies.
+func (a *API) allowedOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	for _, o := range a.cfg().Server.CORS.AllowedOrigins {
+		if o == "*" {
+			return "*"
+		}
+		if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// requestOrigin returns the scheme and host the request was sent from,
+// taken from Origin or, when a browser leaves that out, from Referer.
+// Clients like curl send neither and get "".
+func requestOrigin(r *http.Request) string {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		return origin
+	}
+	referer, err := url.Parse(r.Header.Get("Referer"))
+	if err != nil || referer.Host == "" {
+		return ""
+	}
+	return referer.Scheme + "://" + referer.Host
+}
+
+// writeAllowed reports whether a state-changing request may come from
+// origin: the page syspeek itself served, or an origin listed exactly in
+// the CORS config. A "*" entry does not count; it is meant for reading,
+// and a form posted from any site would carry the user's cookie.
+func (a *API) writeAllowed(r *http.Request, origin string) bool {
+	if origin == "" {
+		return true
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" && a.cfg().Auth.TrustProxy {
+		host = strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host != "" && strings.EqualFold(u.Host, host) {
+		return true
+	}
+	allowed := a.allowedOrigin(origin)
+	return allowed != "" && allowed != "*"
+}
+
+// cors sets the CORS headers for allowed origins and answers preflight
+// OPTIONS requests itself, before they reach the auth middleware. Requests
+// other than GET, HEAD and OPTIONS from a foreign origin are refused, so
+// another site can't act with the user's session cookie.
+func (a *API) cors(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if origin := requestOrigin(r); !a.writeAllowed(r, origin) {
+				writeJSON(w, http.StatusForbidden, ActionResponse{
+					Success: false,
+					Message: "Cross-origin request from " + origin + " not allowed",
+				})
+				return
+			}
+		}
+
+		origin := r.Header.Get("Origin")
+		allowed := a.allowedOrigin(origin)
+		if origin != "" {
+			w.Header().Add("Vary", "Origin")
+		}
+		if allowed != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			if a.cfg().Server.CORS.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next(w, r)
+			return
+		}
+
+		// Preflight
+		if allowed == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		methods := a.cfg().Server.CORS.AllowedMethods
+		if len(methods) == 0 {
+			methods = []string{http.MethodGet, http.MethodPost}
+		}
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+		} else {
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		}
+		w.Header().Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
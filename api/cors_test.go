@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"syspeek/config"
+)
+
+func TestCORSWildcardNeverEchoesOrigin(t *testing.T) {
+	// Validate refuses this config; the handler must still not turn the
+	// wildcard into a credentialed grant for whichever site asks
+	cfg := config.DefaultConfig()
+	cfg.Server.CORS.AllowedOrigins = []string{"*"}
+	cfg.Server.CORS.AllowCredentials = true
+	a := NewAPI(cfg, nil, true)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/cpu", nil)
+	r.Header.Set("Origin", "https://evil.example")
+	rec := httptest.NewRecorder()
+	a.cors(func(w http.ResponseWriter, r *http.Request) {})(rec, r)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin %q, want \"*\"", got)
+	}
+}
+
+func TestAllowedOrigin(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.CORS.AllowedOrigins = []string{"https://dash.example.com/"}
+	cfg.Server.CORS.AllowCredentials = true
+	a := NewAPI(cfg, nil, true)
+
+	for origin, want := range map[string]string{
+		"https://dash.example.com": "https://dash.example.com",
+		"https://DASH.example.com": "https://DASH.example.com",
+		"https://evil.example":     "",
+		"":                         "",
+	} {
+		if got := a.allowedOrigin(origin); got != want {
+			t.Errorf("allowedOrigin(%q) = %q, want %q", origin, got, want)
+		}
+	}
+}
+
+func TestCORSRefusesForeignWrites(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.CORS.AllowedOrigins = []string{"https://dash.example.com"}
+	cfg.Server.CORS.AllowCredentials = true
+	a := NewAPI(cfg, nil, true)
+
+	wildcard := config.DefaultConfig()
+	wildcard.Server.CORS.AllowedOrigins = []string{"*"}
+	aWildcard := NewAPI(wildcard, nil, true)
+
+	proxied := config.DefaultConfig()
+	proxied.Auth.TrustProxy = true
+	aProxied := NewAPI(proxied, nil, true)
+
+	tests := []struct {
+		name    string
+		api     *API
+		method  string
+		origin  string
+		referer string
+		fwdHost string
+		want    int
+	}{
+		{"same origin", a, http.MethodPost, "http://syspeek.local:9876", "", "", http.StatusOK},
+		{"allowed origin", a, http.MethodPost, "https://dash.example.com", "", "", http.StatusOK},
+		{"foreign origin", a, http.MethodPost, "https://evil.example", "", "", http.StatusForbidden},
+		{"opaque origin", a, http.MethodPost, "null", "", "", http.StatusForbidden},
+		{"same origin referer", a, http.MethodPost, "", "http://syspeek.local:9876/#docker", "", http.StatusOK},
+		{"foreign referer", a, http.MethodPost, "", "https://evil.example/form.html", "", http.StatusForbidden},
+		// curl and scripts send neither header
+		{"no origin", a, http.MethodPost, "", "", "", http.StatusOK},
+		{"foreign read", a, http.MethodGet, "https://evil.example", "", "", http.StatusOK},
+		{"foreign delete", a, http.MethodDelete, "https://evil.example", "", "", http.StatusForbidden},
+		// A wildcard is for reading only
+		{"wildcard write", aWildcard, http.MethodPost, "https://evil.example", "", "", http.StatusForbidden},
+		{"wildcard read", aWildcard, http.MethodGet, "https://evil.example", "", "", http.StatusOK},
+		// Behind a proxy that rewrites Host, the public name is forwarded
+		{"proxied same origin", aProxied, http.MethodPost, "https://sys.example.com", "", "sys.example.com", http.StatusOK},
+		{"untrusted forwarded host", a, http.MethodPost, "https://sys.example.com", "", "sys.example.com", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://syspeek.local:9876/api/docker/abc/stop", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.referer != "" {
+				r.Header.Set("Referer", tt.referer)
+			}
+			if tt.fwdHost != "" {
+				r.Header.Set("X-Forwarded-Host", tt.fwdHost)
+			}
+			rec := httptest.NewRecorder()
+			tt.api.cors(func(w http.ResponseWriter, r *http.Request) {})(rec, r)
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"syspeek/config"
+)
+
+// fakeDocker puts a docker script on PATH that succeeds and appends its
+// arguments to the returned log file, one call per line. docker inspect
+// prints the INSPECT_JSON environment variable.
+func fakeDocker(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker is a shell script")
+	}
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> '" + log + "'\n" +
+		"[ \"$1\" = inspect ] && printf '%s' \"$INSPECT_JSON\"\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestHandleDockerAction(t *testing.T) {
+	log := fakeDocker(t)
+	a := NewAPI(config.DefaultConfig(), nil, true)
+
+	tests := []struct {
+		name string
+		path string
+		want int
+		call string // docker arguments expected, empty when docker must not run
+	}{
+		{name: "pause", path: "/api/docker/web/pause", want: http.StatusOK, call: "pause web"},
+		{name: "unpause", path: "/api/docker/web/unpause", want: http.StatusOK, call: "unpause web"},
+		{name: "remove", path: "/api/docker/web/remove", want: http.StatusOK, call: "rm web"},
+		{name: "remove forced", path: "/api/docker/web/remove?force=true", want: http.StatusOK, call: "rm --force web"},
+		{name: "remove not forced", path: "/api/docker/web/remove?force=0", want: http.StatusOK, call: "rm web"},
+		{name: "bad force", path: "/api/docker/web/remove?force=maybe", want: http.StatusBadRequest},
+		{name: "invalid action", path: "/api/docker/web/explode", want: http.StatusBadRequest},
+		{name: "no action", path: "/api/docker/web", want: http.StatusBadRequest},
+		{name: "option as ID", path: "/api/docker/--all/remove", want: http.StatusBadRequest},
+		{name: "empty ID", path: "/api/docker//stop", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(log)
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			rec := httptest.NewRecorder()
+			a.HandleDockerAction(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+
+			// The first call ever also probes with "ps -q"; only the last matters
+			data, _ := os.ReadFile(log)
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			got := lines[len(lines)-1]
+			if tt.call == "" {
+				if len(data) > 0 && got != "ps -q" {
+					t.Errorf("docker ran with %q", got)
+				}
+			} else if got != tt.call {
+				t.Errorf("docker ran with %q, want %q", got, tt.call)
+			}
+		})
+	}
+}
+
+func TestDockerEnvRedactedForReadOnly(t *testing.T) {
+	fakeDocker(t)
+	t.Setenv("INSPECT_JSON", `[{"Id":"abc0123456789abcdef0123456789abcdef0123456789abcdef0123456789a","Name":"/web","Config":{"Image":"nginx","Env":["DB_PASSWORD=hunter2","PATH=/bin"]}}]`)
+	a := NewAPI(config.DefaultConfig(), nil, true)
+
+	for _, tt := range []struct {
+		name    string
+		path    string
+		handler func(http.ResponseWriter, *http.Request)
+	}{
+		{"detail", "/api/docker/abc", a.HandleDockerContainer},
+		{"inspect", "/api/docker/abc/inspect", a.HandleDockerInspect},
+	} {
+		for _, readWrite := range []bool{false, true} {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if readWrite {
+				req.Header.Set("X-ReadWrite", "true")
+			}
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s: status %d: %s", tt.name, rec.Code, rec.Body)
+			}
+
+			body := rec.Body.String()
+			if !strings.Contains(body, "DB_PASSWORD") {
+				t.Errorf("%s: variable name missing: %s", tt.name, body)
+			}
+			if leaked := strings.Contains(body, "hunter2"); leaked == !readWrite {
+				t.Errorf("%s, read-write %v: value shown = %v", tt.name, readWrite, leaked)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// writeJSONWithETag is writeJSON for slow-changing GET endpoints. It tags
+// the body with a hash of its contents and answers 304 Not Modified when
+// the client already holds that version. The tag is weak since gzip may
+// change the bytes on the wire.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		writeJSON(w, http.StatusOK, data)
+		return
+	}
+	body = append(body, '\n') // Match json.Encoder's output in writeJSON
+
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache") // Always revalidate
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// etagMatches compares an If-None-Match header against etag, using the
+// weak comparison RFC 9110 requires for it
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the smallest response worth compressing; below it the
+// gzip header and the CPU cost outweigh the savings
+const gzipMinSize = 1024
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		// "gzip;q=0" explicitly refuses it
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressible reports whether a response with this Content-Type is
+// buffered for compression. SSE streams are excluded: they are written
+// incrementally and must reach the client on every flush.
+func compressible(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/plain")
+}
+
+// gzipResponseWriter holds back the status line and the first
+// gzipMinSize bytes of the body until it knows whether the response is
+// large enough to compress. Once decided, writes go straight through,
+// either to the gzip stream or to the underlying writer.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer // Set when compressing
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	if g.decided || g.status != 0 {
+		return
+	}
+	g.status = code
+	// Bodyless and already encoded responses can't be compressed
+	if code == http.StatusNoContent || code == http.StatusNotModified || code < http.StatusOK ||
+		g.Header().Get("Content-Encoding") != "" || !compressible(g.Header().Get("Content-Type")) {
+		g.decide(false)
+	}
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if g.status == 0 {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.decided {
+		if g.gz != nil {
+			return g.gz.Write(b)
+		}
+		return g.ResponseWriter.Write(b)
+	}
+
+	g.buf = append(g.buf, b...)
+	if len(g.buf) >= gzipMinSize {
+		if err := g.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// decide sends the status line and whatever was buffered, compressed or
+// not
+func (g *gzipResponseWriter) decide(compress bool) error {
+	g.decided = true
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+
+	if compress {
+		h := g.Header()
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length") // Set by the handler for the uncompressed body
+		g.gz = gzipWriterPool.Get().(*gzip.Writer)
+		g.gz.Reset(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+
+	if len(g.buf) == 0 {
+		return nil
+	}
+	var err error
+	if g.gz != nil {
+		_, err = g.gz.Write(g.buf)
+	} else {
+		_, err = g.ResponseWriter.Write(g.buf)
+	}
+	g.buf = nil
+	return err
+}
+
+// Flush sends what is buffered so far; a flush before gzipMinSize bytes
+// were written means a streaming response, which is left uncompressed
+func (g *gzipResponseWriter) Flush() {
+	if !g.decided {
+		g.decide(false)
+	}
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// close finishes the response once the handler returns
+func (g *gzipResponseWriter) close() {
+	if !g.decided {
+		// Small body: send it as is, with an exact length
+		if g.status == 0 && len(g.buf) == 0 {
+			return // Handler wrote nothing, net/http sends the default 200
+		}
+		if g.status == 0 {
+			g.status = http.StatusOK
+		}
+		if g.Header().Get("Content-Length") == "" && g.status != http.StatusNoContent && g.status != http.StatusNotModified {
+			g.Header().Set("Content-Length", strconv.Itoa(len(g.buf)))
+		}
+		g.decide(false)
+	}
+	if g.gz != nil {
+		g.gz.Close()
+		gzipWriterPool.Put(g.gz)
+		g.gz = nil
+	}
+}
+
+// gzip compresses responses for clients that accept it
+func (a *API) gzip(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		next(gw, r)
+	}
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +23,7 @@ import (
 )
 
 type API struct {
+	configMu  sync.RWMutex // Guards config, replaced as a whole on reload
 	config    *config.Config
 	auth      *auth.AuthManager
 	serveMode bool // true = server mode, false = desktop mode (close on browser exit)
@@ -29,6 +35,10 @@ type API struct {
 	shutdownMu     sync.Mutex
 	shutdownTimer  *time.Timer
 	shutdownCancel chan struct{}
+	shutdownFunc   func() // Stops the server gracefully, see SetShutdownFunc
+
+	// Aggregator mode
+	cluster *clusterClient
 }
 
 type LoginRequest struct {
@@ -51,6 +61,8 @@ type StatusResponse struct {
 	RequiresLogin    bool   `json:"requiresLogin"`
 	HasReadWriteAuth bool   `json:"hasReadWriteAuth"`
 	Username         string `json:"username,omitempty"`
+	Role             string `json:"role,omitempty"`     // readwrite or readonly
+	APIToken         bool   `json:"apiToken,omitempty"` // Username is an API token name
 }
 
 type ActionRequest struct {
@@ -68,9 +80,36 @@ func NewAPI(cfg *config.Config, authMgr *auth.AuthManager, serveMode bool) *API
 		config:    cfg,
 		auth:      authMgr,
 		serveMode: serveMode,
+		cluster:   newClusterClient(cfg.Cluster),
 	}
 }
 
+// cfg returns the current config. The returned value is never modified,
+// a reload swaps in a new one.
+func (a *API) cfg() *config.Config {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.config
+}
+
+// ReloadConfig takes the settings that can change without a restart from
+// cfg: refresh intervals, UI, auth, collectors and security. Server
+// address, TLS, CORS and cluster peers keep their startup values. Open
+// SSE streams keep their intervals until they reconnect.
+func (a *API) ReloadConfig(cfg *config.Config) {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+
+	next := *a.config
+	next.Refresh = cfg.Refresh
+	next.UI = cfg.UI
+	next.Auth = cfg.Auth
+	next.Collectors = cfg.Collectors
+	next.Security = cfg.Security
+	next.Commands = cfg.Commands
+	a.config = &next
+}
+
 // SSE connection tracking
 func (a *API) IncrementSSEConnections() {
 	atomic.AddInt32(&a.sseConnections, 1)
@@ -84,6 +123,14 @@ func (a *API) GetSSEConnections() int32 {
 	return atomic.LoadInt32(&a.sseConnections)
 }
 
+// SetShutdownFunc sets what runs once the desktop UI has been closed for good.
+// Without one the process simply exits.
+func (a *API) SetShutdownFunc(fn func()) {
+	a.shutdownMu.Lock()
+	a.shutdownFunc = fn
+	a.shutdownMu.Unlock()
+}
+
 // HandleOpen cancels any pending shutdown (called when UI opens/reloads)
 func (a *API) HandleOpen(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -98,7 +145,7 @@ func (a *API) HandleOpen(w http.ResponseWriter, r *http.Request) {
 	if a.shutdownCancel != nil {
 		close(a.shutdownCancel)
 		a.shutdownCancel = nil
-		fmt.Println("Shutdown cancelled: UI reopened")
+		slog.Info("Shutdown cancelled: UI reopened")
 	}
 	if a.shutdownTimer != nil {
 		a.shutdownTimer.Stop()
@@ -157,7 +204,7 @@ func (a *API) scheduleShutdown() {
 
 	a.shutdownMu.Unlock()
 
-	fmt.Println("Browser closed. Waiting 5 seconds before shutdown...")
+	slog.Info("Browser closed. Waiting 5 seconds before shutdown")
 
 	select {
 	case <-timer.C:
@@ -165,16 +212,20 @@ func (a *API) scheduleShutdown() {
 		a.shutdownMu.Lock()
 		a.shutdownTimer = nil
 		a.shutdownCancel = nil
+		shutdown := a.shutdownFunc
 		a.shutdownMu.Unlock()
 
 		// Check if there are active SSE connections (other tabs)
 		if conns := a.GetSSEConnections(); conns > 0 {
-			fmt.Printf("Shutdown cancelled: %d active SSE connection(s)\n", conns)
+			slog.Info("Shutdown cancelled: SSE connections still open", "connections", conns)
 			return
 		}
 
-		fmt.Println("No active connections. Exiting.")
-		os.Exit(0)
+		slog.Info("No active connections. Exiting.")
+		if shutdown == nil {
+			os.Exit(0)
+		}
+		shutdown()
 
 	case <-cancelChan:
 		// Shutdown was cancelled (UI reopened)
@@ -200,8 +251,20 @@ func (a *API) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, readWrite, ok := a.auth.Login(req.Username, req.Password)
+	ip := auth.ClientIP(r, a.cfg().Auth.TrustProxy)
+	if retryAfter, locked := a.auth.LoginLockout(ip); locked {
+		slog.Warn("Login rejected, client locked out", "ip", ip, "user", req.Username)
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		writeJSON(w, http.StatusTooManyRequests, LoginResponse{
+			Success: false,
+			Message: "Too many failed attempts, try again later",
+		})
+		return
+	}
+
+	token, readWrite, ok := a.auth.Login(ip, req.Username, req.Password)
 	if !ok {
+		slog.Warn("Login failed", "ip", ip, "user", req.Username)
 		writeJSON(w, http.StatusUnauthorized, LoginResponse{
 			Success: false,
 			Message: "Invalid credentials",
@@ -210,13 +273,21 @@ func (a *API) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set session cookie
-	http.SetCookie(w, &http.Cookie{
+	cookie := &http.Cookie{
 		Name:     "session",
 		Value:    token,
 		Path:     "/",
 		HttpOnly: true,
-		MaxAge:   86400, // 24 hours
-	})
+		MaxAge:   int(a.auth.SessionTTL().Seconds()),
+		SameSite: http.SameSiteLaxMode,
+	}
+	// Cross-origin dashboards only get the cookie back with SameSite=None,
+	// which browsers accept on secure connections only
+	if a.cfg().Server.CORS.AllowCredentials && r.TLS != nil {
+		cookie.SameSite = http.SameSiteNoneMode
+		cookie.Secure = true
+	}
+	http.SetCookie(w, cookie)
 
 	writeJSON(w, http.StatusOK, LoginResponse{
 		Success:   true,
@@ -247,6 +318,54 @@ func (a *API) HandleLogout(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// AuthSessionsResponse lists the live login sessions; Current is the ID of the
+// caller's own session, if it has one
+type AuthSessionsResponse struct {
+	Sessions []auth.SessionInfo `json:"sessions"`
+	Current  string             `json:"current,omitempty"`
+}
+
+// HandleAuthSessions lists who is logged in to syspeek
+func (a *API) HandleAuthSessions(w http.ResponseWriter, r *http.Request) {
+	resp := AuthSessionsResponse{Sessions: a.auth.Sessions()}
+	if cookie, err := r.Cookie("session"); err == nil && cookie.Value != "" {
+		resp.Current = auth.SessionID(cookie.Value)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// HandleAuthSessionRevoke ends a session: POST /api/auth/sessions/{id}/revoke
+func (a *API) HandleAuthSessionRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check authentication
+	if r.Header.Get("X-Authenticated") != "true" {
+		writeJSON(w, http.StatusUnauthorized, ActionResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/auth/sessions/"), "/revoke")
+	if !a.auth.RevokeSession(id) {
+		writeJSON(w, http.StatusNotFound, ActionResponse{
+			Success: false,
+			Message: "Session not found",
+		})
+		return
+	}
+
+	slog.Info("Session revoked", "session", id)
+	writeJSON(w, http.StatusOK, ActionResponse{
+		Success: true,
+		Message: "Session revoked",
+	})
+}
+
 func (a *API) HandleAuthStatus(w http.ResponseWriter, r *http.Request) {
 	status := StatusResponse{
 		AuthEnabled:      a.auth.IsEnabled(),
@@ -261,12 +380,15 @@ func (a *API) HandleAuthStatus(w http.ResponseWriter, r *http.Request) {
 		status.Authenticated = true
 		status.ReadWrite = true
 		status.Username = "admin"
-	} else if cookie, err := r.Cookie("session"); err == nil {
-		if session := a.auth.GetSession(cookie.Value); session != nil {
-			status.Authenticated = true
-			status.Username = session.Username
-			status.ReadWrite = session.ReadWrite
-		}
+		status.Role = string(auth.RoleReadWrite)
+	} else if id, ok := a.auth.Identify(r); ok {
+		status.Authenticated = true
+		status.Username = id.Username
+		status.Role = string(id.Role)
+		status.ReadWrite = id.ReadWrite
+		status.APIToken = id.APIToken
+	} else if a.auth.IsPublic() {
+		status.Role = string(auth.RoleReadOnly)
 	}
 
 	writeJSON(w, http.StatusOK, status)
@@ -290,8 +412,25 @@ func (a *API) HandleMemory(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, info)
 }
 
+// HandleDisk returns partitions and I/O. ?includeNetwork= and
+// ?includeVirtual= override collectors.disk for this request
 func (a *API) HandleDisk(w http.ResponseWriter, r *http.Request) {
-	info, err := collectors.GetDiskInfo()
+	filter := collectors.DiskFilter{
+		Network: a.cfg().Collectors.Disk.IncludeNetwork,
+		Virtual: a.cfg().Collectors.Disk.IncludeVirtual,
+	}
+	for param, dst := range map[string]*bool{"includeNetwork": &filter.Network, "includeVirtual": &filter.Virtual} {
+		if v := r.URL.Query().Get(param); v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				http.Error(w, "Invalid "+param, http.StatusBadRequest)
+				return
+			}
+			*dst = parsed
+		}
+	}
+
+	info, err := collectors.GetDiskInfoFiltered(filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -299,12 +438,85 @@ func (a *API) HandleDisk(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, info)
 }
 
+// diskUsageTimeout bounds a /api/disk/usage walk; huge trees return
+// partial sizes rather than tying up the request
+const diskUsageTimeout = 15
+
+// HandleDiskUsage returns the largest entries under a directory, du-style:
+// /api/disk/usage?path=/var&depth=1
+func (a *API) HandleDiskUsage(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		http.Error(w, "Path required", http.StatusBadRequest)
+		return
+	}
+
+	depth := 1
+	if d := r.URL.Query().Get("depth"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 || parsed > collectors.MaxDiskUsageDepth {
+			http.Error(w, fmt.Sprintf("Depth must be between 1 and %d", collectors.MaxDiskUsageDepth), http.StatusBadRequest)
+			return
+		}
+		depth = parsed
+	}
+
+	ctx, cancel := collectContext(r, diskUsageTimeout)
+	defer cancel()
+	usage, err := collectors.GetDiskUsage(ctx, path, depth)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+// HandleDiskSmart returns SMART health for a single device: /api/disk/smart?device=/dev/sda
+func (a *API) HandleDiskSmart(w http.ResponseWriter, r *http.Request) {
+	device := r.URL.Query().Get("device")
+	if device == "" {
+		http.Error(w, "Device required", http.StatusBadRequest)
+		return
+	}
+
+	// Only accept plain device paths so nothing can be smuggled in as a smartctl flag
+	if !strings.HasPrefix(device, "/dev/") || strings.Contains(device, "..") {
+		http.Error(w, "Invalid device", http.StatusBadRequest)
+		return
+	}
+
+	info, err := collectors.GetDiskSmart(device)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if info == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"available": false,
+			"device":    device,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"available": true,
+		"smart":     info,
+	})
+}
+
 func (a *API) HandleNetwork(w http.ResponseWriter, r *http.Request) {
 	info, err := collectors.GetNetworkInfo()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	// Optionally hide bridges, veth pairs and tunnels
+	if r.URL.Query().Get("hideVirtual") == "true" {
+		info.Interfaces = collectors.FilterVirtualInterfaces(info.Interfaces)
+	}
+
 	writeJSON(w, http.StatusOK, info)
 }
 
@@ -317,10 +529,68 @@ func (a *API) HandleGPU(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, info)
 }
 
+// collectContext bounds a slow collector by the request's lifetime and a
+// configured number of seconds (0 means no extra deadline)
+func collectContext(r *http.Request, seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), time.Duration(seconds)*time.Second)
+}
+
+// writeCollectError reports a collector failure, using 503 when it was cut
+// short by its deadline
+func writeCollectError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		slog.Warn("Collector timed out", "err", err)
+		http.Error(w, "Collector timed out", http.StatusServiceUnavailable)
+		return
+	}
+	slog.Error("Collector failed", "err", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
 func (a *API) HandleProcesses(w http.ResponseWriter, r *http.Request) {
-	info, err := collectors.GetProcessList()
+	ctx, cancel := collectContext(r, a.cfg().Collectors.Processes.Timeout)
+	defer cancel()
+
+	info, err := collectors.GetProcessListContext(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeCollectError(w, err)
+		return
+	}
+
+	normalize := a.cfg().Collectors.Processes.NormalizeCPU
+	if v := r.URL.Query().Get("normalizeCpu"); v != "" {
+		if normalize, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "Invalid normalizeCpu", http.StatusBadRequest)
+			return
+		}
+	}
+	if normalize {
+		info.NormalizeCPU()
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// HandleSwapProcesses returns the top swap users, 10 by default (?limit=)
+func (a *API) HandleSwapProcesses(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := collectContext(r, a.cfg().Collectors.Processes.Timeout)
+	defer cancel()
+
+	info, err := collectors.GetSwapProcesses(ctx, limit)
+	if err != nil {
+		writeCollectError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, info)
@@ -348,6 +618,26 @@ func (a *API) HandleProcessDetail(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, info)
 }
 
+// HandleProcessLimits returns the resource limits of a process:
+// /api/process/{pid}/limits
+func (a *API) HandleProcessLimits(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.Atoi(extractPID(r.URL.Path))
+	if err != nil {
+		http.Error(w, "Invalid PID", http.StatusBadRequest)
+		return
+	}
+
+	limits, err := collectors.GetProcessLimits(pid)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"pid":    pid,
+		"limits": limits,
+	})
+}
+
 func (a *API) HandleProcessKill(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -373,11 +663,11 @@ func (a *API) HandleProcessKill(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Prevent killing the service itself
-	if pid == servicePID {
+	// Prevent killing the service itself, init and the like
+	if err := a.checkSignalAllowed(pid); err != nil {
 		writeJSON(w, http.StatusForbidden, ActionResponse{
 			Success: false,
-			Message: "Cannot send signals to the Syspeek service itself",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -406,6 +696,130 @@ func (a *API) HandleProcessKill(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// BulkKillRequest selects processes by PID list or by exact name
+type BulkKillRequest struct {
+	PIDs   []int  `json:"pids,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Signal int    `json:"signal,omitempty"`
+}
+
+type KillResult struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkKillResponse reports each PID separately, since some signals may be
+// delivered while others fail (process already gone, permission denied)
+type BulkKillResponse struct {
+	Success bool               `json:"success"` // Every signal was delivered
+	Results map[int]KillResult `json:"results"`
+}
+
+// HandleBulkKill sends one signal to several processes, given either as
+// {pids: [...]} or {name: "..."}. Protected processes are skipped and
+// reported as failures.
+func (a *API) HandleBulkKill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check authentication
+	if r.Header.Get("X-Authenticated") != "true" {
+		writeJSON(w, http.StatusUnauthorized, ActionResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var req BulkKillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (len(req.PIDs) == 0 && req.Name == "") {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Expected pids or name",
+		})
+		return
+	}
+
+	signal := syscall.Signal(req.Signal)
+	if req.Signal == 0 {
+		signal = syscall.SIGTERM
+	}
+
+	pids := req.PIDs
+	if req.Name != "" {
+		ctx, cancel := collectContext(r, a.cfg().Collectors.Processes.Timeout)
+		defer cancel()
+		list, err := collectors.GetProcessListContext(ctx)
+		if err != nil {
+			writeCollectError(w, err)
+			return
+		}
+		// Signalling only the matches found before the deadline would
+		// leave the rest running with nothing to say so
+		if list.Partial {
+			writeJSON(w, http.StatusServiceUnavailable, ActionResponse{
+				Success: false,
+				Message: "Process list timed out; no signals sent",
+			})
+			return
+		}
+		for _, p := range list.Processes {
+			if p.Name == req.Name {
+				pids = append(pids, p.PID)
+			}
+		}
+		if len(pids) == 0 {
+			writeJSON(w, http.StatusNotFound, ActionResponse{
+				Success: false,
+				Message: "No process named " + req.Name,
+			})
+			return
+		}
+	}
+
+	resp := BulkKillResponse{Success: true, Results: make(map[int]KillResult)}
+	for _, pid := range pids {
+		err := a.checkSignalAllowed(pid)
+		if err == nil {
+			err = collectors.KillProcess(pid, signal)
+		}
+
+		if err != nil {
+			resp.Success = false
+			resp.Results[pid] = KillResult{Success: false, Error: err.Error()}
+		} else {
+			resp.Results[pid] = KillResult{Success: true}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// checkSignalAllowed refuses signals to syspeek itself and to the
+// processes in security.protectedPids, plus Linux kernel threads when
+// security.protectKernelThreads is set. SIGKILL to init panics the kernel.
+// PIDs of 0 and below are process groups to kill(2) (-1 is every process
+// we may signal), never a single process, so they are refused too.
+func (a *API) checkSignalAllowed(pid int) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid PID %d", pid)
+	}
+	if pid == servicePID {
+		return fmt.Errorf("cannot send signals to the Syspeek service itself")
+	}
+	for _, protected := range a.cfg().Security.ProtectedPIDs {
+		if pid == protected {
+			return fmt.Errorf("PID %d is protected and cannot be signalled from the dashboard", pid)
+		}
+	}
+	if a.cfg().Security.ProtectKernelThreads && collectors.IsKernelThread(pid) {
+		return fmt.Errorf("PID %d is a kernel thread and cannot be signalled from the dashboard", pid)
+	}
+	return nil
+}
+
 func (a *API) HandleProcessRenice(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -454,61 +868,243 @@ func (a *API) HandleProcessRenice(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (a *API) HandleSockets(w http.ResponseWriter, r *http.Request) {
-	info, err := collectors.GetSocketInfo()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+type SchedPolicyRequest struct {
+	Policy string `json:"policy"`
+}
+
+func (a *API) HandleProcessSched(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	writeJSON(w, http.StatusOK, info)
-}
 
-func (a *API) HandleFirewall(w http.ResponseWriter, r *http.Request) {
-	info, err := collectors.GetFirewallInfo()
+	pidStr := extractPID(r.URL.Path)
+	pid, err := strconv.Atoi(pidStr)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Invalid PID",
+		})
 		return
 	}
-	writeJSON(w, http.StatusOK, info)
-}
 
-func (a *API) HandleConfig(w http.ResponseWriter, r *http.Request) {
-	// Return UI-relevant config (without sensitive data)
-	uiConfig := struct {
-		UI          config.UIConfig      `json:"ui"`
-		Refresh     config.RefreshConfig `json:"refresh"`
-		AuthEnabled bool                 `json:"authEnabled"`
-	}{
-		UI:          a.config.UI,
-		Refresh:     a.config.Refresh,
-		AuthEnabled: a.auth.IsEnabled(),
+	// Same targets as signals: moving init or a kernel thread to
+	// SCHED_IDLE can stall the machine as surely as killing it
+	if err := a.checkSignalAllowed(pid); err != nil {
+		writeJSON(w, http.StatusForbidden, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
 	}
-	writeJSON(w, http.StatusOK, uiConfig)
-}
 
-func (a *API) HandleIPLookup(w http.ResponseWriter, r *http.Request) {
-	ip := r.URL.Query().Get("ip")
-	if ip == "" {
-		// Try to get from path: /api/ip/1.2.3.4 or /api/ip/2001:db8::1
-		// For IPv6, the address contains colons, so we take everything after /api/ip/
-		pathPart := strings.TrimPrefix(r.URL.Path, "/api/ip/")
-		// Remove trailing slashes
-		pathPart = strings.TrimSuffix(pathPart, "/")
-		if pathPart != "" {
-			ip = pathPart
-		}
+	var req SchedPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Policy == "" {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
 	}
 
-	if ip == "" {
-		http.Error(w, "IP address required", http.StatusBadRequest)
+	if err := collectors.SetSchedPolicy(pid, req.Policy); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
 		return
 	}
 
-	// Clean up IPv6 addresses (remove brackets if present)
-	ip = strings.TrimPrefix(ip, "[")
-	ip = strings.TrimSuffix(ip, "]")
+	writeJSON(w, http.StatusOK, ActionResponse{
+		Success: true,
+		Message: "Scheduling policy changed",
+	})
+}
 
-	info, err := collectors.GetIPInfo(ip)
+// HandleHistory returns recent samples of a metric, e.g.
+// /api/history?metric=cpu&window=5m
+func (a *API) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	metric := r.URL.Query().Get("metric")
+	if metric == "" {
+		metric = "cpu"
+	}
+
+	window := 5 * time.Minute
+	if v := r.URL.Query().Get("window"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid window", http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+
+	samples, err := collectors.GetHistory(metric, window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"metric":  metric,
+		"window":  window.String(),
+		"samples": samples,
+	})
+}
+
+// HandleInterfaceState brings an interface up or down:
+// POST /api/network/{iface}/up or /api/network/{iface}/down
+func (a *API) HandleInterfaceState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/network/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Interface name required",
+		})
+		return
+	}
+
+	iface, action := parts[0], parts[1]
+	up := action == "up"
+
+	// Don't cut off the connection this request arrived on
+	if !up {
+		if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+			if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+				if collectors.InterfaceForIP(net.ParseIP(host)) == iface {
+					writeJSON(w, http.StatusConflict, ActionResponse{
+						Success: false,
+						Message: "Refusing to bring down " + iface + ": it carries this connection",
+					})
+					return
+				}
+			}
+		}
+	}
+
+	if err := collectors.SetInterfaceState(iface, up); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ActionResponse{
+		Success: true,
+		Message: "Interface " + iface + " " + action,
+	})
+}
+
+// HandleSockets lists sockets, optionally filtered with ?state=, ?proto=,
+// ?port= and ?pid=. stateCounts and the totals cover every socket.
+func (a *API) HandleSockets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := collectors.SocketFilter{
+		State: query.Get("state"),
+		Proto: query.Get("proto"),
+	}
+	for param, dst := range map[string]*int{"port": &filter.Port, "pid": &filter.PID} {
+		if v := query.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "Invalid "+param, http.StatusBadRequest)
+				return
+			}
+			*dst = n
+		}
+	}
+
+	ctx, cancel := collectContext(r, a.cfg().Collectors.Sockets.Timeout)
+	defer cancel()
+
+	info, err := collectors.GetSocketInfoContext(ctx)
+	if err != nil {
+		writeCollectError(w, err)
+		return
+	}
+	if filter != (collectors.SocketFilter{}) {
+		info.Filter(filter)
+	}
+
+	// Optional reverse DNS of remote addresses, bounded to a few seconds;
+	// whatever resolved in time is returned
+	if r.URL.Query().Get("resolve") == "true" {
+		resolveCtx, cancelResolve := context.WithTimeout(r.Context(), 3*time.Second)
+		tcpSkipped := collectors.ResolveRemoteHosts(resolveCtx, info.TCP)
+		udpSkipped := collectors.ResolveRemoteHosts(resolveCtx, info.UDP)
+		info.ResolveSkipped = tcpSkipped || udpSkipped
+		cancelResolve()
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// HandleListeningPorts returns only the listening sockets and their owners
+func (a *API) HandleListeningPorts(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := collectContext(r, a.cfg().Collectors.Sockets.Timeout)
+	defer cancel()
+
+	info, err := collectors.GetSocketInfoContext(ctx)
+	if err != nil {
+		writeCollectError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ports":   collectors.ListeningPorts(info.TCP, info.UDP),
+		"partial": info.Partial,
+	})
+}
+
+// HandleProcessSockets returns socket counts per process, busiest first
+func (a *API) HandleProcessSockets(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := collectContext(r, a.cfg().Collectors.Sockets.Timeout)
+	defer cancel()
+
+	info, err := collectors.GetSocketInfoContext(ctx)
+	if err != nil {
+		writeCollectError(w, err)
+		return
+	}
+
+	result := collectors.AggregateSocketsByProcess(info.TCP, info.UDP)
+	result.Partial = info.Partial
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (a *API) HandleFirewall(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetFirewallInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONWithETag(w, r, info)
+}
+
+// HandleFirewallRaw returns the unparsed ruleset: GET /api/firewall/raw?backend=
+func (a *API) HandleFirewallRaw(w http.ResponseWriter, r *http.Request) {
+	backend := r.URL.Query().Get("backend")
+	if backend != "" && !collectors.IsFirewallBackend(backend) {
+		http.Error(w, "Unknown firewall backend", http.StatusBadRequest)
+		return
+	}
+
+	raw, err := collectors.GetFirewallRaw(backend)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, raw)
+}
+
+func (a *API) HandleDeletedFiles(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetDeletedFiles()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -516,6 +1112,155 @@ func (a *API) HandleIPLookup(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, info)
 }
 
+// HandleSystem returns the static facts about the host: OS, kernel,
+// architecture, boot time and CPU counts
+func (a *API) HandleSystem(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetSystemInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// HandlePower returns battery charge and AC state
+func (a *API) HandlePower(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetPowerInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// HandleSensors returns every hwmon temperature, fan and voltage reading,
+// grouped by chip
+func (a *API) HandleSensors(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetSensorsInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// HandlePressure returns the Linux pressure stall information (PSI)
+func (a *API) HandlePressure(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetPressureInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (a *API) HandleTimers(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetTimersInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (a *API) HandleCron(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetCronJobs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (a *API) HandleVPN(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetVPNInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (a *API) HandleConfig(w http.ResponseWriter, r *http.Request) {
+	// Return UI-relevant config (without sensitive data)
+	uiConfig := struct {
+		UI          config.UIConfig      `json:"ui"`
+		Refresh     config.RefreshConfig `json:"refresh"`
+		AuthEnabled bool                 `json:"authEnabled"`
+	}{
+		UI:          a.cfg().UI,
+		Refresh:     a.cfg().Refresh,
+		AuthEnabled: a.auth.IsEnabled(),
+	}
+	writeJSON(w, http.StatusOK, uiConfig)
+}
+
+func (a *API) HandleIPLookup(w http.ResponseWriter, r *http.Request) {
+	ip := r.URL.Query().Get("ip")
+	if ip == "" {
+		// Try to get from path: /api/ip/1.2.3.4 or /api/ip/2001:db8::1
+		// For IPv6, the address contains colons, so we take everything after /api/ip/
+		pathPart := strings.TrimPrefix(r.URL.Path, "/api/ip/")
+		// Remove trailing slashes
+		pathPart = strings.TrimSuffix(pathPart, "/")
+		if pathPart != "" {
+			ip = pathPart
+		}
+	}
+
+	if ip == "" {
+		http.Error(w, "IP address required", http.StatusBadRequest)
+		return
+	}
+
+	// Clean up IPv6 addresses (remove brackets if present)
+	ip = strings.TrimPrefix(ip, "[")
+	ip = strings.TrimSuffix(ip, "]")
+
+	info, err := collectors.GetIPInfo(ip)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// ipBatchTimeout bounds a /api/ip/batch request; addresses not resolved
+// in time are left out and the response is marked partial
+const ipBatchTimeout = 20
+
+// IPBatchRequest lists the addresses for /api/ip/batch
+type IPBatchRequest struct {
+	IPs []string `json:"ips"`
+}
+
+// HandleIPBatch looks up several addresses at once: POST {"ips": [...]}
+func (a *API) HandleIPBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req IPBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.IPs) == 0 {
+		http.Error(w, "Expected ips", http.StatusBadRequest)
+		return
+	}
+	if len(req.IPs) > collectors.MaxIPBatch {
+		http.Error(w, fmt.Sprintf("At most %d IPs per batch", collectors.MaxIPBatch), http.StatusBadRequest)
+		return
+	}
+
+	ips := make([]string, len(req.IPs))
+	for i, ip := range req.IPs {
+		ips[i] = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(ip), "["), "]")
+	}
+
+	ctx, cancel := collectContext(r, ipBatchTimeout)
+	defer cancel()
+	writeJSON(w, http.StatusOK, collectors.GetIPInfoBatch(ctx, ips))
+}
+
 func (a *API) HandleUserLookup(w http.ResponseWriter, r *http.Request) {
 	username := r.URL.Query().Get("user")
 	if username == "" {
@@ -632,12 +1377,104 @@ func (a *API) HandleGroupRemoveUser(w http.ResponseWriter, r *http.Request) {
 	if req.Username == "" {
 		writeJSON(w, http.StatusBadRequest, ActionResponse{
 			Success: false,
-			Message: "Username required",
+			Message: "Username required",
+		})
+		return
+	}
+
+	if err := collectors.RemoveUserFromGroup(groupname, req.Username); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ActionResponse{
+		Success: true,
+		Message: "User removed from group",
+	})
+}
+
+type AddToGroupRequest struct {
+	Username string `json:"username"`
+}
+
+// GroupMembersResponse is the result of a membership change along with
+// the group's members afterwards
+type GroupMembersResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Members []string `json:"members"`
+}
+
+func (a *API) HandleGroupAddUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check authentication
+	if r.Header.Get("X-Authenticated") != "true" {
+		writeJSON(w, http.StatusUnauthorized, ActionResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	// Extract group name from path: /api/group/groupname/add
+	path := strings.TrimPrefix(r.URL.Path, "/api/group/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] == "" {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Group name required",
+		})
+		return
+	}
+	groupname := parts[0]
+
+	var req AddToGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	// Names are passed to gpasswd/dscl/net as arguments, so one starting
+	// with a dash could be read as an option
+	if req.Username == "" || strings.HasPrefix(req.Username, "-") || strings.HasPrefix(groupname, "-") {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Valid username and group required",
+		})
+		return
+	}
+
+	// The lookup also accepts a UID; use the name it resolves to
+	userInfo, err := collectors.GetUserInfo(req.Username)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ActionResponse{
+			Success: false,
+			Message: "User not found: " + req.Username,
+		})
+		return
+	}
+	// Likewise the group may be given as a GID, which gpasswd/dscl/net
+	// don't take
+	groupInfo, err := collectors.GetGroupInfo(groupname)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ActionResponse{
+			Success: false,
+			Message: err.Error(),
 		})
 		return
 	}
 
-	if err := collectors.RemoveUserFromGroup(groupname, req.Username); err != nil {
+	if err := collectors.AddUserToGroup(groupInfo.Name, userInfo.Username); err != nil {
 		writeJSON(w, http.StatusInternalServerError, ActionResponse{
 			Success: false,
 			Message: err.Error(),
@@ -645,10 +1482,15 @@ func (a *API) HandleGroupRemoveUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, ActionResponse{
+	resp := GroupMembersResponse{
 		Success: true,
-		Message: "User removed from group",
-	})
+		Message: "User added to group",
+		Members: []string{},
+	}
+	if info, err := collectors.GetGroupInfo(groupInfo.Name); err == nil && info.Members != nil {
+		resp.Members = info.Members
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 type ModifyUserRequest struct {
@@ -735,8 +1577,116 @@ func GetServicePID() int {
 }
 
 // Docker handlers
+// HandleDocker lists containers, optionally filtered, e.g.
+// /api/docker?status=running&image=nginx&label=app=web&q=api
 func (a *API) HandleDocker(w http.ResponseWriter, r *http.Request) {
 	info := collectors.GetDockerInfo()
+
+	query := r.URL.Query()
+	filter := collectors.ContainerFilter{
+		Status: query.Get("status"),
+		Image:  query.Get("image"),
+		Label:  query.Get("label"),
+		Query:  query.Get("q"),
+	}
+	if filter != (collectors.ContainerFilter{}) && info.Available {
+		info.Containers = collectors.FilterContainers(info.Containers, filter)
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+// HandleDockerSearch finds containers by env var and/or label:
+// /api/docker/search?env=KEY=VAL&label=key=val
+func (a *API) HandleDockerSearch(w http.ResponseWriter, r *http.Request) {
+	envQuery := r.URL.Query().Get("env")
+	labelQuery := r.URL.Query().Get("label")
+
+	if envQuery == "" && labelQuery == "" {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "env or label query required",
+		})
+		return
+	}
+
+	// Read-only viewers never see env values (container detail and inspect
+	// redact them too), so don't let them probe for values either - only
+	// the presence of a variable can be searched.
+	readWrite := r.Header.Get("X-ReadWrite") == "true"
+	if !readWrite && strings.Contains(envQuery, "=") {
+		writeJSON(w, http.StatusForbidden, ActionResponse{
+			Success: false,
+			Message: "Read-write access required to search env values",
+		})
+		return
+	}
+
+	if !collectors.IsDockerAvailable() {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"available": false,
+		})
+		return
+	}
+
+	matches, err := collectors.SearchContainers(envQuery, labelQuery)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !readWrite {
+		for i := range matches {
+			matches[i].Env = collectors.RedactEnv(matches[i].Env)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"available":  true,
+		"containers": matches,
+		"count":      len(matches),
+	})
+}
+
+// HandleDockerDiskUsage reports docker's disk usage; /api/docker/df?verbose=true
+// adds every image, container, volume and build cache entry
+func (a *API) HandleDockerDiskUsage(w http.ResponseWriter, r *http.Request) {
+	verbose := false
+	if v := r.URL.Query().Get("verbose"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid verbose value", http.StatusBadRequest)
+			return
+		}
+		verbose = parsed
+	}
+
+	usage, err := collectors.GetDockerDiskUsage(verbose)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+func (a *API) HandleDockerVolumes(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetDockerVolumes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (a *API) HandleDockerCompose(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetComposeProjects()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	writeJSON(w, http.StatusOK, info)
 }
 
@@ -763,6 +1713,11 @@ func (a *API) HandleDockerContainer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Env often holds credentials; read-only viewers get the names only
+	if r.Header.Get("X-ReadWrite") != "true" {
+		container.Env = collectors.RedactEnv(container.Env)
+	}
+
 	writeJSON(w, http.StatusOK, container)
 }
 
@@ -774,10 +1729,11 @@ func (a *API) HandleDockerAction(w http.ResponseWriter, r *http.Request) {
 
 	// Authentication is handled by middleware in routes.go
 
-	// Extract container ID and action from path: /api/docker/{id}/{action}
+	// Extract container ID and action from path: /api/docker/{id}/{action}.
+	// An ID starting with "-" would be read by docker as an option.
 	path := strings.TrimPrefix(r.URL.Path, "/api/docker/")
 	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
+	if len(parts) < 2 || parts[0] == "" || strings.HasPrefix(parts[0], "-") {
 		writeJSON(w, http.StatusBadRequest, ActionResponse{
 			Success: false,
 			Message: "Container ID and action required",
@@ -789,16 +1745,32 @@ func (a *API) HandleDockerAction(w http.ResponseWriter, r *http.Request) {
 	action := parts[1]
 
 	// Validate action
-	validActions := map[string]bool{"start": true, "stop": true, "restart": true, "kill": true, "pause": true, "unpause": true}
+	validActions := map[string]bool{"start": true, "stop": true, "restart": true, "kill": true, "pause": true, "unpause": true, "remove": true}
 	if !validActions[action] {
 		writeJSON(w, http.StatusBadRequest, ActionResponse{
 			Success: false,
-			Message: "Invalid action. Valid actions: start, stop, restart, kill, pause, unpause",
+			Message: "Invalid action. Valid actions: start, stop, restart, kill, pause, unpause, remove",
 		})
 		return
 	}
 
-	err := collectors.DockerAction(containerID, action)
+	var err error
+	if action == "remove" {
+		// ?force=true removes a running container too
+		force := false
+		if v := r.URL.Query().Get("force"); v != "" {
+			if force, err = strconv.ParseBool(v); err != nil {
+				writeJSON(w, http.StatusBadRequest, ActionResponse{
+					Success: false,
+					Message: "Invalid force value",
+				})
+				return
+			}
+		}
+		err = collectors.RemoveContainer(containerID, force)
+	} else {
+		err = collectors.DockerAction(containerID, action)
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, ActionResponse{
 			Success: false,
@@ -813,6 +1785,124 @@ func (a *API) HandleDockerAction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+type DockerExecRequest struct {
+	Cmd []string `json:"cmd"`
+}
+
+// HandleDockerExec runs a command inside a running container and streams
+// its output back as SSE "output" events, followed by a final "exit" event.
+func (a *API) HandleDockerExec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract container ID from path: /api/docker/{id}/exec
+	path := strings.TrimPrefix(r.URL.Path, "/api/docker/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] == "" || strings.HasPrefix(parts[0], "-") {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Container ID required",
+		})
+		return
+	}
+
+	containerID := parts[0]
+
+	var req DockerExecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Cmd) == 0 {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Invalid request body, expected {\"cmd\": [...]}",
+		})
+		return
+	}
+
+	running, err := collectors.IsContainerRunning(containerID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if !running {
+		writeJSON(w, http.StatusConflict, ActionResponse{
+			Success: false,
+			Message: "Container is not running",
+		})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	cfg := a.cfg().Collectors.Docker
+	timeout := time.Duration(cfg.ExecTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	result, err := collectors.ExecInContainer(r.Context(), containerID, req.Cmd, timeout, cfg.ExecMaxOutput, func(line string) error {
+		return sendSSEEvent(w, flusher, "output", line)
+	})
+	if err != nil {
+		sendSSEEvent(w, flusher, "error", err.Error())
+		return
+	}
+
+	sendSSEEvent(w, flusher, "exit", result)
+}
+
+// HandleDockerLogsStream tails a container's logs over SSE:
+// GET /api/docker/{id}/logs/stream?tail=N
+func (a *API) HandleDockerLogsStream(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/docker/")
+	containerID := strings.TrimSuffix(path, "/logs/stream")
+	if containerID == "" || strings.Contains(containerID, "/") || strings.HasPrefix(containerID, "-") {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Container ID required",
+		})
+		return
+	}
+
+	// Initial backlog (default 50), same as the snapshot endpoint
+	tail := 50
+	if t := r.URL.Query().Get("tail"); t != "" {
+		if parsed, err := strconv.Atoi(t); err == nil && parsed >= 0 {
+			tail = parsed
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	// docker logs is killed when the client disconnects and the request context is cancelled
+	err := collectors.FollowContainerLogs(r.Context(), containerID, tail, func(line string) error {
+		return sendSSEEvent(w, flusher, "log", line)
+	})
+	if err != nil && r.Context().Err() == nil {
+		sendSSEEvent(w, flusher, "error", err.Error())
+	}
+}
+
 func (a *API) HandleDockerLogs(w http.ResponseWriter, r *http.Request) {
 	// Extract container ID from path: /api/docker/{id}/logs
 	path := strings.TrimPrefix(r.URL.Path, "/api/docker/")
@@ -878,6 +1968,46 @@ func (a *API) HandleDockerTop(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// HandleDockerStatsHistory returns the recorded CPU and memory usage of a
+// container, e.g. /api/docker/{id}/stats/history?window=10m
+func (a *API) HandleDockerStatsHistory(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/docker/")
+	containerID := strings.TrimSuffix(path, "/stats/history")
+	if containerID == "" || strings.Contains(containerID, "/") {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Container ID required",
+		})
+		return
+	}
+
+	window := 5 * time.Minute
+	if v := r.URL.Query().Get("window"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid window", http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+
+	history, err := collectors.GetContainerHistory(containerID, window)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":     history.ID,
+		"window": window.String(),
+		"cpu":    history.CPU,
+		"memory": history.Memory,
+	})
+}
+
 func (a *API) HandleDockerInspect(w http.ResponseWriter, r *http.Request) {
 	// Extract container ID from path: /api/docker/{id}/inspect
 	path := strings.TrimPrefix(r.URL.Path, "/api/docker/")
@@ -893,6 +2023,10 @@ func (a *API) HandleDockerInspect(w http.ResponseWriter, r *http.Request) {
 	containerID := parts[0]
 
 	inspect, err := collectors.GetContainerInspect(containerID)
+	if err == nil && r.Header.Get("X-ReadWrite") != "true" {
+		// Same env redaction as the container detail
+		inspect, err = collectors.RedactInspectEnv(inspect)
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, ActionResponse{
 			Success: false,
@@ -906,8 +2040,33 @@ func (a *API) HandleDockerInspect(w http.ResponseWriter, r *http.Request) {
 }
 
 // Services handlers
+// HandleServices lists services; ?withStats=true adds the memory and CPU
+// time of running units, which costs an extra systemctl call per 200 units
 func (a *API) HandleServices(w http.ResponseWriter, r *http.Request) {
-	info, err := collectors.GetServicesInfo()
+	getInfo := collectors.GetServicesInfo
+	if v := r.URL.Query().Get("withStats"); v != "" {
+		withStats, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid withStats value", http.StatusBadRequest)
+			return
+		}
+		if withStats {
+			getInfo = collectors.GetServicesInfoWithStats
+		}
+	}
+
+	info, err := getInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONWithETag(w, r, info)
+}
+
+// HandleFailedServices returns only the services in a failed state, like
+// systemctl --failed
+func (a *API) HandleFailedServices(w http.ResponseWriter, r *http.Request) {
+	info, err := collectors.GetFailedServices()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -961,16 +2120,33 @@ func (a *API) HandleServiceAction(w http.ResponseWriter, r *http.Request) {
 	serviceName := parts[0]
 	action := parts[1]
 
-	// Validate action
-	validActions := map[string]bool{"start": true, "stop": true, "restart": true, "enable": true, "disable": true}
+	// Validate action against what this platform's service manager supports
+	validActions := make(map[string]bool)
+	for _, a := range collectors.ServiceActions {
+		validActions[a] = true
+	}
 	if !validActions[action] {
 		writeJSON(w, http.StatusBadRequest, ActionResponse{
 			Success: false,
-			Message: "Invalid action. Valid actions: start, stop, restart, enable, disable",
+			Message: "Invalid action. Valid actions: " + strings.Join(collectors.ServiceActions, ", "),
 		})
 		return
 	}
 
+	// Prevent locking the operator out by stopping sshd and the like.
+	// The UI can override after an explicit confirmation by echoing the
+	// service name in X-Confirm-Protected.
+	disruptive := map[string]string{"stop": "stopped", "restart": "restarted", "disable": "disabled", "mask": "masked"}
+	if verb, ok := disruptive[action]; ok {
+		if a.isProtectedService(serviceName) && r.Header.Get("X-Confirm-Protected") != serviceName {
+			writeJSON(w, http.StatusForbidden, ActionResponse{
+				Success: false,
+				Message: "Service " + serviceName + " is protected and cannot be " + verb + " from the dashboard",
+			})
+			return
+		}
+	}
+
 	err := collectors.ServiceAction(serviceName, action)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, ActionResponse{
@@ -986,6 +2162,18 @@ func (a *API) HandleServiceAction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// isProtectedService reports whether name matches the configured protected
+// services, ignoring case and a ".service" suffix.
+func (a *API) isProtectedService(name string) bool {
+	name = strings.TrimSuffix(name, ".service")
+	for _, protected := range a.cfg().Security.ProtectedServices {
+		if strings.EqualFold(strings.TrimSuffix(protected, ".service"), name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *API) HandleServiceLogs(w http.ResponseWriter, r *http.Request) {
 	// Extract service name from path: /api/service/{name}/logs
 	path := strings.TrimPrefix(r.URL.Path, "/api/service/")
@@ -1008,7 +2196,23 @@ func (a *API) HandleServiceLogs(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logs, err := collectors.GetServiceLogs(serviceName, lines)
+	// Optional journal filters: ?priority=err&since=-1h&until=now&grep=timeout
+	query := r.URL.Query()
+	filter := collectors.LogFilter{
+		Priority: query.Get("priority"),
+		Since:    query.Get("since"),
+		Until:    query.Get("until"),
+		Grep:     query.Get("grep"),
+	}
+	if err := filter.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	logs, err := collectors.GetServiceLogs(serviceName, lines, filter)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, ActionResponse{
 			Success: false,
@@ -1023,6 +2227,105 @@ func (a *API) HandleServiceLogs(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// maxStreamUnits bounds how many journals a single log stream may follow
+const maxStreamUnits = 10
+
+var unitNameRegex = regexp.MustCompile(`^[a-zA-Z0-9@._:\\-]+$`)
+
+// HandleLogsStream follows the journals of several units at once and streams
+// each entry as an SSE "log" event tagged with its unit.
+func (a *API) HandleLogsStream(w http.ResponseWriter, r *http.Request) {
+	var units []string
+	for _, u := range strings.Split(r.URL.Query().Get("units"), ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		if strings.HasPrefix(u, "-") || !unitNameRegex.MatchString(u) {
+			writeJSON(w, http.StatusBadRequest, ActionResponse{
+				Success: false,
+				Message: "Invalid unit name: " + u,
+			})
+			return
+		}
+		if !strings.Contains(u, ".") {
+			u += ".service"
+		}
+		units = append(units, u)
+	}
+
+	if len(units) == 0 || len(units) > maxStreamUnits {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: fmt.Sprintf("Between 1 and %d units required", maxStreamUnits),
+		})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	// journalctl is killed when the client disconnects and the request context is cancelled
+	err := collectors.FollowJournal(r.Context(), units, 10, func(entry collectors.JournalEntry) error {
+		return sendSSEEvent(w, flusher, "log", entry)
+	})
+	if err != nil && r.Context().Err() == nil {
+		sendSSEEvent(w, flusher, "error", err.Error())
+	}
+}
+
+// HandleServiceLogsStream tails a service's journal over SSE:
+// GET /api/service/{name}/logs/stream?lines=N
+func (a *API) HandleServiceLogsStream(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/service/")
+	unit := strings.TrimSuffix(path, "/logs/stream")
+	if unit == "" || strings.HasPrefix(unit, "-") || !unitNameRegex.MatchString(unit) {
+		writeJSON(w, http.StatusBadRequest, ActionResponse{
+			Success: false,
+			Message: "Invalid service name",
+		})
+		return
+	}
+	if !strings.HasSuffix(unit, ".service") {
+		unit += ".service"
+	}
+
+	// Initial backlog (default 100), same as the snapshot endpoint
+	lines := 100
+	if l := r.URL.Query().Get("lines"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed >= 0 {
+			lines = parsed
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	// journalctl is killed when the client disconnects and the request context is cancelled
+	err := collectors.FollowJournal(r.Context(), []string{unit}, lines, func(entry collectors.JournalEntry) error {
+		return sendSSEEvent(w, flusher, "log", entry)
+	})
+	if err != nil && r.Context().Err() == nil {
+		sendSSEEvent(w, flusher, "error", err.Error())
+	}
+}
+
 // Sessions handlers
 func (a *API) HandleSessions(w http.ResponseWriter, r *http.Request) {
 	info, err := collectors.GetSessions()
@@ -1040,5 +2343,5 @@ func (a *API) HandleUsersList(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusOK, info)
+	writeJSONWithETag(w, r, info)
 }
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"syspeek/collectors"
+	"syspeek/config"
+)
+
+func TestCheckSignalAllowed(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Security.ProtectedPIDs = []int{1, 4242}
+	cfg.Security.ProtectKernelThreads = true
+	a := NewAPI(cfg, nil, true)
+
+	prev := servicePID
+	servicePID = 31337
+	defer func() { servicePID = prev }()
+
+	tests := []struct {
+		name    string
+		pid     int
+		allowed bool
+	}{
+		{"every process", -1, false},
+		{"process group", -1234, false},
+		{"own process group", 0, false},
+		{"syspeek itself", 31337, false},
+		{"init", 1, false},
+		{"protected", 4242, false},
+		{"ordinary", 4243, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.checkSignalAllowed(tt.pid)
+			if (err == nil) != tt.allowed {
+				t.Errorf("checkSignalAllowed(%d) = %v, allowed %v", tt.pid, err, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestCheckSignalAllowedKernelThread(t *testing.T) {
+	// kthreadd is PID 2 outside of a PID namespace
+	if !collectors.IsKernelThread(2) {
+		t.Skip("no kernel thread visible as PID 2")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Security.ProtectKernelThreads = true
+	if err := NewAPI(cfg, nil, true).checkSignalAllowed(2); err == nil {
+		t.Error("kernel thread allowed with protectKernelThreads")
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.Security.ProtectKernelThreads = false
+	if err := NewAPI(cfg, nil, true).checkSignalAllowed(2); err != nil {
+		t.Errorf("kernel thread refused without protectKernelThreads: %v", err)
+	}
+}
+
+func TestHandleProcessSchedRefusesProtected(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Security.ProtectedPIDs = []int{1}
+	a := NewAPI(cfg, nil, true)
+
+	prev := servicePID
+	servicePID = 31337
+	defer func() { servicePID = prev }()
+
+	for _, pid := range []string{"0", "-1", "1", "31337"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/process/"+pid+"/sched", strings.NewReader(`{"policy":"SCHED_IDLE"}`))
+		rec := httptest.NewRecorder()
+		a.HandleProcessSched(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("PID %s: status %d, want %d", pid, rec.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestHandleGroupAddUserUnknownGroup(t *testing.T) {
+	a := NewAPI(config.DefaultConfig(), nil, true)
+
+	// GID that no group has; the user exists, so only the group fails
+	req := httptest.NewRequest(http.MethodPost, "/api/group/4294967000/add", strings.NewReader(`{"username":"root"}`))
+	req.Header.Set("X-Authenticated", "true")
+	rec := httptest.NewRecorder()
+	a.HandleGroupAddUser(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleBulkKillPartialList(t *testing.T) {
+	a := NewAPI(config.DefaultConfig(), nil, true)
+
+	// A request whose deadline has already passed gets a partial list
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/api/processes/kill", strings.NewReader(`{"name":"syspeek-test-none","signal":15}`))
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Authenticated", "true")
+	rec := httptest.NewRecorder()
+	a.HandleBulkKill(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"math"
+	"net/http"
+
+	"syspeek/collectors"
+	"syspeek/config"
+)
+
+// HealthFactor is one normalized (0-100) input to the health score
+type HealthFactor struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`  // 0 = idle, 100 = saturated
+	Weight float64 `json:"weight"` // Relative weight from config
+}
+
+type HealthScore struct {
+	Score     float64        `json:"score"`  // 0 = idle, 100 = saturated
+	Status    string         `json:"status"` // ok, warn, crit
+	TopFactor string         `json:"topFactor,omitempty"`
+	Factors   []HealthFactor `json:"factors"`
+}
+
+// computeHealthScore combines the factors into a single 0-100 score:
+//
+//	score = sum(value_i * weight_i) / sum(weight_i)
+//
+// The top factor is the one contributing most (value * weight); ties go to
+// the earlier factor so the result is deterministic.
+func computeHealthScore(factors []HealthFactor, cfg config.HealthScoreConfig) HealthScore {
+	result := HealthScore{Status: "ok", Factors: factors}
+
+	var weighted, totalWeight, topContribution float64
+	for _, f := range factors {
+		if f.Weight <= 0 {
+			continue
+		}
+		value := math.Max(0, math.Min(100, f.Value))
+		weighted += value * f.Weight
+		totalWeight += f.Weight
+
+		if contribution := value * f.Weight; contribution > topContribution {
+			topContribution = contribution
+			result.TopFactor = f.Name
+		}
+	}
+
+	if totalWeight > 0 {
+		result.Score = math.Round(weighted/totalWeight*10) / 10
+	}
+
+	switch {
+	case result.Score >= cfg.Crit:
+		result.Status = "crit"
+	case result.Score >= cfg.Warn:
+		result.Status = "warn"
+	}
+
+	return result
+}
+
+// HandleHealthScore returns a single "how stressed is this box" number
+func (a *API) HandleHealthScore(w http.ResponseWriter, r *http.Request) {
+	cfg := a.cfg().Health
+	var factors []HealthFactor
+
+	if cpu, err := collectors.GetCPUInfo(); err == nil {
+		factors = append(factors, HealthFactor{Name: "cpu", Value: cpu.UsagePercent, Weight: cfg.CPUWeight})
+
+		// Load of 1.0 per core means every core is busy
+		cores := cpu.Threads
+		if cores == 0 {
+			cores = cpu.Cores
+		}
+		if len(cpu.LoadAvg) > 0 && cores > 0 {
+			factors = append(factors, HealthFactor{Name: "load", Value: cpu.LoadAvg[0] / float64(cores) * 100, Weight: cfg.LoadWeight})
+		}
+
+		factors = append(factors, HealthFactor{Name: "iowait", Value: cpu.IOWaitPercent, Weight: cfg.IOWaitWeight})
+	}
+
+	if mem, err := collectors.GetMemoryInfo(); err == nil {
+		factors = append(factors, HealthFactor{Name: "memory", Value: mem.UsedPercent, Weight: cfg.MemoryWeight})
+		factors = append(factors, HealthFactor{Name: "swap", Value: mem.SwapPercent, Weight: cfg.SwapWeight})
+	}
+
+	if disk, err := collectors.GetDiskInfo(); err == nil {
+		// The fullest filesystem is the one that will cause trouble first
+		var fullest float64
+		for _, p := range disk.Partitions {
+			fullest = math.Max(fullest, p.UsedPercent)
+		}
+		factors = append(factors, HealthFactor{Name: "disk", Value: fullest, Weight: cfg.DiskWeight})
+	}
+
+	writeJSON(w, http.StatusOK, computeHealthScore(factors, cfg))
+}
+
+// version is reported by /healthz, set from main
+var version = ""
+
+func SetVersion(v string) {
+	version = v
+}
+
+// HandleHealthz is the liveness probe: if the server answers, it is alive
+func (a *API) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"version": version,
+	})
+}
+
+// HandleReadyz is the readiness probe. It reports 503 when the collectors
+// cannot read the host, e.g. /proc is not mounted in a container.
+func (a *API) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	if err := collectors.CheckReady(); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status": "unavailable",
+			"error":  err.Error(),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
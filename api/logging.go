@@ -0,0 +1,97 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"syspeek/auth"
+)
+
+// statusRecorder captures the status code and body size written by a
+// handler. It keeps http.Flusher working so SSE handlers can still stream
+// through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status   int
+	bytes    int64
+	onHeader func() // Called once, when the status line is written
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+		if s.onHeader != nil {
+			s.onHeader()
+		}
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.WriteHeader(http.StatusOK)
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += int64(n)
+	return n, err
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// logRequests wraps an API handler with the access log. With log.accessLog
+// set every request is logged at info level, otherwise at debug level.
+// SSE streams log when they open and when they close, since a single
+// duration says little about a connection that stays up for hours.
+func (a *API) logRequests(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		level := slog.LevelDebug
+		if a.cfg().Log.AccessLog {
+			level = slog.LevelInfo
+		}
+		ctx := r.Context()
+		if !slog.Default().Enabled(ctx, level) {
+			next(w, r)
+			return
+		}
+
+		start := time.Now()
+		ip := auth.ClientIP(r, a.cfg().Auth.TrustProxy)
+		streaming := false
+		rec := &statusRecorder{ResponseWriter: w}
+		rec.onHeader = func() {
+			if rec.status == http.StatusOK && strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+				streaming = true
+				slog.Log(ctx, level, "SSE opened", "path", r.URL.Path, "ip", ip)
+			}
+		}
+		next(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		msg := "Request"
+		if streaming {
+			msg = "SSE closed"
+		}
+		slog.Log(ctx, level, msg,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"bytes", rec.bytes,
+			"duration", time.Since(start),
+			"ip", ip,
+		)
+	}
+}
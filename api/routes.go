@@ -8,31 +8,88 @@ import (
 )
 
 func (a *API) SetupRoutes(mux *http.ServeMux, authMgr *auth.AuthManager) {
+	// Every API route goes through the access log, the CORS policy and
+	// gzip compression
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, a.logRequests(a.cors(a.gzip(h))))
+	}
+
+	// Liveness/readiness probes - never gated, orchestrators have no credentials
+	handle("/healthz", a.HandleHealthz)
+	handle("/readyz", a.HandleReadyz)
+
 	// API endpoints - read-only, but may require login depending on mode
-	mux.HandleFunc("/api/cpu", authMgr.Middleware(a.HandleCPU, false))
-	mux.HandleFunc("/api/memory", authMgr.Middleware(a.HandleMemory, false))
-	mux.HandleFunc("/api/disk", authMgr.Middleware(a.HandleDisk, false))
-	mux.HandleFunc("/api/network", authMgr.Middleware(a.HandleNetwork, false))
-	mux.HandleFunc("/api/gpu", authMgr.Middleware(a.HandleGPU, false))
-	mux.HandleFunc("/api/processes", authMgr.Middleware(a.HandleProcesses, false))
-	mux.HandleFunc("/api/sockets", authMgr.Middleware(a.HandleSockets, false))
-	mux.HandleFunc("/api/firewall", authMgr.Middleware(a.HandleFirewall, false))
-	mux.HandleFunc("/api/config", authMgr.Middleware(a.HandleConfig, false))
+	handle("/api/system", authMgr.Middleware(a.HandleSystem, false))
+	handle("/api/cpu", authMgr.Middleware(a.HandleCPU, false))
+	handle("/api/memory", authMgr.Middleware(a.HandleMemory, false))
+	handle("/api/pressure", authMgr.Middleware(a.HandlePressure, false))
+	handle("/api/power", authMgr.Middleware(a.HandlePower, false))
+	handle("/api/sensors", authMgr.Middleware(a.HandleSensors, false))
+	handle("/api/disk", authMgr.Middleware(a.HandleDisk, false))
+	handle("/api/disk/smart", authMgr.Middleware(a.HandleDiskSmart, false))
+	handle("/api/disk/usage", authMgr.Middleware(a.HandleDiskUsage, false))
+	handle("/api/network", authMgr.Middleware(a.HandleNetwork, false))
+	handle("/api/network/", func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if strings.HasSuffix(path, "/up") || strings.HasSuffix(path, "/down") {
+			// Requires read-write access
+			authMgr.MiddlewareReadWrite(a.HandleInterfaceState)(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+	})
+	handle("/api/vpn", authMgr.Middleware(a.HandleVPN, false))
+	handle("/api/files/deleted", authMgr.Middleware(a.HandleDeletedFiles, false))
+	handle("/api/gpu", authMgr.Middleware(a.HandleGPU, false))
+	handle("/api/processes", authMgr.Middleware(a.HandleProcesses, false))
+	handle("/api/processes/swap", authMgr.Middleware(a.HandleSwapProcesses, false))
+	handle("/api/processes/network", authMgr.Middleware(a.HandleProcessSockets, false))
+	handle("/api/processes/kill", authMgr.MiddlewareReadWrite(a.HandleBulkKill))
+	handle("/api/sockets", authMgr.Middleware(a.HandleSockets, false))
+	handle("/api/ports", authMgr.Middleware(a.HandleListeningPorts, false))
+	handle("/api/firewall", authMgr.Middleware(a.HandleFirewall, false))
+	handle("/api/firewall/raw", authMgr.Middleware(a.HandleFirewallRaw, false))
+	handle("/api/config", authMgr.Middleware(a.HandleConfig, false))
+	handle("/api/health-score", authMgr.Middleware(a.HandleHealthScore, false))
+	handle("/api/summary", authMgr.Middleware(a.HandleSummary, false))
+	handle("/api/cluster/summary", authMgr.Middleware(a.HandleClusterSummary, false))
+	handle("/api/history", authMgr.Middleware(a.HandleHistory, false))
+
+	// Allowlisted operational commands
+	handle("/api/commands", authMgr.Middleware(a.HandleCommands, false))
+	handle("/api/commands/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/run") {
+			// Requires read-write access
+			authMgr.MiddlewareReadWrite(a.HandleCommandRun)(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+	})
 
 	// SSE stream - read-only but may require login
-	mux.HandleFunc("/api/stream", authMgr.Middleware(a.HandleSSE, false))
+	handle("/api/stream", authMgr.Middleware(a.HandleSSE, false))
 
 	// Auth endpoints - always accessible (for login flow)
-	mux.HandleFunc("/api/auth/login", a.HandleLogin)
-	mux.HandleFunc("/api/auth/logout", a.HandleLogout)
-	mux.HandleFunc("/api/auth/status", a.HandleAuthStatus)
+	handle("/api/auth/login", a.HandleLogin)
+	handle("/api/auth/logout", a.HandleLogout)
+	handle("/api/auth/status", a.HandleAuthStatus)
+
+	// Session management - read-write
+	handle("/api/auth/sessions", authMgr.MiddlewareReadWrite(a.HandleAuthSessions))
+	handle("/api/auth/sessions/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/revoke") {
+			authMgr.MiddlewareReadWrite(a.HandleAuthSessionRevoke)(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+	})
 
 	// Open/Close endpoints - for desktop mode (ignored in serve mode)
-	mux.HandleFunc("/api/open", a.HandleOpen)
-	mux.HandleFunc("/api/close", a.HandleClose)
+	handle("/api/open", a.HandleOpen)
+	handle("/api/close", a.HandleClose)
 
 	// Process endpoints with dynamic PID
-	mux.HandleFunc("/api/process/", func(w http.ResponseWriter, r *http.Request) {
+	handle("/api/process/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 
 		// Route based on path pattern
@@ -42,6 +99,11 @@ func (a *API) SetupRoutes(mux *http.ServeMux, authMgr *auth.AuthManager) {
 		} else if strings.HasSuffix(path, "/renice") {
 			// Requires read-write access
 			authMgr.MiddlewareReadWrite(a.HandleProcessRenice)(w, r)
+		} else if strings.HasSuffix(path, "/sched") {
+			// Requires read-write access
+			authMgr.MiddlewareReadWrite(a.HandleProcessSched)(w, r)
+		} else if strings.HasSuffix(path, "/limits") {
+			authMgr.Middleware(a.HandleProcessLimits, false)(w, r)
 		} else {
 			// Process detail - read-only
 			authMgr.Middleware(a.HandleProcessDetail, false)(w, r)
@@ -49,10 +111,11 @@ func (a *API) SetupRoutes(mux *http.ServeMux, authMgr *auth.AuthManager) {
 	})
 
 	// IP lookup endpoint - read-only
-	mux.HandleFunc("/api/ip/", authMgr.Middleware(a.HandleIPLookup, false))
+	handle("/api/ip/batch", authMgr.Middleware(a.HandleIPBatch, false))
+	handle("/api/ip/", authMgr.Middleware(a.HandleIPLookup, false))
 
 	// User endpoints - lookup and modify
-	mux.HandleFunc("/api/user/", func(w http.ResponseWriter, r *http.Request) {
+	handle("/api/user/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 		if strings.HasSuffix(path, "/modify") {
 			// Requires read-write access
@@ -63,12 +126,15 @@ func (a *API) SetupRoutes(mux *http.ServeMux, authMgr *auth.AuthManager) {
 		}
 	})
 
-	// Group endpoints - lookup and remove user
-	mux.HandleFunc("/api/group/", func(w http.ResponseWriter, r *http.Request) {
+	// Group endpoints - lookup, add and remove user
+	handle("/api/group/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 		if strings.HasSuffix(path, "/remove") {
 			// Requires read-write access
 			authMgr.MiddlewareReadWrite(a.HandleGroupRemoveUser)(w, r)
+		} else if strings.HasSuffix(path, "/add") {
+			// Requires read-write access
+			authMgr.MiddlewareReadWrite(a.HandleGroupAddUser)(w, r)
 		} else {
 			// Group lookup - read-only
 			authMgr.Middleware(a.HandleGroupLookup, false)(w, r)
@@ -76,25 +142,47 @@ func (a *API) SetupRoutes(mux *http.ServeMux, authMgr *auth.AuthManager) {
 	})
 
 	// Service PID endpoint - read-only
-	mux.HandleFunc("/api/pid", authMgr.Middleware(a.HandleServicePID, false))
+	handle("/api/pid", authMgr.Middleware(a.HandleServicePID, false))
 
 	// Docker endpoints
-	mux.HandleFunc("/api/docker", authMgr.Middleware(a.HandleDocker, false))
-	mux.HandleFunc("/api/docker/", func(w http.ResponseWriter, r *http.Request) {
+	handle("/api/docker", authMgr.Middleware(a.HandleDocker, false))
+	handle("/api/docker/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 
-		// Check if it's an action (start, stop, restart, kill, pause, unpause)
-		if strings.HasSuffix(path, "/start") ||
+		// Check if it's an action (start, stop, restart, kill, pause, unpause, remove)
+		if path == "/api/docker/search" {
+			// Search across all containers - read-only
+			authMgr.Middleware(a.HandleDockerSearch, false)(w, r)
+		} else if path == "/api/docker/volumes" {
+			// Volume listing - read-only
+			authMgr.Middleware(a.HandleDockerVolumes, false)(w, r)
+		} else if path == "/api/docker/df" {
+			// Disk usage - read-only
+			authMgr.Middleware(a.HandleDockerDiskUsage, false)(w, r)
+		} else if path == "/api/docker/compose" {
+			// Compose project grouping - read-only
+			authMgr.Middleware(a.HandleDockerCompose, false)(w, r)
+		} else if strings.HasSuffix(path, "/start") ||
 			strings.HasSuffix(path, "/stop") ||
 			strings.HasSuffix(path, "/restart") ||
 			strings.HasSuffix(path, "/kill") ||
 			strings.HasSuffix(path, "/pause") ||
-			strings.HasSuffix(path, "/unpause") {
+			strings.HasSuffix(path, "/unpause") ||
+			strings.HasSuffix(path, "/remove") {
 			// Requires read-write access
 			authMgr.MiddlewareReadWrite(a.HandleDockerAction)(w, r)
+		} else if strings.HasSuffix(path, "/exec") {
+			// Exec streams command output - requires read-write access
+			authMgr.MiddlewareReadWrite(a.HandleDockerExec)(w, r)
+		} else if strings.HasSuffix(path, "/logs/stream") {
+			// Live logs over SSE - read-only
+			authMgr.Middleware(a.HandleDockerLogsStream, false)(w, r)
 		} else if strings.HasSuffix(path, "/logs") {
 			// Logs - read-only
 			authMgr.Middleware(a.HandleDockerLogs, false)(w, r)
+		} else if strings.HasSuffix(path, "/stats/history") {
+			// Resource usage history - read-only
+			authMgr.Middleware(a.HandleDockerStatsHistory, false)(w, r)
 		} else if strings.HasSuffix(path, "/top") {
 			// Top - read-only
 			authMgr.Middleware(a.HandleDockerTop, false)(w, r)
@@ -108,18 +196,28 @@ func (a *API) SetupRoutes(mux *http.ServeMux, authMgr *auth.AuthManager) {
 	})
 
 	// Services endpoints
-	mux.HandleFunc("/api/services", authMgr.Middleware(a.HandleServices, false))
-	mux.HandleFunc("/api/service/", func(w http.ResponseWriter, r *http.Request) {
+	handle("/api/services", authMgr.Middleware(a.HandleServices, false))
+	handle("/api/services/failed", authMgr.Middleware(a.HandleFailedServices, false))
+	handle("/api/timers", authMgr.Middleware(a.HandleTimers, false))
+	handle("/api/cron", authMgr.Middleware(a.HandleCron, false))
+	handle("/api/logs/stream", authMgr.Middleware(a.HandleLogsStream, false))
+	handle("/api/service/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 
-		// Check if it's an action (start, stop, restart, enable, disable)
+		// Check if it's an action (start, stop, restart, enable, disable, reload, mask, unmask)
 		if strings.HasSuffix(path, "/start") ||
 			strings.HasSuffix(path, "/stop") ||
 			strings.HasSuffix(path, "/restart") ||
 			strings.HasSuffix(path, "/enable") ||
-			strings.HasSuffix(path, "/disable") {
+			strings.HasSuffix(path, "/disable") ||
+			strings.HasSuffix(path, "/reload") ||
+			strings.HasSuffix(path, "/mask") ||
+			strings.HasSuffix(path, "/unmask") {
 			// Requires read-write access
 			authMgr.MiddlewareReadWrite(a.HandleServiceAction)(w, r)
+		} else if strings.HasSuffix(path, "/logs/stream") {
+			// Live logs over SSE - read-only
+			authMgr.Middleware(a.HandleServiceLogsStream, false)(w, r)
 		} else if strings.HasSuffix(path, "/logs") {
 			// Logs - read-only
 			authMgr.Middleware(a.HandleServiceLogs, false)(w, r)
@@ -130,8 +228,8 @@ func (a *API) SetupRoutes(mux *http.ServeMux, authMgr *auth.AuthManager) {
 	})
 
 	// Sessions endpoint - read-only
-	mux.HandleFunc("/api/sessions", authMgr.Middleware(a.HandleSessions, false))
+	handle("/api/sessions", authMgr.Middleware(a.HandleSessions, false))
 
 	// Users list endpoint - read-only
-	mux.HandleFunc("/api/users", authMgr.Middleware(a.HandleUsersList, false))
+	handle("/api/users", authMgr.Middleware(a.HandleUsersList, false))
 }
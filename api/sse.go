@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"syspeek/collectors"
@@ -15,7 +18,109 @@ type SSEData struct {
 	Data interface{} `json:"data"`
 }
 
+// sseEventTypes are all the event types HandleSSE can stream
+var sseEventTypes = []string{"cpu", "memory", "disk", "network", "gpu", "processes", "sockets", "firewall", "docker", "pressure"}
+
+// sseOptionalTypes are only streamed when a client names them in types=
+var sseOptionalTypes = map[string]bool{"pressure": true}
+
+// sseSubscription is the set of event types a client asked for; nil means
+// all but the optional ones
+type sseSubscription map[string]bool
+
+func (s sseSubscription) wants(eventType string) bool {
+	if s == nil {
+		return !sseOptionalTypes[eventType]
+	}
+	return s[eventType]
+}
+
+// parseSSETypes parses the "types=cpu,memory" query parameter
+func parseSSETypes(param string) (sseSubscription, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+
+	valid := make(map[string]bool, len(sseEventTypes))
+	for _, t := range sseEventTypes {
+		valid[t] = true
+	}
+
+	types := sseSubscription{}
+	for _, t := range strings.Split(param, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !valid[t] {
+			return nil, fmt.Errorf("unknown event type %q", t)
+		}
+		types[t] = true
+	}
+	return types, nil
+}
+
+// minSSEInterval is the fastest refresh a client can ask for
+const minSSEInterval = 250 * time.Millisecond
+
+// minDockerInterval is the fastest docker refresh a client can ask for:
+// each tick runs docker ps -a, which is far heavier than reading /proc
+const minDockerInterval = 5 * time.Second
+
+// sseIntervals returns the refresh interval of each event type: the
+// configured one, or a per-connection override such as "?cpu=1000" in
+// milliseconds, clamped to minSSEInterval (minDockerInterval for docker).
+// A zero or negative configured interval is clamped too; a zero or
+// negative override is an error.
+func (a *API) sseIntervals(query url.Values) (map[string]time.Duration, error) {
+	ms := func(v int) time.Duration { return max(time.Duration(v)*time.Millisecond, minSSEInterval) }
+	refresh := a.cfg().Refresh
+	intervals := map[string]time.Duration{
+		"cpu":       ms(refresh.CPU),
+		"memory":    ms(refresh.Memory),
+		"disk":      ms(refresh.Disk),
+		"network":   ms(refresh.Network),
+		"gpu":       ms(refresh.GPU),
+		"processes": ms(refresh.Processes),
+		"sockets":   ms(refresh.Sockets),
+		"firewall":  ms(refresh.Firewall),
+		"docker":    10 * time.Second, // Docker refreshes every 10 seconds
+	}
+
+	for _, eventType := range sseEventTypes {
+		param := query.Get(eventType)
+		if param == "" {
+			continue
+		}
+		v, err := strconv.Atoi(param)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid %s interval %q", eventType, param)
+		}
+		intervals[eventType] = ms(v)
+		if eventType == "docker" {
+			intervals[eventType] = max(intervals[eventType], minDockerInterval)
+		}
+	}
+
+	// Pressure follows the CPU rate unless set on its own
+	if query.Get("pressure") == "" {
+		intervals["pressure"] = intervals["cpu"]
+	}
+	return intervals, nil
+}
+
 func (a *API) HandleSSE(w http.ResponseWriter, r *http.Request) {
+	types, err := parseSSETypes(r.URL.Query().Get("types"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	intervals, err := a.sseIntervals(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Track SSE connection
 	a.IncrementSSEConnections()
 	defer a.DecrementSSEConnections()
@@ -24,7 +129,6 @@ func (a *API) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// Get flusher
 	flusher, ok := w.(http.Flusher)
@@ -36,31 +140,40 @@ func (a *API) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	// Create channels for each data type
 	ctx := r.Context()
 
+	// Only tick for the event types the client subscribed to. Unsubscribed
+	// types get a nil channel, which never fires in the select below.
+	var tickers []*time.Ticker
+	newTicker := func(eventType string, d time.Duration) <-chan time.Time {
+		if !types.wants(eventType) {
+			return nil
+		}
+		// Clamped here too so a zeroed config value can never reach
+		// time.NewTicker, which panics on intervals <= 0
+		t := time.NewTicker(max(d, minSSEInterval))
+		tickers = append(tickers, t)
+		return t.C
+	}
+
 	// Timers for different refresh rates
-	cpuTicker := time.NewTicker(time.Duration(a.config.Refresh.CPU) * time.Millisecond)
-	memTicker := time.NewTicker(time.Duration(a.config.Refresh.Memory) * time.Millisecond)
-	diskTicker := time.NewTicker(time.Duration(a.config.Refresh.Disk) * time.Millisecond)
-	netTicker := time.NewTicker(time.Duration(a.config.Refresh.Network) * time.Millisecond)
-	gpuTicker := time.NewTicker(time.Duration(a.config.Refresh.GPU) * time.Millisecond)
-	procTicker := time.NewTicker(time.Duration(a.config.Refresh.Processes) * time.Millisecond)
-	sockTicker := time.NewTicker(time.Duration(a.config.Refresh.Sockets) * time.Millisecond)
-	fwTicker := time.NewTicker(time.Duration(a.config.Refresh.Firewall) * time.Millisecond)
-	dockerTicker := time.NewTicker(10 * time.Second) // Docker refreshes every 10 seconds
+	cpuTicker := newTicker("cpu", intervals["cpu"])
+	memTicker := newTicker("memory", intervals["memory"])
+	diskTicker := newTicker("disk", intervals["disk"])
+	netTicker := newTicker("network", intervals["network"])
+	gpuTicker := newTicker("gpu", intervals["gpu"])
+	procTicker := newTicker("processes", intervals["processes"])
+	sockTicker := newTicker("sockets", intervals["sockets"])
+	fwTicker := newTicker("firewall", intervals["firewall"])
+	dockerTicker := newTicker("docker", intervals["docker"])
+	pressureTicker := newTicker("pressure", intervals["pressure"])
 
 	defer func() {
-		cpuTicker.Stop()
-		memTicker.Stop()
-		diskTicker.Stop()
-		netTicker.Stop()
-		gpuTicker.Stop()
-		procTicker.Stop()
-		sockTicker.Stop()
-		fwTicker.Stop()
-		dockerTicker.Stop()
+		for _, t := range tickers {
+			t.Stop()
+		}
 	}()
 
 	// Send initial data immediately
-	if !sendInitialData(w, flusher, a.config) {
+	if !sendInitialData(w, flusher, a.cfg(), types) {
 		return // Client disconnected during initial data
 	}
 
@@ -70,118 +183,156 @@ func (a *API) HandleSSE(w http.ResponseWriter, r *http.Request) {
 		case <-ctx.Done():
 			return
 
-		case <-cpuTicker.C:
+		case <-cpuTicker:
 			if data, err := collectors.GetCPUInfo(); err == nil {
 				if sendSSEEvent(w, flusher, "cpu", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-memTicker.C:
+		case <-memTicker:
 			if data, err := collectors.GetMemoryInfo(); err == nil {
 				if sendSSEEvent(w, flusher, "memory", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-diskTicker.C:
+		case <-diskTicker:
 			if data, err := collectors.GetDiskInfo(); err == nil {
 				if sendSSEEvent(w, flusher, "disk", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-netTicker.C:
+		case <-netTicker:
 			if data, err := collectors.GetNetworkInfo(); err == nil {
 				if sendSSEEvent(w, flusher, "network", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-gpuTicker.C:
+		case <-gpuTicker:
 			if data, err := collectors.GetGPUInfo(); err == nil {
 				if sendSSEEvent(w, flusher, "gpu", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-procTicker.C:
+		case <-procTicker:
 			if data, err := collectors.GetProcessList(); err == nil {
+				if a.cfg().Collectors.Processes.NormalizeCPU {
+					data.NormalizeCPU()
+				}
 				if sendSSEEvent(w, flusher, "processes", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-sockTicker.C:
+		case <-sockTicker:
 			if data, err := collectors.GetSocketInfo(); err == nil {
 				if sendSSEEvent(w, flusher, "sockets", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-fwTicker.C:
+		case <-fwTicker:
 			if data, err := collectors.GetFirewallInfo(); err == nil {
 				if sendSSEEvent(w, flusher, "firewall", data) != nil {
 					return // Client disconnected
 				}
 			}
 
-		case <-dockerTicker.C:
+		case <-dockerTicker:
 			data := collectors.GetDockerInfo()
 			if sendSSEEvent(w, flusher, "docker", data) != nil {
 				return // Client disconnected
 			}
+
+		case <-pressureTicker:
+			if data, err := collectors.GetPressureInfo(); err == nil {
+				if sendSSEEvent(w, flusher, "pressure", data) != nil {
+					return // Client disconnected
+				}
+			}
 		}
 	}
 }
 
-func sendInitialData(w http.ResponseWriter, flusher http.Flusher, cfg *config.Config) bool {
-	// Send all data immediately on connection
+func sendInitialData(w http.ResponseWriter, flusher http.Flusher, cfg *config.Config, types sseSubscription) bool {
+	// Send all subscribed data immediately on connection
 	// Returns false if client disconnected
-	if data, err := collectors.GetCPUInfo(); err == nil {
-		if sendSSEEvent(w, flusher, "cpu", data) != nil {
-			return false
+	if types.wants("cpu") {
+		if data, err := collectors.GetCPUInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "cpu", data) != nil {
+				return false
+			}
 		}
 	}
-	if data, err := collectors.GetMemoryInfo(); err == nil {
-		if sendSSEEvent(w, flusher, "memory", data) != nil {
-			return false
+	if types.wants("memory") {
+		if data, err := collectors.GetMemoryInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "memory", data) != nil {
+				return false
+			}
 		}
 	}
-	if data, err := collectors.GetDiskInfo(); err == nil {
-		if sendSSEEvent(w, flusher, "disk", data) != nil {
-			return false
+	if types.wants("disk") {
+		if data, err := collectors.GetDiskInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "disk", data) != nil {
+				return false
+			}
 		}
 	}
-	if data, err := collectors.GetNetworkInfo(); err == nil {
-		if sendSSEEvent(w, flusher, "network", data) != nil {
-			return false
+	if types.wants("network") {
+		if data, err := collectors.GetNetworkInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "network", data) != nil {
+				return false
+			}
 		}
 	}
-	if data, err := collectors.GetGPUInfo(); err == nil {
-		if sendSSEEvent(w, flusher, "gpu", data) != nil {
-			return false
+	if types.wants("gpu") {
+		if data, err := collectors.GetGPUInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "gpu", data) != nil {
+				return false
+			}
 		}
 	}
-	if data, err := collectors.GetProcessList(); err == nil {
-		if sendSSEEvent(w, flusher, "processes", data) != nil {
-			return false
+	if types.wants("processes") {
+		if data, err := collectors.GetProcessList(); err == nil {
+			if cfg.Collectors.Processes.NormalizeCPU {
+				data.NormalizeCPU()
+			}
+			if sendSSEEvent(w, flusher, "processes", data) != nil {
+				return false
+			}
 		}
 	}
-	if data, err := collectors.GetSocketInfo(); err == nil {
-		if sendSSEEvent(w, flusher, "sockets", data) != nil {
-			return false
+	if types.wants("sockets") {
+		if data, err := collectors.GetSocketInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "sockets", data) != nil {
+				return false
+			}
 		}
 	}
-	if data, err := collectors.GetFirewallInfo(); err == nil {
-		if sendSSEEvent(w, flusher, "firewall", data) != nil {
-			return false
+	if types.wants("firewall") {
+		if data, err := collectors.GetFirewallInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "firewall", data) != nil {
+				return false
+			}
 		}
 	}
 	// Send docker info
-	dockerData := collectors.GetDockerInfo()
-	if sendSSEEvent(w, flusher, "docker", dockerData) != nil {
-		return false
+	if types.wants("docker") {
+		dockerData := collectors.GetDockerInfo()
+		if sendSSEEvent(w, flusher, "docker", dockerData) != nil {
+			return false
+		}
+	}
+	if types.wants("pressure") {
+		if data, err := collectors.GetPressureInfo(); err == nil {
+			if sendSSEEvent(w, flusher, "pressure", data) != nil {
+				return false
+			}
+		}
 	}
 	return true
 }
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"syspeek/config"
+)
+
+// zeroRefresh is a config whose refresh intervals are all v milliseconds
+func zeroRefresh(v int) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Refresh = config.RefreshConfig{
+		CPU: v, Memory: v, Disk: v, Network: v,
+		GPU: v, Processes: v, Sockets: v, Firewall: v,
+	}
+	return cfg
+}
+
+func TestSSEIntervalsClampsConfig(t *testing.T) {
+	for _, v := range []int{0, -1000} {
+		intervals, err := NewAPI(zeroRefresh(v), nil, true).sseIntervals(url.Values{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, eventType := range sseEventTypes {
+			if d := intervals[eventType]; d < minSSEInterval {
+				t.Errorf("config interval %d: %s interval %v, below %v", v, eventType, d, minSSEInterval)
+			}
+		}
+	}
+}
+
+func TestSSEIntervalsQuery(t *testing.T) {
+	a := NewAPI(config.DefaultConfig(), nil, true)
+
+	tests := []struct {
+		query   string
+		want    map[string]time.Duration
+		invalid bool
+	}{
+		{query: "cpu=2000", want: map[string]time.Duration{"cpu": 2 * time.Second, "pressure": 2 * time.Second}},
+		{query: "cpu=2000&pressure=5000", want: map[string]time.Duration{"cpu": 2 * time.Second, "pressure": 5 * time.Second}},
+		{query: "memory=1", want: map[string]time.Duration{"memory": minSSEInterval}},
+		{query: "docker=250", want: map[string]time.Duration{"docker": minDockerInterval}},
+		{query: "docker=30000", want: map[string]time.Duration{"docker": 30 * time.Second}},
+		{query: "cpu=0", invalid: true},
+		{query: "cpu=-5", invalid: true},
+		{query: "disk=fast", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			intervals, err := a.sseIntervals(query)
+			if tt.invalid {
+				if err == nil {
+					t.Error("accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for eventType, want := range tt.want {
+				if intervals[eventType] != want {
+					t.Errorf("%s interval %v, want %v", eventType, intervals[eventType], want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSSERejectsZeroInterval(t *testing.T) {
+	a := NewAPI(config.DefaultConfig(), nil, true)
+	for _, query := range []string{"cpu=0", "memory=-1", "types=cpu&cpu=0"} {
+		rec := httptest.NewRecorder()
+		a.HandleSSE(rec, httptest.NewRequest(http.MethodGet, "/api/sse?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("?%s: status %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandleSSEZeroConfigInterval(t *testing.T) {
+	// time.NewTicker panics on intervals <= 0; the stream must start anyway
+	for _, v := range []int{0, -1} {
+		a := NewAPI(zeroRefresh(v), nil, true)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		req := httptest.NewRequest(http.MethodGet, "/api/sse?types=memory", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		a.HandleSSE(rec, req)
+		cancel()
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("config interval %d: status %d", v, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"os"
+
+	"syspeek/collectors"
+)
+
+// SummaryMount is the usage of one mounted filesystem
+type SummaryMount struct {
+	MountPoint  string  `json:"mountPoint"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	UsedPercent float64 `json:"usedPercent"`
+}
+
+// Summary is a compact snapshot of the host for status pages and external
+// health checks. Fields whose collector failed are left out and the
+// failure is listed in Errors.
+type Summary struct {
+	Hostname      string            `json:"hostname"`
+	CPUPercent    *float64          `json:"cpuPercent,omitempty"`
+	LoadAvg       []float64         `json:"loadAvg,omitempty"`
+	Uptime        string            `json:"uptime,omitempty"`
+	MemoryPercent *float64          `json:"memoryPercent,omitempty"`
+	Mounts        []SummaryMount    `json:"mounts,omitempty"`
+	ProcessCount  *int              `json:"processCount,omitempty"`
+	Errors        map[string]string `json:"errors,omitempty"` // Collector name -> error
+}
+
+// HandleSummary returns CPU, memory, disk, load, uptime and process count in
+// one response, collecting each once
+func (a *API) HandleSummary(w http.ResponseWriter, r *http.Request) {
+	summary := Summary{Hostname: a.cfg().UI.Hostname}
+	if summary.Hostname == "" {
+		summary.Hostname, _ = os.Hostname()
+	}
+
+	fail := func(name string, err error) {
+		if summary.Errors == nil {
+			summary.Errors = make(map[string]string)
+		}
+		summary.Errors[name] = err.Error()
+	}
+
+	if cpu, err := collectors.GetCPUInfo(); err == nil {
+		summary.CPUPercent = &cpu.UsagePercent
+		summary.LoadAvg = cpu.LoadAvg
+		summary.Uptime = cpu.Uptime
+	} else {
+		fail("cpu", err)
+	}
+
+	if mem, err := collectors.GetMemoryInfo(); err == nil {
+		summary.MemoryPercent = &mem.UsedPercent
+	} else {
+		fail("memory", err)
+	}
+
+	if disk, err := collectors.GetDiskInfo(); err == nil {
+		for _, p := range disk.Partitions {
+			summary.Mounts = append(summary.Mounts, SummaryMount{
+				MountPoint:  p.MountPoint,
+				Total:       p.Total,
+				Used:        p.Used,
+				UsedPercent: p.UsedPercent,
+			})
+		}
+	} else {
+		fail("disk", err)
+	}
+
+	ctx, cancel := collectContext(r, a.cfg().Collectors.Processes.Timeout)
+	defer cancel()
+	if procs, err := collectors.GetProcessListContext(ctx); err == nil {
+		summary.ProcessCount = &procs.TotalCount
+	} else {
+		fail("processes", err)
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -3,60 +3,140 @@ package auth
 import (
 	"crypto/md5"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword generates md5 hash of "syspeek_" + password
+// HashPassword generates md5 hash of "syspeek_" + password.
+// Kept for existing configs; new configs should use HashPasswordBcrypt.
 func HashPassword(password string) string {
 	hash := md5.Sum([]byte("syspeek_" + password))
 	return hex.EncodeToString(hash[:])
 }
 
+// HashPasswordBcrypt generates a salted bcrypt hash of password
+func HashPasswordBcrypt(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// VerifyPassword checks a plain-text password against a stored hash, which
+// may be a bcrypt hash ("$2a$...") or a legacy hex MD5 from HashPassword.
+func VerifyPassword(stored, plain string) bool {
+	if stored == "" {
+		return false
+	}
+	if strings.HasPrefix(stored, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(plain)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(HashPassword(plain)), []byte(strings.ToLower(stored))) == 1
+}
+
 type Session struct {
 	Token     string
 	Username  string
+	Role      Role
 	ReadWrite bool // true = can perform actions, false = read-only
 	CreatedAt time.Time
 	ExpiresAt time.Time
 }
 
+// Role is what a user may do once logged in
+type Role string
+
+const (
+	RoleReadWrite Role = "readwrite" // View and perform actions
+	RoleReadOnly  Role = "readonly"  // View only
+)
+
+// User is one account that can log in. Password is the stored bcrypt or
+// legacy MD5 hash, as written in the config.
+type User struct {
+	Username string
+	Password string
+	Role     Role
+}
+
+// APIToken is a static credential accepted in the Authorization header
+type APIToken struct {
+	Name      string
+	Token     string
+	ReadWrite bool
+}
+
 type AuthManager struct {
-	// Read-write user (admin)
-	username string
-	password string
-	// Read-only user
-	readOnlyUsername string
-	readOnlyPassword string
+	// Accounts and static API tokens, guarded by mu since a config reload
+	// can replace them
+	users  []User
+	tokens []APIToken
 	// Sessions
 	sessions map[string]*Session
 	mu       sync.RWMutex
+	// Failed login tracking per client IP (guarded by mu)
+	attempts         map[string]*loginAttempts
+	maxLoginAttempts int
+	loginWindow      time.Duration
+	loginLockout     time.Duration
+	trustProxy       bool // Key failures on X-Forwarded-For, as HandleLogin does
+	// Session lifetime; with slidingExpiration each use extends it
+	sessionTTL        time.Duration
+	slidingExpiration bool
 	// Flags
-	hasReadWrite bool // Has read-write credentials configured
-	hasReadOnly  bool // Has read-only credentials configured
-	isPublic     bool // Public read-only access (no login required for viewing)
-	isAdmin      bool // Full admin access without authentication
+	isPublic bool // Public read-only access (no login required for viewing)
+	isAdmin  bool // Full admin access without authentication
 }
 
-func NewAuthManager(username, password, readOnlyUsername, readOnlyPassword string, isPublic, isAdmin bool) *AuthManager {
+func NewAuthManager(users []User, isPublic, isAdmin bool) *AuthManager {
 	return &AuthManager{
-		username:         username,
-		password:         password,
-		readOnlyUsername: readOnlyUsername,
-		readOnlyPassword: readOnlyPassword,
+		users:            usable(users),
 		sessions:         make(map[string]*Session),
-		hasReadWrite:     username != "" && password != "",
-		hasReadOnly:      readOnlyUsername != "" && readOnlyPassword != "",
+		attempts:         make(map[string]*loginAttempts),
+		maxLoginAttempts: 5,
+		loginWindow:      5 * time.Minute,
+		loginLockout:     15 * time.Minute,
+		sessionTTL:       24 * time.Hour,
 		isPublic:         isPublic,
 		isAdmin:          isAdmin,
 	}
 }
 
+// usable drops accounts missing a username or password
+func usable(users []User) []User {
+	var out []User
+	for _, u := range users {
+		if u.Username != "" && u.Password != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// hasRole reports whether some user has role; callers hold mu
+func (am *AuthManager) hasRole(role Role) bool {
+	for _, u := range am.users {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
 // IsEnabled returns true if any form of authentication is configured
 func (am *AuthManager) IsEnabled() bool {
-	return am.hasReadWrite || am.hasReadOnly
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return len(am.users) > 0 || len(am.tokens) > 0
 }
 
 // RequiresLoginForReadOnly returns true if login is required to view the app
@@ -71,12 +151,16 @@ func (am *AuthManager) RequiresLoginForReadOnly() bool {
 
 // HasReadWriteAuth returns true if read-write credentials are configured
 func (am *AuthManager) HasReadWriteAuth() bool {
-	return am.hasReadWrite
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.hasRole(RoleReadWrite)
 }
 
 // HasReadOnlyAuth returns true if read-only credentials are configured
 func (am *AuthManager) HasReadOnlyAuth() bool {
-	return am.hasReadOnly
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.hasRole(RoleReadOnly)
 }
 
 // IsPublic returns true if public read-only access is enabled
@@ -89,44 +173,187 @@ func (am *AuthManager) IsAdminMode() bool {
 	return am.isAdmin
 }
 
+// SetUsers replaces the accounts that can log in. Sessions of a user
+// whose password or role changed, or who was removed, are ended.
+func (am *AuthManager) SetUsers(users []User) {
+	users = usable(users)
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	unchanged := make(map[string]bool)
+	for _, old := range am.users {
+		for _, u := range users {
+			if u == old {
+				unchanged[u.Username] = true
+			}
+		}
+	}
+	for token, session := range am.sessions {
+		if !unchanged[session.Username] {
+			delete(am.sessions, token)
+		}
+	}
+
+	am.users = users
+}
+
+// SetAPITokens replaces the accepted API tokens
+func (am *AuthManager) SetAPITokens(tokens []APIToken) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.tokens = tokens
+}
+
+// apiToken finds the API token matching token. Every entry is compared
+// in constant time so the timing doesn't reveal how much of it matched.
+func (am *AuthManager) apiToken(token string) (APIToken, bool) {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	var found APIToken
+	ok := false
+	for _, t := range am.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			found, ok = t, true
+		}
+	}
+	return found, ok
+}
+
+// SetSessionTTL configures how long sessions last. When sliding is true the
+// expiry is pushed forward every time the session is validated.
+func (am *AuthManager) SetSessionTTL(ttl time.Duration, sliding bool) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	if ttl > 0 {
+		am.sessionTTL = ttl
+	}
+	am.slidingExpiration = sliding
+}
+
+// SessionTTL returns the configured session lifetime
+func (am *AuthManager) SessionTTL() time.Duration {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.sessionTTL
+}
+
+type loginAttempts struct {
+	failures    int
+	firstFailed time.Time
+	lockedUntil time.Time
+}
+
+// SetLoginLimits configures brute-force protection: after maxAttempts failed
+// logins from one IP within window, that IP is locked out for lockout.
+// maxAttempts <= 0 disables the limit.
+func (am *AuthManager) SetLoginLimits(maxAttempts int, window, lockout time.Duration) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.maxLoginAttempts = maxAttempts
+	am.loginWindow = window
+	am.loginLockout = lockout
+}
+
+// SetTrustProxy makes Basic Auth failures count against the client IP from
+// X-Forwarded-For, matching the key HandleLogin passes to Login
+func (am *AuthManager) SetTrustProxy(trustProxy bool) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.trustProxy = trustProxy
+}
+
+// LoginLockout returns how long the given IP must wait before trying again
+func (am *AuthManager) LoginLockout(ip string) (time.Duration, bool) {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	if a, ok := am.attempts[ip]; ok {
+		if remaining := time.Until(a.lockedUntil); remaining > 0 {
+			return remaining, true
+		}
+	}
+	return 0, false
+}
+
+func (am *AuthManager) recordLoginFailure(ip string) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if am.maxLoginAttempts <= 0 {
+		return
+	}
+
+	now := time.Now()
+	a, ok := am.attempts[ip]
+	if !ok || now.Sub(a.firstFailed) > am.loginWindow {
+		a = &loginAttempts{firstFailed: now}
+		am.attempts[ip] = a
+	}
+
+	a.failures++
+	if a.failures >= am.maxLoginAttempts {
+		a.lockedUntil = now.Add(am.loginLockout)
+		a.failures = 0
+		a.firstFailed = now
+	}
+}
+
+func (am *AuthManager) clearLoginFailures(ip string) {
+	am.mu.Lock()
+	delete(am.attempts, ip)
+	am.mu.Unlock()
+}
+
 // Login attempts to authenticate and returns (token, readWrite, success)
 // The password parameter is the plain-text password from the user.
-// It gets hashed and compared against the stored hash in config.
-func (am *AuthManager) Login(username, password string) (string, bool, bool) {
-	hashedPassword := HashPassword(password)
+// It is verified against the stored bcrypt or legacy MD5 hash in config.
+// Failures are counted per client ip; a locked-out ip always fails.
+func (am *AuthManager) Login(ip, username, password string) (string, bool, bool) {
+	if _, locked := am.LoginLockout(ip); locked {
+		return "", false, false
+	}
 
-	// Try read-write credentials first
-	if am.hasReadWrite && username == am.username && hashedPassword == am.password {
-		token := generateToken()
-		session := &Session{
-			Token:     token,
-			Username:  username,
-			ReadWrite: true,
-			CreatedAt: time.Now(),
-			ExpiresAt: time.Now().Add(24 * time.Hour),
+	token, readWrite, ok := am.login(username, password)
+	if ok {
+		am.clearLoginFailures(ip)
+	} else {
+		am.recordLoginFailure(ip)
+	}
+	return token, readWrite, ok
+}
+
+// verifyUser finds the account matching username and password
+func (am *AuthManager) verifyUser(username, password string) (User, bool) {
+	am.mu.RLock()
+	users := am.users
+	am.mu.RUnlock()
+
+	for _, u := range users {
+		if subtle.ConstantTimeCompare([]byte(u.Username), []byte(username)) == 1 && VerifyPassword(u.Password, password) {
+			return u, true
 		}
-		am.mu.Lock()
-		am.sessions[token] = session
-		am.mu.Unlock()
-		return token, true, true
 	}
+	return User{}, false
+}
 
-	// Try read-only credentials
-	if am.hasReadOnly && username == am.readOnlyUsername && hashedPassword == am.readOnlyPassword {
+func (am *AuthManager) login(username, password string) (string, bool, bool) {
+	if u, ok := am.verifyUser(username, password); ok {
 		token := generateToken()
 		session := &Session{
 			Token:     token,
 			Username:  username,
-			ReadWrite: false,
+			Role:      u.Role,
+			ReadWrite: u.Role == RoleReadWrite,
 			CreatedAt: time.Now(),
-			ExpiresAt: time.Now().Add(24 * time.Hour),
+			ExpiresAt: time.Now().Add(am.SessionTTL()),
 		}
 		am.mu.Lock()
 		am.sessions[token] = session
 		am.mu.Unlock()
-		return token, false, true
+		return token, session.ReadWrite, true
 	}
-
 	return "", false, false
 }
 
@@ -136,20 +363,99 @@ func (am *AuthManager) Logout(token string) {
 	am.mu.Unlock()
 }
 
+// ValidateSession reports whether token is a live session. The lookup,
+// expiry check and sliding extension share one write lock, since other
+// requests may be extending the same session concurrently.
 func (am *AuthManager) ValidateSession(token string) bool {
-	am.mu.RLock()
-	session, exists := am.sessions[token]
-	am.mu.RUnlock()
+	am.mu.Lock()
+	defer am.mu.Unlock()
 
+	session, exists := am.sessions[token]
 	if !exists {
 		return false
 	}
 
-	if time.Now().After(session.ExpiresAt) {
-		am.Logout(token)
+	now := time.Now()
+	if now.After(session.ExpiresAt) {
+		delete(am.sessions, token)
 		return false
 	}
 
+	if am.slidingExpiration {
+		session.ExpiresAt = now.Add(am.sessionTTL)
+	}
+	return true
+}
+
+// sessionIDLength is how much of a token identifies its session in
+// listings; the rest is never shown
+const sessionIDLength = 12
+
+// SessionInfo describes a live session without revealing its token
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Role      Role      `json:"role"`
+	ReadWrite bool      `json:"readWrite"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SessionID returns the public identifier of a session token
+func SessionID(token string) string {
+	if len(token) > sessionIDLength {
+		return token[:sessionIDLength]
+	}
+	return token
+}
+
+// Sessions returns the unexpired sessions, oldest first
+func (am *AuthManager) Sessions() []SessionInfo {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	now := time.Now()
+	list := []SessionInfo{}
+	for token, session := range am.sessions {
+		if now.After(session.ExpiresAt) {
+			continue
+		}
+		list = append(list, SessionInfo{
+			ID:        SessionID(token),
+			Username:  session.Username,
+			Role:      session.Role,
+			ReadWrite: session.ReadWrite,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// RevokeSession ends the session with the given ID. It reports false when
+// no session, or more than one, has that ID.
+func (am *AuthManager) RevokeSession(id string) bool {
+	if len(id) < sessionIDLength {
+		return false
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	var match string
+	for token := range am.sessions {
+		if SessionID(token) == id {
+			if match != "" {
+				return false
+			}
+			match = token
+		}
+	}
+	if match == "" {
+		return false
+	}
+	delete(am.sessions, match)
 	return true
 }
 
@@ -168,6 +474,111 @@ func (am *AuthManager) IsReadWrite(token string) bool {
 	return session.ReadWrite
 }
 
+// bearerToken takes the token out of an Authorization header, which may
+// or may not carry a "Bearer " prefix
+func bearerToken(header string) string {
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return header
+}
+
+// ClientIP returns the remote address of the request. When trustProxy is
+// set the last X-Forwarded-For entry is used instead: that is the one the
+// trusted proxy appended, while anything before it came from the client.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			entries := strings.Split(values[len(values)-1], ",")
+			if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Identity is who a request is authenticated as
+type Identity struct {
+	Username  string // API token name for token requests
+	Role      Role
+	ReadWrite bool
+	APIToken  bool // Authenticated with an API token rather than a session
+}
+
+// Identify checks the session cookie, then the Authorization header,
+// which can hold either a session token or one of the API tokens
+func (am *AuthManager) Identify(r *http.Request) (Identity, bool) {
+	fromSession := func(token string) (Identity, bool) {
+		if !am.ValidateSession(token) {
+			return Identity{}, false
+		}
+		session := am.GetSession(token)
+		if session == nil {
+			return Identity{}, false
+		}
+		return Identity{Username: session.Username, Role: session.Role, ReadWrite: session.ReadWrite}, true
+	}
+
+	if cookie, err := r.Cookie("session"); err == nil {
+		if id, ok := fromSession(cookie.Value); ok {
+			return id, true
+		}
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		return am.basicAuth(r, username, password)
+	}
+
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return Identity{}, false
+	}
+	if id, ok := fromSession(token); ok {
+		return id, true
+	}
+	if t, ok := am.apiToken(token); ok {
+		role := RoleReadOnly
+		if t.ReadWrite {
+			role = RoleReadWrite
+		}
+		return Identity{Username: t.Name, Role: role, ReadWrite: t.ReadWrite, APIToken: true}, true
+	}
+	return Identity{}, false
+}
+
+// basicAuth checks HTTP Basic credentials against the user accounts
+// without creating a session. Failures count towards the same lockout as
+// Login, keyed by the same client IP.
+func (am *AuthManager) basicAuth(r *http.Request, username, password string) (Identity, bool) {
+	am.mu.RLock()
+	trustProxy := am.trustProxy
+	am.mu.RUnlock()
+
+	ip := ClientIP(r, trustProxy)
+	if _, locked := am.LoginLockout(ip); locked {
+		return Identity{}, false
+	}
+
+	u, ok := am.verifyUser(username, password)
+	if !ok {
+		am.recordLoginFailure(ip)
+		return Identity{}, false
+	}
+	return Identity{Username: u.Username, Role: u.Role, ReadWrite: u.Role == RoleReadWrite}, true
+}
+
+// authenticate reports whether the request is authenticated and whether
+// it may perform actions
+func (am *AuthManager) authenticate(r *http.Request) (authenticated, readWrite bool) {
+	id, ok := am.Identify(r)
+	return ok, id.ReadWrite
+}
+
 // Middleware handles authentication for routes
 // requireAuth: if true, requires authenticated session
 // requireReadWrite: if true, requires read-write session (only matters if requireAuth is true)
@@ -181,17 +592,7 @@ func (am *AuthManager) Middleware(next http.HandlerFunc, requireAuth bool) http.
 			return
 		}
 
-		// Get token from cookie or header
-		token := ""
-		if cookie, err := r.Cookie("session"); err == nil {
-			token = cookie.Value
-		}
-		if token == "" {
-			token = r.Header.Get("Authorization")
-		}
-
-		isAuthenticated := am.ValidateSession(token)
-		isReadWrite := am.IsReadWrite(token)
+		isAuthenticated, isReadWrite := am.authenticate(r)
 
 		// Set headers for downstream handlers
 		if isAuthenticated {
@@ -235,21 +636,13 @@ func (am *AuthManager) MiddlewareReadWrite(next http.HandlerFunc) http.HandlerFu
 			return
 		}
 
-		// Get token from cookie or header
-		token := ""
-		if cookie, err := r.Cookie("session"); err == nil {
-			token = cookie.Value
-		}
-		if token == "" {
-			token = r.Header.Get("Authorization")
-		}
-
-		if !am.ValidateSession(token) {
+		isAuthenticated, isReadWrite := am.authenticate(r)
+		if !isAuthenticated {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		if !am.IsReadWrite(token) {
+		if !isReadWrite {
 			http.Error(w, "Forbidden: Read-write access required", http.StatusForbidden)
 			return
 		}
@@ -266,7 +659,7 @@ func generateToken() string {
 	return hex.EncodeToString(bytes)
 }
 
-// CleanupExpiredSessions removes expired sessions
+// CleanupExpiredSessions removes expired sessions and stale login attempts
 func (am *AuthManager) CleanupExpiredSessions() {
 	am.mu.Lock()
 	defer am.mu.Unlock()
@@ -277,14 +670,10 @@ func (am *AuthManager) CleanupExpiredSessions() {
 			delete(am.sessions, token)
 		}
 	}
-}
 
-// StartCleanupRoutine starts a goroutine that periodically cleans up expired sessions
-func (am *AuthManager) StartCleanupRoutine() {
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		for range ticker.C {
-			am.CleanupExpiredSessions()
+	for ip, a := range am.attempts {
+		if now.After(a.lockedUntil) && now.Sub(a.firstFailed) > am.loginWindow {
+			delete(am.attempts, ip)
 		}
-	}()
+	}
 }
@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	adminToken  = "rw-api-token"
+	viewerToken = "ro-api-token"
+)
+
+// newTestManager has a read-write "admin" and a read-only "viewer", both
+// with password "secret", and a read-write and a read-only API token
+func newTestManager(t *testing.T) *AuthManager {
+	t.Helper()
+	bcryptHash, err := HashPasswordBcrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	am := NewAuthManager([]User{
+		{Username: "admin", Password: bcryptHash, Role: RoleReadWrite},
+		{Username: "viewer", Password: HashPassword("secret"), Role: RoleReadOnly},
+	}, false, false)
+	am.SetAPITokens([]APIToken{
+		{Name: "ci", Token: adminToken, ReadWrite: true},
+		{Name: "grafana", Token: viewerToken, ReadWrite: false},
+	})
+	return am
+}
+
+func login(t *testing.T, am *AuthManager, username string) string {
+	t.Helper()
+	token, _, ok := am.Login("192.0.2.1", username, "secret")
+	if !ok {
+		t.Fatalf("login as %s failed", username)
+	}
+	return token
+}
+
+func TestIdentify(t *testing.T) {
+	am := newTestManager(t)
+	adminSession := login(t, am, "admin")
+	viewerSession := login(t, am, "viewer")
+
+	tests := []struct {
+		name      string
+		cookie    string
+		basicUser string
+		basicPass string
+		header    string
+		wantOK    bool
+		want      Identity
+	}{
+		{name: "nothing"},
+		{name: "session cookie, read-write", cookie: adminSession,
+			wantOK: true, want: Identity{Username: "admin", Role: RoleReadWrite, ReadWrite: true}},
+		{name: "session cookie, read-only", cookie: viewerSession,
+			wantOK: true, want: Identity{Username: "viewer", Role: RoleReadOnly}},
+		{name: "session token as bearer", header: "Bearer " + viewerSession,
+			wantOK: true, want: Identity{Username: "viewer", Role: RoleReadOnly}},
+		{name: "session token without prefix", header: adminSession,
+			wantOK: true, want: Identity{Username: "admin", Role: RoleReadWrite, ReadWrite: true}},
+		{name: "read-write API token", header: "Bearer " + adminToken,
+			wantOK: true, want: Identity{Username: "ci", Role: RoleReadWrite, ReadWrite: true, APIToken: true}},
+		{name: "read-only API token", header: "Bearer " + viewerToken,
+			wantOK: true, want: Identity{Username: "grafana", Role: RoleReadOnly, APIToken: true}},
+		{name: "unknown token", header: "Bearer nope"},
+		{name: "basic, read-write", basicUser: "admin", basicPass: "secret",
+			wantOK: true, want: Identity{Username: "admin", Role: RoleReadWrite, ReadWrite: true}},
+		{name: "basic, read-only", basicUser: "viewer", basicPass: "secret",
+			wantOK: true, want: Identity{Username: "viewer", Role: RoleReadOnly}},
+		{name: "basic, wrong password", basicUser: "admin", basicPass: "wrong"},
+		{name: "basic, unknown user", basicUser: "mallory", basicPass: "secret"},
+
+		// Precedence: a valid cookie wins over the Authorization header
+		{name: "cookie over API token", cookie: viewerSession, header: "Bearer " + adminToken,
+			wantOK: true, want: Identity{Username: "viewer", Role: RoleReadOnly}},
+		{name: "cookie over basic", cookie: viewerSession, basicUser: "admin", basicPass: "secret",
+			wantOK: true, want: Identity{Username: "viewer", Role: RoleReadOnly}},
+		// A stale cookie falls through to the header
+		{name: "bad cookie, API token", cookie: "stale", header: "Bearer " + viewerToken,
+			wantOK: true, want: Identity{Username: "grafana", Role: RoleReadOnly, APIToken: true}},
+		{name: "bad cookie, basic", cookie: "stale", basicUser: "admin", basicPass: "secret",
+			wantOK: true, want: Identity{Username: "admin", Role: RoleReadWrite, ReadWrite: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/cpu", nil)
+			r.RemoteAddr = "198.51.100.7:5555"
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+			if tt.basicUser != "" {
+				r.SetBasicAuth(tt.basicUser, tt.basicPass)
+			} else if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			got, ok := am.Identify(r)
+			if ok != tt.wantOK {
+				t.Fatalf("Identify ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("Identify = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifyExpiredSession(t *testing.T) {
+	am := newTestManager(t)
+	token := login(t, am, "admin")
+
+	am.mu.Lock()
+	am.sessions[token].ExpiresAt = time.Now().Add(-time.Second)
+	am.mu.Unlock()
+
+	for _, set := range []func(*http.Request){
+		func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "session", Value: token}) },
+		func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) },
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/api/cpu", nil)
+		set(r)
+		if id, ok := am.Identify(r); ok {
+			t.Errorf("expired session accepted as %+v", id)
+		}
+	}
+	if am.GetSession(token) != nil {
+		t.Error("expired session not removed")
+	}
+}
+
+func TestSlidingExpiration(t *testing.T) {
+	am := newTestManager(t)
+	am.SetSessionTTL(time.Hour, true)
+	token := login(t, am, "viewer")
+
+	am.mu.Lock()
+	am.sessions[token].ExpiresAt = time.Now().Add(time.Minute)
+	am.mu.Unlock()
+
+	if !am.ValidateSession(token) {
+		t.Fatal("live session rejected")
+	}
+	if remaining := time.Until(am.GetSession(token).ExpiresAt); remaining < 59*time.Minute {
+		t.Errorf("sliding session expires in %v, want about an hour", remaining)
+	}
+}
+
+// TestValidateSessionConcurrent extends one sliding session from many
+// requests at once; run with -race to catch unguarded ExpiresAt access
+func TestValidateSessionConcurrent(t *testing.T) {
+	am := newTestManager(t)
+	am.SetSessionTTL(time.Hour, true)
+	token := login(t, am, "viewer")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if !am.ValidateSession(token) {
+					t.Error("live session rejected")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBasicAuthLockout(t *testing.T) {
+	am := newTestManager(t)
+	am.SetLoginLimits(3, time.Minute, time.Minute)
+
+	basic := func(password string) bool {
+		r := httptest.NewRequest(http.MethodGet, "/api/cpu", nil)
+		r.RemoteAddr = "198.51.100.9:4000"
+		r.SetBasicAuth("admin", password)
+		_, ok := am.Identify(r)
+		return ok
+	}
+
+	for i := 0; i < 3; i++ {
+		if basic("wrong") {
+			t.Fatal("wrong password accepted")
+		}
+	}
+	if basic("secret") {
+		t.Error("right password accepted while locked out")
+	}
+	if _, locked := am.LoginLockout("198.51.100.9"); !locked {
+		t.Error("client address not locked out")
+	}
+}
+
+func TestBasicAuthLockoutBehindProxy(t *testing.T) {
+	am := newTestManager(t)
+	am.SetLoginLimits(3, time.Minute, time.Minute)
+	am.SetTrustProxy(true)
+
+	basic := func(client, password string) bool {
+		r := httptest.NewRequest(http.MethodGet, "/api/cpu", nil)
+		r.RemoteAddr = "127.0.0.1:4000"
+		r.Header.Set("X-Forwarded-For", client)
+		r.SetBasicAuth("admin", password)
+		_, ok := am.Identify(r)
+		return ok
+	}
+
+	for i := 0; i < 3; i++ {
+		basic("203.0.113.5", "wrong")
+	}
+	if _, locked := am.LoginLockout("203.0.113.5"); !locked {
+		t.Error("forwarded client not locked out")
+	}
+	if _, locked := am.LoginLockout("127.0.0.1"); locked {
+		t.Error("proxy address locked out")
+	}
+	if !basic("203.0.113.6", "secret") {
+		t.Error("other client behind the proxy locked out")
+	}
+}
+
+func TestMiddlewareRoles(t *testing.T) {
+	am := newTestManager(t)
+	viewerSession := login(t, am, "viewer")
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	tests := []struct {
+		name      string
+		header    string
+		readWrite bool
+		want      int
+	}{
+		{"anonymous read", "", false, http.StatusUnauthorized},
+		{"read-only session reads", viewerSession, false, http.StatusOK},
+		{"read-only token reads", viewerToken, false, http.StatusOK},
+		{"read-only session acts", viewerSession, true, http.StatusForbidden},
+		{"read-only token acts", viewerToken, true, http.StatusForbidden},
+		{"read-write token acts", adminToken, true, http.StatusOK},
+		{"anonymous acts", "", true, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := am.Middleware(ok, true)
+			if tt.readWrite {
+				h = am.MiddlewareReadWrite(ok)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/process/1/kill", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h(rec, r)
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		forwarded  []string
+		trustProxy bool
+		want       string
+	}{
+		{name: "no proxy", want: "198.51.100.7"},
+		{name: "untrusted header", forwarded: []string{"203.0.113.5"}, want: "198.51.100.7"},
+		{name: "single entry", forwarded: []string{"203.0.113.5"}, trustProxy: true, want: "203.0.113.5"},
+		// The client can prepend anything; only the proxy's entry counts
+		{name: "spoofed prefix", forwarded: []string{"10.9.9.9, 203.0.113.5"}, trustProxy: true, want: "203.0.113.5"},
+		{name: "repeated header", forwarded: []string{"10.9.9.9", "203.0.113.5"}, trustProxy: true, want: "203.0.113.5"},
+		{name: "empty entry", forwarded: []string{"10.9.9.9, "}, trustProxy: true, want: "198.51.100.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+			r.RemoteAddr = "198.51.100.7:5555"
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := ClientIP(r, tt.trustProxy); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
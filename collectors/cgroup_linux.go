@@ -0,0 +1,173 @@
+//go:build linux
+
+package collectors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupUnlimited is the smallest value treated as "no limit" in cgroup v1,
+// which reports an unset memory limit as a page-aligned 2^63-1
+const cgroupUnlimited = 1 << 62
+
+// ProcessCgroup is the cgroup a process runs in and what that cgroup has
+// used and is allowed. Limits are 0 when unset. Counters the kernel does
+// not expose for the running cgroup version stay 0.
+type ProcessCgroup struct {
+	Version          int     `json:"version"` // 1 or 2
+	Path             string  `json:"path"`    // e.g. /system.slice/nginx.service
+	MemoryCurrent    uint64  `json:"memoryCurrent"`
+	MemoryMax        uint64  `json:"memoryMax"`
+	CPUUsageUsec     uint64  `json:"cpuUsageUsec"`
+	CPUThrottledUsec uint64  `json:"cpuThrottledUsec"`
+	CPUNrThrottled   uint64  `json:"cpuNrThrottled"`
+	CPUQuota         float64 `json:"cpuQuota"` // Cores, e.g. 0.5
+}
+
+// getProcessCgroup reads /proc/<pid>/cgroup and the accounting files of
+// the cgroup it names. It returns nil when the process has no cgroup
+// information.
+func getProcessCgroup(pid int) *ProcessCgroup {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil
+	}
+
+	// Lines are "hierarchy-id:controllers:path". cgroup v2 has a single
+	// "0::path" line; v1 (or hybrid) has one line per controller hierarchy.
+	unified := ""
+	v1 := make(map[string]string) // Controller to path
+	v1Dirs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			v1[controller] = parts[2]
+			v1Dirs[controller] = parts[1]
+		}
+	}
+
+	_, hasMemory := v1["memory"]
+	_, hasCPU := v1["cpu"]
+	switch {
+	case hasMemory || hasCPU:
+		return readCgroupV1(v1, v1Dirs)
+	case unified != "":
+		return readCgroupV2(unified)
+	}
+	return nil
+}
+
+// cgroupDir returns the directory of a cgroup under mount. Inside a
+// container without a cgroup namespace the path names a cgroup of the
+// host, while the mount already is the container's own cgroup.
+func cgroupDir(mount, path string) string {
+	dir := filepath.Join(mount, path)
+	if _, err := os.Stat(dir); err != nil {
+		return mount
+	}
+	return dir
+}
+
+func readCgroupV2(path string) *ProcessCgroup {
+	cg := &ProcessCgroup{Version: 2, Path: path}
+	dir := cgroupDir(cgroupRoot, path)
+
+	cg.MemoryCurrent = readCgroupUint(dir, "memory.current")
+	cg.MemoryMax = readCgroupUint(dir, "memory.max")
+
+	stat := readCgroupStat(dir, "cpu.stat")
+	cg.CPUUsageUsec = stat["usage_usec"]
+	cg.CPUThrottledUsec = stat["throttled_usec"]
+	cg.CPUNrThrottled = stat["nr_throttled"]
+
+	// cpu.max is "<quota> <period>", with "max" for no quota
+	if fields := strings.Fields(readSysfsString(dir, "cpu.max")); len(fields) == 2 {
+		quota, err1 := strconv.ParseFloat(fields[0], 64)
+		period, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 == nil && err2 == nil && period > 0 {
+			cg.CPUQuota = quota / period
+		}
+	}
+	return cg
+}
+
+func readCgroupV1(paths, dirs map[string]string) *ProcessCgroup {
+	cg := &ProcessCgroup{Version: 1, Path: paths["memory"]}
+	if cg.Path == "" {
+		cg.Path = paths["cpu"]
+	}
+
+	// Hierarchies are mounted under their controller list ("cpu,cpuacct"),
+	// usually with a symlink per controller
+	mount := func(controller string) string {
+		for _, name := range []string{dirs[controller], controller} {
+			dir := filepath.Join(cgroupRoot, name)
+			if _, err := os.Stat(dir); err == nil {
+				return cgroupDir(dir, paths[controller])
+			}
+		}
+		return ""
+	}
+
+	if dir := mount("memory"); dir != "" {
+		cg.MemoryCurrent = readCgroupUint(dir, "memory.usage_in_bytes")
+		cg.MemoryMax = readCgroupUint(dir, "memory.limit_in_bytes")
+	}
+	if dir := mount("cpuacct"); dir != "" {
+		cg.CPUUsageUsec = readCgroupUint(dir, "cpuacct.usage") / 1000
+	}
+	if dir := mount("cpu"); dir != "" {
+		stat := readCgroupStat(dir, "cpu.stat")
+		cg.CPUThrottledUsec = stat["throttled_time"] / 1000
+		cg.CPUNrThrottled = stat["nr_throttled"]
+
+		quota, err1 := strconv.ParseFloat(readSysfsString(dir, "cpu.cfs_quota_us"), 64)
+		period, err2 := strconv.ParseFloat(readSysfsString(dir, "cpu.cfs_period_us"), 64)
+		if err1 == nil && err2 == nil && quota > 0 && period > 0 {
+			cg.CPUQuota = quota / period
+		}
+	}
+	return cg
+}
+
+// readCgroupUint reads a single-number cgroup file; "max" and the v1
+// "unlimited" value read as 0
+func readCgroupUint(dir, name string) uint64 {
+	v, err := strconv.ParseUint(readSysfsString(dir, name), 10, 64)
+	if err != nil || v >= cgroupUnlimited {
+		return 0
+	}
+	return v
+}
+
+// readCgroupStat reads a "key value" per line file such as cpu.stat
+func readCgroupStat(dir, name string) map[string]uint64 {
+	stat := make(map[string]uint64)
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return stat
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			stat[fields[0]] = v
+		}
+	}
+	return stat
+}
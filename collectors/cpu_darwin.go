@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -33,29 +34,33 @@ type CPUInfo struct {
 	CoreTemps     []PhysicalCore `json:"coreTemps,omitempty"`
 	PackageTemp   float64        `json:"packageTemp,omitempty"`
 	Uptime        string         `json:"uptime"`
+	IOWaitPercent float64        `json:"iowaitPercent"` // Not available on this platform
 }
 
 func GetCPUInfo() (CPUInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	info := CPUInfo{}
 
 	// Get CPU model using sysctl
-	if out, err := exec.Command("sysctl", "-n", "machdep.cpu.brand_string").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "machdep.cpu.brand_string").Output(); err == nil {
 		info.Model = strings.TrimSpace(string(out))
 	}
 
 	// Get core count
-	if out, err := exec.Command("sysctl", "-n", "hw.physicalcpu").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.physicalcpu").Output(); err == nil {
 		info.PhysicalCores, _ = strconv.Atoi(strings.TrimSpace(string(out)))
 		info.Cores = info.PhysicalCores
 	}
 
 	// Get thread count
-	if out, err := exec.Command("sysctl", "-n", "hw.logicalcpu").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.logicalcpu").Output(); err == nil {
 		info.Threads, _ = strconv.Atoi(strings.TrimSpace(string(out)))
 	}
 
 	// Get load average
-	if out, err := exec.Command("sysctl", "-n", "vm.loadavg").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "vm.loadavg").Output(); err == nil {
 		parts := strings.Fields(strings.Trim(string(out), "{ }"))
 		for _, p := range parts {
 			if v, err := strconv.ParseFloat(p, 64); err == nil {
@@ -65,7 +70,7 @@ func GetCPUInfo() (CPUInfo, error) {
 	}
 
 	// Get CPU usage from top
-	if out, err := exec.Command("top", "-l", "1", "-n", "0", "-stats", "cpu").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "top", "-l", "1", "-n", "0", "-stats", "cpu").Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		for _, line := range lines {
 			if strings.Contains(line, "CPU usage") {
@@ -97,7 +102,7 @@ func GetCPUInfo() (CPUInfo, error) {
 	}
 
 	// Get uptime
-	if out, err := exec.Command("uptime").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "uptime").Output(); err == nil {
 		upStr := string(out)
 		if idx := strings.Index(upStr, "up "); idx != -1 {
 			end := strings.Index(upStr[idx:], ",")
@@ -119,3 +124,10 @@ func GetCPUInfo() (CPUInfo, error) {
 }
 
 var startTime = time.Now()
+
+// CheckReady confirms the host interfaces the collectors rely on can be read.
+// It only reads one sysctl, so it is cheap enough for a readiness probe.
+func CheckReady() error {
+	_, err := syscall.Sysctl("kern.boottime")
+	return err
+}
@@ -37,6 +37,7 @@ type CPUInfo struct {
 	CoreTemps     []PhysicalCore `json:"coreTemps,omitempty"` // Physical core temperatures
 	PackageTemp   float64        `json:"packageTemp,omitempty"`
 	Uptime        string         `json:"uptime"`
+	IOWaitPercent float64        `json:"iowaitPercent"`
 }
 
 type cpuTimes struct {
@@ -108,6 +109,7 @@ func GetCPUInfo() (*CPUInfo, error) {
 
 	scanner := bufio.NewScanner(stat)
 	coreID := -1 // -1 for total CPU
+	seenCores := map[int]bool{-1: true}
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -135,12 +137,20 @@ func GetCPUInfo() (*CPUInfo, error) {
 		if fields[0] == "cpu" {
 			// Total CPU
 			coreID = -1
+			cpuMutex.Lock()
+			prev, hasPrev := previousCPUTimes[coreID]
+			cpuMutex.Unlock()
+
 			usage := calculateCPUUsage(coreID, times)
 			info.UsagePercent = usage
+			if hasPrev {
+				info.IOWaitPercent = calculateIOWait(prev, times)
+			}
 		} else {
 			// Individual core
 			coreNum, _ := strconv.Atoi(strings.TrimPrefix(fields[0], "cpu"))
 			coreID = coreNum
+			seenCores[coreID] = true
 			usage := calculateCPUUsage(coreID, times)
 
 			core := CPUCore{
@@ -149,7 +159,9 @@ func GetCPUInfo() (*CPUInfo, error) {
 			}
 
 			// Get temperature for this core
-			core.Temperature = getCoreTemperature(coreNum)
+			if enabledFeatures().CPUTemps {
+				core.Temperature = getCoreTemperature(coreNum)
+			}
 
 			// Get frequency for this core
 			core.Frequency = getCoreFrequency(coreNum)
@@ -158,12 +170,24 @@ func GetCPUInfo() (*CPUInfo, error) {
 		}
 	}
 
+	// Drop samples of cores that went offline
+	cpuMutex.Lock()
+	for id := range previousCPUTimes {
+		if !seenCores[id] {
+			delete(previousCPUTimes, id)
+			delete(previousTotalTimes, id)
+		}
+	}
+	cpuMutex.Unlock()
+
 	info.Cores = len(info.CoreStats)
 	info.Threads = len(info.CoreStats)
 
 	// Get physical core temperatures
-	info.CoreTemps, info.PackageTemp = getPhysicalCoreTemperatures()
-	info.PhysicalCores = len(info.CoreTemps)
+	if enabledFeatures().CPUTemps {
+		info.CoreTemps, info.PackageTemp = getPhysicalCoreTemperatures()
+		info.PhysicalCores = len(info.CoreTemps)
+	}
 
 	return info, nil
 }
@@ -197,6 +221,18 @@ func calculateCPUUsage(coreID int, current cpuTimes) float64 {
 	return float64(totalDiff-idleDiff) / float64(totalDiff) * 100
 }
 
+// calculateIOWait returns the share of CPU time spent waiting on I/O between two samples
+func calculateIOWait(prev, current cpuTimes) float64 {
+	prevTotal := prev.user + prev.nice + prev.system + prev.idle + prev.iowait + prev.irq + prev.softirq + prev.steal
+	currTotal := current.user + current.nice + current.system + current.idle + current.iowait + current.irq + current.softirq + current.steal
+
+	if currTotal <= prevTotal || current.iowait < prev.iowait {
+		return 0
+	}
+
+	return float64(current.iowait-prev.iowait) / float64(currTotal-prevTotal) * 100
+}
+
 func getCoreTemperature(coreNum int) float64 {
 	// Try hwmon
 	hwmonPath := "/sys/class/hwmon"
@@ -338,12 +374,16 @@ func getPhysicalCoreTemperatures() ([]PhysicalCore, float64) {
 					continue
 				}
 
-				// Determine core type based on Intel 13th gen hybrid architecture
-				// P-cores: IDs 0, 4, 8, 12, 16, 20 (multiples of 4, up to 20)
-				// E-cores: IDs 24-31
-				coreType := "P"
-				if coreID >= 24 {
-					coreType = "E"
+				// Determine core type from the CPU topology when available
+				coreType, known := coreTypes()[coreID]
+				if !known {
+					// Fallback for Intel 13th gen hybrid architecture
+					// P-cores: IDs 0, 4, 8, 12, 16, 20 (multiples of 4, up to 20)
+					// E-cores: IDs 24-31
+					coreType = "P"
+					if coreID >= 24 {
+						coreType = "E"
+					}
 				}
 
 				cores = append(cores, PhysicalCore{
@@ -362,6 +402,110 @@ func getPhysicalCoreTemperatures() ([]PhysicalCore, float64) {
 	return cores, packageTemp
 }
 
+var (
+	coreTypeMap  map[int]string
+	coreTypeOnce sync.Once
+)
+
+// coreTypes maps physical core IDs (as used in coretemp "Core N" labels) to
+// "P" or "E". The topology doesn't change at runtime so it's read once.
+// Returns an empty map when the kernel doesn't expose core types.
+func coreTypes() map[int]string {
+	coreTypeOnce.Do(func() {
+		coreTypeMap = detectCoreTypes()
+	})
+	return coreTypeMap
+}
+
+func detectCoreTypes() map[int]string {
+	types := make(map[int]string)
+	cpuBase := "/sys/devices/system/cpu"
+
+	// Logical CPU -> type. Hybrid Intel CPUs register separate PMUs for
+	// P-cores (cpu_core) and E-cores (cpu_atom), each listing its CPUs.
+	logical := make(map[int]string)
+	if atom, err := os.ReadFile("/sys/devices/cpu_atom/cpus"); err == nil {
+		for _, cpu := range parseCPUList(string(atom)) {
+			logical[cpu] = "E"
+		}
+		if core, err := os.ReadFile("/sys/devices/cpu_core/cpus"); err == nil {
+			for _, cpu := range parseCPUList(string(core)) {
+				logical[cpu] = "P"
+			}
+		}
+	} else {
+		// Otherwise compare cpu_capacity: cores below the max are E-cores
+		capacities := make(map[int]int)
+		maxCapacity := 0
+		entries, _ := filepath.Glob(filepath.Join(cpuBase, "cpu[0-9]*", "cpu_capacity"))
+		for _, path := range entries {
+			cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(path)), "cpu"))
+			if err != nil {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			capacity, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err != nil {
+				continue
+			}
+			capacities[cpu] = capacity
+			if capacity > maxCapacity {
+				maxCapacity = capacity
+			}
+		}
+		for cpu, capacity := range capacities {
+			if capacity < maxCapacity {
+				logical[cpu] = "E"
+			} else {
+				logical[cpu] = "P"
+			}
+		}
+	}
+
+	// Logical CPU -> physical core ID
+	for cpu, coreType := range logical {
+		data, err := os.ReadFile(filepath.Join(cpuBase, fmt.Sprintf("cpu%d", cpu), "topology", "core_id"))
+		if err != nil {
+			continue
+		}
+		coreID, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			continue
+		}
+		types[coreID] = coreType
+	}
+
+	return types
+}
+
+// parseCPUList parses the kernel's CPU list format, e.g. "0-15,24,26-27"
+func parseCPUList(s string) []int {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
+
 // sortPhysicalCores sorts cores by ID (P-cores first, then E-cores)
 func sortPhysicalCores(cores []PhysicalCore) {
 	// Simple insertion sort (small slice)
@@ -375,3 +519,16 @@ func sortPhysicalCores(cores []PhysicalCore) {
 		cores[j+1] = key
 	}
 }
+
+// CheckReady confirms the host interfaces the collectors rely on can be read.
+// It only reads /proc/uptime, so it is cheap enough for a readiness probe.
+func CheckReady() error {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return err
+	}
+	if len(strings.Fields(string(data))) == 0 {
+		return fmt.Errorf("/proc/uptime is empty")
+	}
+	return nil
+}
@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -35,6 +36,7 @@ type CPUInfo struct {
 	CoreTemps     []PhysicalCore `json:"coreTemps,omitempty"`
 	PackageTemp   float64        `json:"packageTemp,omitempty"`
 	Uptime        string         `json:"uptime"`
+	IOWaitPercent float64        `json:"iowaitPercent"` // Not available on this platform
 }
 
 // runPowerShell runs a PowerShell snippet and returns its trimmed stdout. The
@@ -43,9 +45,18 @@ type CPUInfo struct {
 // prelude is prepended so accented characters survive the round-trip back to
 // the HTTP response (PowerShell otherwise emits the current OEM code page).
 func runPowerShell(script string) (string, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	return runPowerShellContext(ctx, script)
+}
+
+// runPowerShellContext is runPowerShell bounded by ctx instead of the
+// default command timeout
+func runPowerShellContext(ctx context.Context, script string) (string, error) {
 	const utf8Prelude = "[Console]::OutputEncoding = [System.Text.UTF8Encoding]::new(); $OutputEncoding = [System.Text.UTF8Encoding]::new(); "
 	encoded := encodePowerShellCommand(utf8Prelude + script)
-	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-EncodedCommand", encoded)
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-EncodedCommand", encoded)
 	out, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -138,3 +149,10 @@ func GetCPUInfo() (CPUInfo, error) {
 
 	return info, nil
 }
+
+// CheckReady confirms the host interfaces the collectors rely on can be read.
+// It only asks for the boot time, so it is cheap enough for a readiness probe.
+func CheckReady() error {
+	_, err := gpshost.BootTime()
+	return err
+}
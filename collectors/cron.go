@@ -0,0 +1,321 @@
+package collectors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CronJob struct {
+	User      string `json:"user"`
+	Schedule  string `json:"schedule"`
+	Command   string `json:"command"`
+	Source    string `json:"source"`              // File the entry was read from
+	NextRun   string `json:"nextRun,omitempty"`   // RFC 3339, empty for @reboot or unparseable schedules
+	NextRunIn string `json:"nextRunIn,omitempty"` // Human-readable, e.g. "in 3h 20m"
+}
+
+type CronInfo struct {
+	Available bool      `json:"available"`
+	Jobs      []CronJob `json:"jobs"`
+	// Crontab files skipped because they couldn't be read (usually needs root)
+	Unreadable []string `json:"unreadable,omitempty"`
+}
+
+// cronSources describes where crontabs live on a platform. System files
+// carry a user column; per-user spool files are named after their owner.
+type cronSources struct {
+	systemFiles []string // Files or globs
+	userDirs    []string
+}
+
+func collectCronJobs(src cronSources) CronInfo {
+	info := CronInfo{Jobs: []CronJob{}}
+	now := time.Now()
+
+	var systemFiles []string
+	for _, pattern := range src.systemFiles {
+		matches, _ := filepath.Glob(pattern)
+		sort.Strings(matches)
+		systemFiles = append(systemFiles, matches...)
+	}
+
+	for _, path := range systemFiles {
+		info.Available = true
+		jobs, err := parseCrontabFile(path, "", now)
+		if err != nil {
+			info.Unreadable = append(info.Unreadable, path)
+			continue
+		}
+		info.Jobs = append(info.Jobs, jobs...)
+	}
+
+	for _, dir := range src.userDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsPermission(err) {
+				info.Available = true
+				info.Unreadable = append(info.Unreadable, dir)
+			}
+			continue
+		}
+		info.Available = true
+
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			jobs, err := parseCrontabFile(path, entry.Name(), now)
+			if err != nil {
+				info.Unreadable = append(info.Unreadable, path)
+				continue
+			}
+			info.Jobs = append(info.Jobs, jobs...)
+		}
+	}
+
+	return info
+}
+
+// parseCrontabFile reads one crontab. An empty owner means a system
+// crontab, where the sixth field names the user.
+func parseCrontabFile(path, owner string, now time.Time) ([]CronJob, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	jobs := []CronJob{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		// Environment assignments such as SHELL=/bin/sh or MAILTO=""
+		if eq := strings.Index(fields[0], "="); eq > 0 && !strings.HasPrefix(fields[0], "@") {
+			continue
+		}
+
+		scheduleFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		}
+
+		userFields := 0
+		if owner == "" {
+			userFields = 1
+		}
+		if len(fields) < scheduleFields+userFields+1 {
+			continue
+		}
+
+		job := CronJob{
+			User:     owner,
+			Schedule: strings.Join(fields[:scheduleFields], " "),
+			Command:  strings.Join(fields[scheduleFields+userFields:], " "),
+			Source:   path,
+		}
+		if owner == "" {
+			job.User = fields[scheduleFields]
+		}
+
+		if sched, err := parseCronSchedule(job.Schedule); err == nil {
+			if next, ok := sched.next(now); ok {
+				job.NextRun = next.Format(time.RFC3339)
+				job.NextRunIn = "in " + formatCronDuration(next.Sub(now))
+			}
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, scanner.Err()
+}
+
+// cronSchedule holds the allowed values of each field as bitsets
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+	reboot                        bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+var cronDayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	if spec == "@reboot" {
+		return &cronSchedule{reboot: true}, nil
+	}
+	if expanded, ok := cronMacros[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	// Like cron, any field starting with "*" counts as unrestricted for the
+	// day-of-month/day-of-week rule, including steps such as "*/2"
+	s := &cronSchedule{
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, err
+	}
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField parses lists of values, ranges and steps ("1,5-10,*/15").
+// names, when given, map to values starting at 1 for months, 0 for days.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if slash := strings.Index(part, "/"); slash != -1 {
+			n, err := strconv.Atoi(part[slash+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+			part = part[:slash]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = cronValue(bounds[0], min, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = cronValue(bounds[1], min, names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				// "5/15" means from 5 to the end in steps of 15
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value out of range in %q", field)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func cronValue(s string, min int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return i + min, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// next finds the first matching minute after from, looking up to five years
+// ahead (enough for Feb 29 schedules)
+func (s *cronSchedule) next(from time.Time) (time.Time, bool) {
+	if s.reboot {
+		return time.Time{}, false
+	}
+
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 || !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+// dayMatches applies cron's rule that when both day-of-month and
+// day-of-week are restricted, matching either one is enough
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func formatCronDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build darwin
+
+package collectors
+
+// GetCronJobs aggregates system and per-user crontabs. launchd jobs are
+// listed with the services instead.
+func GetCronJobs() (CronInfo, error) {
+	return collectCronJobs(cronSources{
+		systemFiles: []string{"/etc/crontab"},
+		userDirs:    []string{"/usr/lib/cron/tabs"},
+	}), nil
+}
@@ -0,0 +1,12 @@
+//go:build linux
+
+package collectors
+
+// GetCronJobs aggregates system and per-user crontabs
+func GetCronJobs() (CronInfo, error) {
+	return collectCronJobs(cronSources{
+		systemFiles: []string{"/etc/crontab", "/etc/cron.d/*"},
+		// Debian/Ubuntu, then RHEL/Fedora/Arch
+		userDirs: []string{"/var/spool/cron/crontabs", "/var/spool/cron"},
+	}), nil
+}
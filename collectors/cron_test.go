@@ -0,0 +1,47 @@
+package collectors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// A Monday
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want string // RFC 3339, empty when there is no next run
+	}{
+		{"30 12 * * *", "2024-01-01T12:30:00Z"},
+		{"0 0 13 * *", "2024-01-13T00:00:00Z"},
+		{"0 0 * * 1", "2024-01-08T00:00:00Z"},
+		{"@weekly", "2024-01-07T00:00:00Z"},
+		// Both days restricted: either one matching is enough
+		{"0 0 1 * 1", "2024-01-08T00:00:00Z"},
+		{"0 0 20 * fri", "2024-01-05T00:00:00Z"},
+		// A "*/n" day field is a star to cron, so both must match:
+		// odd days that are Mondays, and the 1st on an even weekday
+		{"0 0 */2 * 1", "2024-01-15T00:00:00Z"},
+		{"0 0 1 * */2", "2024-02-01T00:00:00Z"},
+		// A stepped range is restricted, so either matches
+		{"0 0 1-31/2 * 1", "2024-01-03T00:00:00Z"},
+		{"@reboot", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			sched, err := parseCronSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next, ok := sched.next(from)
+			got := ""
+			if ok {
+				got = next.Format(time.RFC3339)
+			}
+			if got != tt.want {
+				t.Errorf("next run %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
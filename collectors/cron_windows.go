@@ -0,0 +1,8 @@
+//go:build windows
+
+package collectors
+
+// GetCronJobs reports cron as unavailable; Windows uses Task Scheduler
+func GetCronJobs() (CronInfo, error) {
+	return CronInfo{Available: false, Jobs: []CronJob{}}, nil
+}
@@ -0,0 +1,28 @@
+package collectors
+
+import "sync"
+
+// DiskFilter selects which mounts besides local block devices GetDiskInfo
+// reports. Both are off by default so the dashboard only lists real disks.
+type DiskFilter struct {
+	Network bool // nfs, cifs/smb, sshfs, ceph...
+	Virtual bool // zfs, overlay, aufs and other fuse filesystems
+}
+
+var (
+	diskFilter   DiskFilter
+	diskFilterMu sync.RWMutex
+)
+
+// SetDiskFilter sets the mounts GetDiskInfo includes
+func SetDiskFilter(f DiskFilter) {
+	diskFilterMu.Lock()
+	diskFilter = f
+	diskFilterMu.Unlock()
+}
+
+func currentDiskFilter() DiskFilter {
+	diskFilterMu.RLock()
+	defer diskFilterMu.RUnlock()
+	return diskFilter
+}
@@ -6,16 +6,23 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"syscall"
 )
 
 type Partition struct {
-	Device      string  `json:"device"`
-	MountPoint  string  `json:"mountPoint"`
-	FSType      string  `json:"fsType"`
-	Total       uint64  `json:"total"`
-	Used        uint64  `json:"used"`
-	Free        uint64  `json:"free"`
-	UsedPercent float64 `json:"usedPercent"`
+	Device            string   `json:"device"`
+	MountPoint        string   `json:"mountPoint"`
+	FSType            string   `json:"fsType"`
+	Total             uint64   `json:"total"`
+	Used              uint64   `json:"used"`
+	Free              uint64   `json:"free"`
+	UsedPercent       float64  `json:"usedPercent"`
+	InodesTotal       uint64   `json:"inodesTotal"`
+	InodesUsed        uint64   `json:"inodesUsed"`
+	InodesFree        uint64   `json:"inodesFree"`
+	InodesUsedPercent float64  `json:"inodesUsedPercent"`
+	MountOptions      []string `json:"mountOptions"`
+	ReadOnly          bool     `json:"readOnly"`
 }
 
 type DiskIO struct {
@@ -31,11 +38,24 @@ type DiskInfo struct {
 	IO         []DiskIO    `json:"io,omitempty"`
 }
 
+// GetDiskInfo reports the mounts selected by SetDiskFilter
 func GetDiskInfo() (DiskInfo, error) {
+	return GetDiskInfoFiltered(currentDiskFilter())
+}
+
+// darwinNetworkFSTypes are the remote filesystems DiskFilter.Network includes
+var darwinNetworkFSTypes = map[string]bool{"nfs": true, "smbfs": true, "afpfs": true, "webdav": true}
+
+// GetDiskInfoFiltered reports local disks, plus network and fuse mounts as
+// filter selects
+func GetDiskInfoFiltered(filter DiskFilter) (DiskInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	info := DiskInfo{}
 
 	// Get disk usage using df
-	out, err := exec.Command("df", "-k").Output()
+	out, err := exec.CommandContext(ctx, "df", "-k").Output()
 	if err != nil {
 		return info, err
 	}
@@ -51,9 +71,18 @@ func GetDiskInfo() (DiskInfo, error) {
 			continue
 		}
 
-		// Skip pseudo filesystems
+		// Skip pseudo filesystems unless asked for
 		if !strings.HasPrefix(fields[0], "/dev") {
-			continue
+			var stat syscall.Statfs_t
+			if err := syscall.Statfs(fields[len(fields)-1], &stat); err != nil {
+				continue
+			}
+			fsType := fsTypeName(stat.Fstypename)
+			isNetwork := darwinNetworkFSTypes[fsType]
+			isFuse := strings.Contains(fsType, "fuse")
+			if !(filter.Network && isNetwork) && !(filter.Virtual && isFuse) {
+				continue
+			}
 		}
 
 		total, _ := strconv.ParseUint(fields[1], 10, 64)
@@ -70,7 +99,7 @@ func GetDiskInfo() (DiskInfo, error) {
 			usedPercent = float64(used) / float64(total) * 100
 		}
 
-		info.Partitions = append(info.Partitions, Partition{
+		partition := Partition{
 			Device:      fields[0],
 			MountPoint:  fields[len(fields)-1],
 			FSType:      "apfs", // Most modern macOS uses APFS
@@ -78,8 +107,78 @@ func GetDiskInfo() (DiskInfo, error) {
 			Used:        used,
 			Free:        free,
 			UsedPercent: usedPercent,
-		})
+		}
+
+		// Inode usage and mount flags via statfs
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(partition.MountPoint, &stat); err == nil {
+			if fsType := fsTypeName(stat.Fstypename); fsType != "" {
+				partition.FSType = fsType
+			}
+			partition.MountOptions = darwinMountOptions(stat.Flags)
+			partition.ReadOnly = stat.Flags&mntReadOnly != 0
+			partition.InodesTotal = stat.Files
+			partition.InodesFree = stat.Ffree
+			partition.InodesUsed = stat.Files - stat.Ffree
+			if partition.InodesTotal > 0 {
+				partition.InodesUsedPercent = float64(partition.InodesUsed) / float64(partition.InodesTotal) * 100
+			}
+		}
+
+		info.Partitions = append(info.Partitions, partition)
 	}
 
 	return info, nil
 }
+
+// fsTypeName converts statfs's NUL-terminated f_fstypename
+func fsTypeName(name [16]int8) string {
+	b := make([]byte, 0, len(name))
+	for _, c := range name {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
+
+const mntReadOnly = 0x00000001 // MNT_RDONLY
+
+// darwinMountFlags are the MNT_* flags from <sys/mount.h> that mount(8)
+// prints, in the same order
+var darwinMountFlags = []struct {
+	flag uint32
+	name string
+}{
+	{0x00000001, "read-only"},
+	{0x00000002, "synchronous"},
+	{0x00000004, "noexec"},
+	{0x00000008, "nosuid"},
+	{0x00000010, "nodev"},
+	{0x00000020, "union"},
+	{0x00000040, "asynchronous"},
+	{0x00000080, "protect"},
+	{0x00001000, "local"},
+	{0x00004000, "quarantine"},
+	{0x00008000, "root file system"},
+	{0x00100000, "dovolfs"},
+	{0x00200000, "dontbrowse"},
+	{0x00400000, "ignore-ownership"},
+	{0x00800000, "journaled"},
+	{0x04000000, "defwrite"},
+	{0x08000000, "multilabel"},
+	{0x10000000, "noatime"},
+	{0x40000000, "sealed"},
+}
+
+// darwinMountOptions turns statfs flags into option names
+func darwinMountOptions(flags uint32) []string {
+	options := []string{}
+	for _, f := range darwinMountFlags {
+		if flags&f.flag != 0 {
+			options = append(options, f.name)
+		}
+	}
+	return options
+}
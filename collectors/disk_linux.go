@@ -5,6 +5,8 @@ package collectors
 import (
 	"bufio"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,13 +14,19 @@ import (
 )
 
 type DiskPartition struct {
-	Device      string  `json:"device"`
-	MountPoint  string  `json:"mountPoint"`
-	FSType      string  `json:"fsType"`
-	Total       uint64  `json:"total"`
-	Used        uint64  `json:"used"`
-	Free        uint64  `json:"free"`
-	UsedPercent float64 `json:"usedPercent"`
+	Device            string   `json:"device"`
+	MountPoint        string   `json:"mountPoint"`
+	FSType            string   `json:"fsType"`
+	Total             uint64   `json:"total"`
+	Used              uint64   `json:"used"`
+	Free              uint64   `json:"free"`
+	UsedPercent       float64  `json:"usedPercent"`
+	InodesTotal       uint64   `json:"inodesTotal"`
+	InodesUsed        uint64   `json:"inodesUsed"`
+	InodesFree        uint64   `json:"inodesFree"`
+	InodesUsedPercent float64  `json:"inodesUsedPercent"`
+	MountOptions      []string `json:"mountOptions"`
+	ReadOnly          bool     `json:"readOnly"`
 }
 
 type DiskIO struct {
@@ -27,6 +35,9 @@ type DiskIO struct {
 	WriteBytes uint64 `json:"writeBytes"`
 	ReadSpeed  uint64 `json:"readSpeed"`
 	WriteSpeed uint64 `json:"writeSpeed"`
+	// Temperature in °C, from hwmon (nvme, drivetemp) or else from SMART
+	Temperature float64    `json:"temperature,omitempty"`
+	Smart       *SmartInfo `json:"smart,omitempty"`
 }
 
 type DiskInfo struct {
@@ -41,7 +52,25 @@ func init() {
 	previousDiskIO = make(map[string]DiskIO)
 }
 
+// networkFSTypes are the remote filesystems DiskFilter.Network includes
+var networkFSTypes = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "smbfs": true,
+	"ceph": true, "glusterfs": true, "9p": true,
+	"fuse.sshfs": true, "fuse.glusterfs": true, "fuse.rclone": true, "fuse.s3fs": true,
+}
+
+// virtualFSTypes are the pooled and layered filesystems DiskFilter.Virtual
+// includes, besides any other fuse filesystem
+var virtualFSTypes = map[string]bool{"zfs": true, "overlay": true, "aufs": true}
+
+// GetDiskInfo reports the mounts selected by SetDiskFilter
 func GetDiskInfo() (*DiskInfo, error) {
+	return GetDiskInfoFiltered(currentDiskFilter())
+}
+
+// GetDiskInfoFiltered reports local block devices, plus network and virtual
+// mounts as filter selects
+func GetDiskInfoFiltered(filter DiskFilter) (*DiskInfo, error) {
 	info := &DiskInfo{
 		Partitions: []DiskPartition{},
 		IO:         []DiskIO{},
@@ -68,26 +97,40 @@ func GetDiskInfo() (*DiskInfo, error) {
 		mountPoint := fields[1]
 		fsType := fields[2]
 
-		// Skip non-physical filesystems
+		// Skip non-physical filesystems unless asked for
+		dedupKey := device
 		if !strings.HasPrefix(device, "/dev/") {
+			switch {
+			case filter.Network && networkFSTypes[fsType]:
+			case filter.Virtual && (virtualFSTypes[fsType] || strings.HasPrefix(fsType, "fuse.")) && !networkFSTypes[fsType]:
+				if fsType == "overlay" || fsType == "aufs" {
+					// Every container's root is an overlay named "overlay"
+					dedupKey = mountPoint
+				}
+			default:
+				continue
+			}
+		} else if strings.Contains(device, "loop") || strings.Contains(mountPoint, "/snap/") {
+			// Skip snap and loop devices
 			continue
 		}
 
-		// Skip snap and loop devices
-		if strings.Contains(device, "loop") || strings.Contains(mountPoint, "/snap/") {
-			continue
-		}
-
-		// Skip duplicates
-		if seenDevices[device] {
+		// Skip duplicates, such as bind mounts of the same device
+		if seenDevices[dedupKey] {
 			continue
 		}
-		seenDevices[device] = true
+		seenDevices[dedupKey] = true
 
 		partition := DiskPartition{
-			Device:     device,
-			MountPoint: mountPoint,
-			FSType:     fsType,
+			Device:       device,
+			MountPoint:   mountPoint,
+			FSType:       fsType,
+			MountOptions: strings.Split(fields[3], ","),
+		}
+		for _, opt := range partition.MountOptions {
+			if opt == "ro" {
+				partition.ReadOnly = true
+			}
 		}
 
 		// Get disk usage using statfs
@@ -100,6 +143,14 @@ func GetDiskInfo() (*DiskInfo, error) {
 			if partition.Total > 0 {
 				partition.UsedPercent = float64(partition.Used) / float64(partition.Total) * 100
 			}
+
+			// Inode usage (some filesystems like btrfs report 0 total)
+			partition.InodesTotal = stat.Files
+			partition.InodesFree = stat.Ffree
+			partition.InodesUsed = stat.Files - stat.Ffree
+			if partition.InodesTotal > 0 {
+				partition.InodesUsedPercent = float64(partition.InodesUsed) / float64(partition.InodesTotal) * 100
+			}
 		}
 
 		info.Partitions = append(info.Partitions, partition)
@@ -110,6 +161,9 @@ func GetDiskInfo() (*DiskInfo, error) {
 	if err == nil {
 		defer diskstats.Close()
 
+		// Devices seen in this pass; others were removed (USB drives etc.)
+		seenIO := make(map[string]bool)
+
 		scanner := bufio.NewScanner(diskstats)
 		for scanner.Scan() {
 			fields := strings.Fields(scanner.Text())
@@ -138,6 +192,8 @@ func GetDiskInfo() (*DiskInfo, error) {
 				continue
 			}
 
+			seenIO[device] = true
+
 			readSectors, _ := strconv.ParseUint(fields[5], 10, 64)
 			writeSectors, _ := strconv.ParseUint(fields[9], 10, 64)
 
@@ -165,9 +221,53 @@ func GetDiskInfo() (*DiskInfo, error) {
 			}
 			diskMutex.Unlock()
 
+			// Cached SMART data; smartctl runs in the background, never here
+			io.Smart = getCachedDiskSmart("/dev/" + device)
+			io.Temperature = getDiskTemperature(device)
+			if io.Temperature == 0 && io.Smart != nil {
+				io.Temperature = io.Smart.Temperature
+			}
+
 			info.IO = append(info.IO, io)
 		}
+
+		diskMutex.Lock()
+		for device := range previousDiskIO {
+			if !seenIO[device] {
+				delete(previousDiskIO, device)
+			}
+		}
+		diskMutex.Unlock()
 	}
 
 	return info, nil
 }
+
+// nvmeNamespaceRegex extracts the controller from a namespace, e.g. nvme0
+// from nvme0n1
+var nvmeNamespaceRegex = regexp.MustCompile(`^(nvme\d+)n\d+$`)
+
+// getDiskTemperature reads a drive's temperature from hwmon, or 0 when it
+// has no sensor there. NVMe controllers register an "nvme" chip, under the
+// controller on newer kernels and under its PCI device on older ones; SATA
+// drives have one when the drivetemp module is loaded.
+func getDiskTemperature(device string) float64 {
+	dirs := []string{filepath.Join("/sys/block", device, "device")}
+	if m := nvmeNamespaceRegex.FindStringSubmatch(device); m != nil {
+		ctrl := filepath.Join("/sys/class/nvme", m[1])
+		dirs = append(dirs, ctrl, filepath.Join(ctrl, "device"))
+	}
+
+	for _, dir := range dirs {
+		for _, pattern := range []string{"hwmon*", "hwmon/hwmon*"} {
+			matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+			for _, hwmon := range matches {
+				// temp1 is the drive's composite temperature
+				if temp := readSysfsFloat(hwmon, "temp1_input"); temp > 0 {
+					return temp / 1000
+				}
+			}
+		}
+	}
+	return 0
+}
@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -17,6 +18,13 @@ type Partition struct {
 	Used        uint64  `json:"used"`
 	Free        uint64  `json:"free"`
 	UsedPercent float64 `json:"usedPercent"`
+	// Inode counts don't apply to NTFS; kept for a consistent shape
+	InodesTotal       uint64   `json:"inodesTotal"`
+	InodesUsed        uint64   `json:"inodesUsed"`
+	InodesFree        uint64   `json:"inodesFree"`
+	InodesUsedPercent float64  `json:"inodesUsedPercent"`
+	MountOptions      []string `json:"mountOptions"` // "rw" or "ro", plus "compress"
+	ReadOnly          bool     `json:"readOnly"`
 }
 
 type DiskIO struct {
@@ -43,7 +51,15 @@ var (
 	prevDiskIOMu sync.Mutex
 )
 
+// GetDiskInfo reports every drive with a filesystem
 func GetDiskInfo() (DiskInfo, error) {
+	return GetDiskInfoFiltered(currentDiskFilter())
+}
+
+// GetDiskInfoFiltered is GetDiskInfo: mapped network drives were always
+// listed on Windows and there are no virtual mounts to add, so the filter
+// changes nothing
+func GetDiskInfoFiltered(filter DiskFilter) (DiskInfo, error) {
 	info := DiskInfo{}
 
 	parts, err := gpsdisk.Partitions(false)
@@ -57,13 +73,15 @@ func GetDiskInfo() (DiskInfo, error) {
 			continue
 		}
 		info.Partitions = append(info.Partitions, Partition{
-			Device:      p.Device,
-			MountPoint:  p.Mountpoint,
-			FSType:      p.Fstype,
-			Total:       usage.Total,
-			Used:        usage.Used,
-			Free:        usage.Free,
-			UsedPercent: usage.UsedPercent,
+			Device:       p.Device,
+			MountPoint:   p.Mountpoint,
+			FSType:       p.Fstype,
+			Total:        usage.Total,
+			Used:         usage.Used,
+			Free:         usage.Free,
+			UsedPercent:  usage.UsedPercent,
+			MountOptions: p.Opts,
+			ReadOnly:     slices.Contains(p.Opts, "ro"),
 		})
 	}
 
@@ -0,0 +1,120 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MaxDiskUsageDepth bounds how many levels of children GetDiskUsage returns
+const MaxDiskUsageDepth = 4
+
+// DiskUsageEntry is a file or directory and the total size of everything
+// under it. Children are only listed down to the requested depth.
+type DiskUsageEntry struct {
+	Name     string           `json:"name"`
+	Path     string           `json:"path"`
+	Size     uint64           `json:"size"` // Bytes allocated on disk, like du
+	IsDir    bool             `json:"isDir"`
+	Children []DiskUsageEntry `json:"children,omitempty"`
+}
+
+type DiskUsage struct {
+	DiskUsageEntry
+	Skipped int  `json:"skipped"`           // Unreadable directories left out of the totals
+	Partial bool `json:"partial,omitempty"` // Deadline hit, sizes are lower bounds
+}
+
+// diskUsageScan is the state shared by one GetDiskUsage walk
+type diskUsageScan struct {
+	ctx     context.Context
+	device  uint64
+	skipped int
+	linked  map[[2]uint64]bool // Hard-linked files already counted
+}
+
+// GetDiskUsage totals the sizes under path, du-style, listing its children
+// sorted by size down to depth levels. The walk stays on path's filesystem
+// and never follows symlinks, so it can't loop. If ctx ends first the sizes
+// found so far are returned with Partial set.
+func GetDiskUsage(ctx context.Context, path string, depth int) (DiskUsage, error) {
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		return DiskUsage{}, fmt.Errorf("path must be absolute")
+	}
+	depth = max(1, min(depth, MaxDiskUsageDepth))
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return DiskUsage{}, err
+	}
+	if !info.IsDir() {
+		return DiskUsage{}, fmt.Errorf("%s is not a directory", path)
+	}
+
+	scan := &diskUsageScan{ctx: ctx, linked: make(map[[2]uint64]bool)}
+	scan.device, _ = fileDevice(info)
+
+	usage := DiskUsage{DiskUsageEntry: scan.walk(path, info, depth)}
+	usage.Skipped = scan.skipped
+	usage.Partial = ctx.Err() != nil
+	return usage, nil
+}
+
+// walk sizes one entry, listing its children while depth > 0
+func (s *diskUsageScan) walk(path string, info os.FileInfo, depth int) DiskUsageEntry {
+	entry := DiskUsageEntry{
+		Name:  info.Name(),
+		Path:  path,
+		Size:  allocatedSize(info),
+		IsDir: info.IsDir(),
+	}
+	if !entry.IsDir {
+		// Count each hard-linked file once, like du
+		if id, ok := hardLinkID(info); ok {
+			if s.linked[id] {
+				entry.Size = 0
+			}
+			s.linked[id] = true
+		}
+		return entry
+	}
+	if s.ctx.Err() != nil {
+		return entry
+	}
+
+	// Mount points of other filesystems count as empty, like du -x
+	if dev, ok := fileDevice(info); ok && dev != s.device {
+		return entry
+	}
+
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		s.skipped++
+		return entry
+	}
+
+	for _, d := range dirEntries {
+		if s.ctx.Err() != nil {
+			break
+		}
+		childInfo, err := d.Info() // Lstat: symlinks are sized, not followed
+		if err != nil {
+			continue
+		}
+		child := s.walk(filepath.Join(path, d.Name()), childInfo, depth-1)
+		entry.Size += child.Size
+		if depth > 0 {
+			entry.Children = append(entry.Children, child)
+		}
+	}
+
+	if depth > 0 {
+		sort.Slice(entry.Children, func(i, j int) bool {
+			return entry.Children[i].Size > entry.Children[j].Size
+		})
+	}
+	return entry
+}
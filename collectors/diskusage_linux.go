@@ -0,0 +1,35 @@
+//go:build linux
+
+package collectors
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileDevice returns the ID of the filesystem holding info
+func fileDevice(info os.FileInfo) (uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// allocatedSize is the space info takes on disk, which is less than its
+// length for sparse files
+func allocatedSize(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Blocks) * 512
+	}
+	return uint64(info.Size())
+}
+
+// hardLinkID identifies a file with more than one hard link
+func hardLinkID(info os.FileInfo) ([2]uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st.Nlink < 2 {
+		return [2]uint64{}, false
+	}
+	return [2]uint64{uint64(st.Dev), uint64(st.Ino)}, true
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package collectors
+
+import "os"
+
+// fileDevice reports no filesystem ID: mounted folders are rare on
+// Windows and the walk doesn't try to detect them
+func fileDevice(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// allocatedSize is the file length; compressed and sparse files may take
+// less space on disk
+func allocatedSize(info os.FileInfo) uint64 {
+	return uint64(info.Size())
+}
+
+// hardLinkID never matches: hard links are uncommon on NTFS and their
+// file IDs aren't in os.FileInfo
+func hardLinkID(info os.FileInfo) ([2]uint64, bool) {
+	return [2]uint64{}, false
+}
@@ -1,13 +1,18 @@
 package collectors
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -83,11 +88,51 @@ type Container struct {
 	Networks       []ContainerNetwork `json:"networks,omitempty"`
 	RestartPolicy  *RestartPolicy     `json:"restartPolicy,omitempty"`
 	ResourceLimits *ResourceLimits    `json:"resourceLimits,omitempty"`
+	RestartCount   int                `json:"restartCount,omitempty"`
+	OOMKilled      bool               `json:"oomKilled,omitempty"` // Last exit was the OOM killer
 }
 
 type DockerInfo struct {
 	Available  bool        `json:"available"`
 	Containers []Container `json:"containers"`
+	Total      int         `json:"total"` // Containers before filtering
+}
+
+// ContainerFilter narrows the container list. Empty fields match
+// everything.
+type ContainerFilter struct {
+	Status string // State, e.g. "running"
+	Image  string // Substring of the image
+	Label  string // "key" or "key=value"
+	Query  string // Case-insensitive substring of the name
+}
+
+// Match reports whether c passes every set field of f
+func (f ContainerFilter) Match(c Container) bool {
+	if f.Status != "" && !strings.EqualFold(c.State, f.Status) {
+		return false
+	}
+	if f.Image != "" && !strings.Contains(c.Image, f.Image) {
+		return false
+	}
+	if f.Label != "" && !labelMatches(c.Labels, f.Label) {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(c.Name), strings.ToLower(f.Query)) {
+		return false
+	}
+	return true
+}
+
+// FilterContainers returns the containers matching f
+func FilterContainers(containers []Container, f ContainerFilter) []Container {
+	result := []Container{}
+	for _, c := range containers {
+		if f.Match(c) {
+			result = append(result, c)
+		}
+	}
+	return result
 }
 
 var dockerAvailable *bool
@@ -117,7 +162,7 @@ func checkDockerAvailable() bool {
 	}
 
 	// Try to run docker ps to verify it works
-	ctx, cancel := contextWithTimeout(2 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "docker", "ps", "-q")
@@ -127,6 +172,11 @@ func checkDockerAvailable() bool {
 	return result
 }
 
+// IsDockerAvailable reports whether the docker CLI is installed and usable
+func IsDockerAvailable() bool {
+	return checkDockerAvailable()
+}
+
 func GetDockerInfo() DockerInfo {
 	if !checkDockerAvailable() {
 		return DockerInfo{Available: false}
@@ -137,11 +187,12 @@ func GetDockerInfo() DockerInfo {
 	return DockerInfo{
 		Available:  true,
 		Containers: containers,
+		Total:      len(containers),
 	}
 }
 
 func getContainerList() []Container {
-	ctx, cancel := contextWithTimeout(5 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
 	defer cancel()
 
 	// Get all containers (including stopped) with JSON format
@@ -168,6 +219,7 @@ func getContainerList() []Container {
 			State   string `json:"State"`
 			Status  string `json:"Status"`
 			Ports   string `json:"Ports"`
+			Labels  string `json:"Labels"`
 		}
 
 		if err := json.Unmarshal([]byte(line), &raw); err != nil {
@@ -184,18 +236,34 @@ func getContainerList() []Container {
 			Status:   raw.Status,
 			ExitCode: parseExitCode(raw.Status),
 			Ports:    raw.Ports,
+			Labels:   parseLabelList(raw.Labels),
 		})
 	}
 
 	return containers
 }
 
+// parseLabelList parses docker ps' "k=v,k2=v2" labels column
+func parseLabelList(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	labels := make(map[string]string)
+	for _, label := range strings.Split(s, ",") {
+		key, value, _ := splitKeyValue(label)
+		if key != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
 func GetContainerDetail(containerID string) (*Container, error) {
 	if !checkDockerAvailable() {
 		return nil, fmt.Errorf("docker not available")
 	}
 
-	ctx, cancel := contextWithTimeout(5 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
 	defer cancel()
 
 	// Get detailed container info using docker inspect
@@ -219,14 +287,17 @@ func GetContainerDetail(containerID string) (*Container, error) {
 					Output string `json:"Output"`
 				} `json:"Log"`
 			} `json:"Health"`
+			OOMKilled bool `json:"OOMKilled"`
 		} `json:"State"`
-		Config struct {
+		RestartCount int `json:"RestartCount"`
+		Config       struct {
 			Image  string            `json:"Image"`
 			Cmd    []string          `json:"Cmd"`
 			Env    []string          `json:"Env"`
 			Labels map[string]string `json:"Labels"`
 		} `json:"Config"`
 		HostConfig struct {
+			NetworkMode  string `json:"NetworkMode"`
 			PortBindings map[string][]struct {
 				HostIp   string `json:"HostIp"`
 				HostPort string `json:"HostPort"`
@@ -363,7 +434,7 @@ func GetContainerDetail(containerID string) (*Container, error) {
 	var memUsage, memLimit, netRx, netTx uint64
 	var pids int
 
-	if data.State.Status == "running" {
+	if data.State.Status == "running" && enabledFeatures().DockerStats {
 		stats := getContainerStats(containerID)
 		if stats != nil {
 			cpuPercent = stats.CPUPercent
@@ -373,6 +444,15 @@ func GetContainerDetail(containerID string) (*Container, error) {
 			netTx = stats.NetworkTx
 			pids = stats.PIDs
 		}
+		// Some engines report NetIO as "--"; count the traffic of the
+		// container's network namespace instead. Host and none networking
+		// have no namespace of their own to count.
+		if stats != nil && !stats.NetworkKnown && data.State.Pid > 0 &&
+			data.HostConfig.NetworkMode != "host" && data.HostConfig.NetworkMode != "none" {
+			if rx, tx, ok := readContainerNetDev(data.State.Pid); ok {
+				netRx, netTx = rx, tx
+			}
+		}
 	}
 
 	container := &Container{
@@ -397,6 +477,8 @@ func GetContainerDetail(containerID string) (*Container, error) {
 		Networks:       networks,
 		RestartPolicy:  restartPolicy,
 		ResourceLimits: resourceLimits,
+		RestartCount:   data.RestartCount,
+		OOMKilled:      data.State.OOMKilled,
 	}
 
 	return container, nil
@@ -409,10 +491,12 @@ type containerStats struct {
 	NetworkRx   uint64
 	NetworkTx   uint64
 	PIDs        int
+	// NetworkKnown is false when docker stats gave no usable NetIO
+	NetworkKnown bool
 }
 
 func getContainerStats(containerID string) *containerStats {
-	ctx, cancel := contextWithTimeout(3 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "docker", "stats", containerID, "--no-stream", "--format", "{{json .}}")
@@ -421,6 +505,49 @@ func getContainerStats(containerID string) *containerStats {
 		return nil
 	}
 
+	return parseStatsLine(output)
+}
+
+// getAllContainerStats samples every running container in a single docker
+// stats call. The map is keyed by the short (12 char) container ID.
+func getAllContainerStats() map[string]*containerStats {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "docker", "stats", "--no-stream", "--format", "{{json .}}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	result := make(map[string]*containerStats)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var raw struct {
+			ID string `json:"ID"`
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil || raw.ID == "" {
+			continue
+		}
+		if stats := parseStatsLine([]byte(line)); stats != nil {
+			result[shortID(raw.ID)] = stats
+		}
+	}
+
+	return result
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// parseStatsLine parses one line of docker stats --format "{{json .}}"
+func parseStatsLine(output []byte) *containerStats {
 	var raw struct {
 		CPUPerc  string `json:"CPUPerc"`
 		MemUsage string `json:"MemUsage"`
@@ -446,11 +573,7 @@ func getContainerStats(containerID string) *containerStats {
 	}
 
 	// Parse network I/O (e.g., "1.45kB / 0B")
-	netParts := strings.Split(raw.NetIO, " / ")
-	if len(netParts) == 2 {
-		stats.NetworkRx = parseSize(strings.TrimSpace(netParts[0]))
-		stats.NetworkTx = parseSize(strings.TrimSpace(netParts[1]))
-	}
+	stats.NetworkRx, stats.NetworkTx, stats.NetworkKnown = parseNetIO(raw.NetIO)
 
 	// Parse PIDs
 	fmt.Sscanf(raw.PIDs, "%d", &stats.PIDs)
@@ -458,28 +581,92 @@ func getContainerStats(containerID string) *containerStats {
 	return stats
 }
 
+// parseNetIO parses docker stats' NetIO column, "rx / tx". Some engines
+// list one pair per network ("1kB / 2kB, 3kB / 4kB"), which are summed.
+// ok is false for "--", "N/A" or anything else without a number.
+func parseNetIO(s string) (rx, tx uint64, ok bool) {
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(pair, "/")
+		if len(parts) != 2 {
+			continue
+		}
+		r, rok := parseSizeOK(parts[0])
+		t, tok := parseSizeOK(parts[1])
+		if !rok || !tok {
+			continue
+		}
+		rx += r
+		tx += t
+		ok = true
+	}
+	return rx, tx, ok
+}
+
 func parseSize(s string) uint64 {
+	size, _ := parseSizeOK(s)
+	return size
+}
+
+// parseSizeOK parses sizes as docker prints them: binary units for
+// memory ("54.3MiB") and decimal ones for I/O ("1.45kB")
+func parseSizeOK(s string) (uint64, bool) {
 	s = strings.TrimSpace(s)
 
 	var value float64
 	var unit string
 
-	fmt.Sscanf(s, "%f%s", &value, &unit)
+	if n, _ := fmt.Sscanf(s, "%f%s", &value, &unit); n == 0 || value < 0 {
+		return 0, false
+	}
 
 	unit = strings.ToLower(unit)
+	base := 1000.0
+	if strings.Contains(unit, "i") {
+		base = 1024
+	}
 
 	switch {
 	case strings.HasPrefix(unit, "k"):
-		return uint64(value * 1024)
+		return uint64(value * base), true
 	case strings.HasPrefix(unit, "m"):
-		return uint64(value * 1024 * 1024)
+		return uint64(value * base * base), true
 	case strings.HasPrefix(unit, "g"):
-		return uint64(value * 1024 * 1024 * 1024)
+		return uint64(value * base * base * base), true
 	case strings.HasPrefix(unit, "t"):
-		return uint64(value * 1024 * 1024 * 1024 * 1024)
+		return uint64(value * base * base * base * base), true
 	default:
-		return uint64(value)
+		return uint64(value), true
+	}
+}
+
+// readContainerNetDev sums the traffic of every interface but loopback in
+// the network namespace of pid. Only Linux has /proc; with Docker Desktop
+// the containers run inside a VM and this reports nothing.
+func readContainerNetDev(pid int) (rx, tx uint64, ok bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		name, counters, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) < 9 {
+			continue
+		}
+		r, err1 := strconv.ParseUint(fields[0], 10, 64)
+		t, err2 := strconv.ParseUint(fields[8], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		rx += r
+		tx += t
+		ok = true
 	}
+	return rx, tx, ok
 }
 
 func DockerAction(containerID, action string) error {
@@ -487,7 +674,7 @@ func DockerAction(containerID, action string) error {
 		return fmt.Errorf("docker not available")
 	}
 
-	ctx, cancel := contextWithTimeout(30 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().DockerAction)
 	defer cancel()
 
 	var cmd *exec.Cmd
@@ -512,13 +699,40 @@ func DockerAction(containerID, action string) error {
 	return cmd.Run()
 }
 
+// RemoveContainer deletes a container with docker rm. A running container
+// is only removed when force is set, which kills it first.
+func RemoveContainer(containerID string, force bool) error {
+	if !checkDockerAvailable() {
+		return fmt.Errorf("docker not available")
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().DockerAction)
+	defer cancel()
+
+	args := []string{"rm"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, containerID)
+
+	// docker's message ("cannot remove a running container...") is more
+	// useful than the exit status
+	if output, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
 // GetContainerLogs returns the last n lines of container logs
 func GetContainerLogs(containerID string, tail int) (string, error) {
 	if !checkDockerAvailable() {
 		return "", fmt.Errorf("docker not available")
 	}
 
-	ctx, cancel := contextWithTimeout(10 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().DockerLogs)
 	defer cancel()
 
 	tailStr := fmt.Sprintf("%d", tail)
@@ -549,7 +763,7 @@ func GetContainerTop(containerID string) ([]ContainerProcess, error) {
 		return nil, fmt.Errorf("docker not available")
 	}
 
-	ctx, cancel := contextWithTimeout(5 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "docker", "top", containerID, "-o", "uid,pid,ppid,%cpu,stime,tty,time,cmd")
@@ -589,7 +803,7 @@ func GetContainerInspect(containerID string) (string, error) {
 		return "", fmt.Errorf("docker not available")
 	}
 
-	ctx, cancel := contextWithTimeout(5 * time.Second)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "docker", "inspect", containerID)
@@ -600,3 +814,566 @@ func GetContainerInspect(containerID string) (string, error) {
 
 	return string(output), nil
 }
+
+// ContainerMatch is a container returned by SearchContainers with just
+// enough detail to identify it plus the env/labels that were searched.
+type ContainerMatch struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	Image  string            `json:"image"`
+	State  string            `json:"state"`
+	Env    []string          `json:"env,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type inspectedContainer struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State struct {
+		Status string `json:"Status"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Env    []string          `json:"Env"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	Mounts []struct {
+		Type string `json:"Type"`
+		Name string `json:"Name"`
+	} `json:"Mounts"`
+}
+
+var (
+	inspectAllCache    []inspectedContainer
+	inspectAllCachedAt time.Time
+	inspectAllMu       sync.Mutex
+	inspectAllTTL      = 10 * time.Second
+)
+
+// inspectAllContainers runs a single docker inspect over every container.
+// Results are cached briefly since searches tend to come in bursts.
+func inspectAllContainers() ([]inspectedContainer, error) {
+	inspectAllMu.Lock()
+	defer inspectAllMu.Unlock()
+
+	if !inspectAllCachedAt.IsZero() && time.Since(inspectAllCachedAt) < inspectAllTTL {
+		return inspectAllCache, nil
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "ps", "-a", "-q").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %v", err)
+	}
+
+	ids := strings.Fields(string(output))
+	if len(ids) == 0 {
+		inspectAllCache = nil
+		inspectAllCachedAt = time.Now()
+		return nil, nil
+	}
+
+	ctx2, cancel2 := contextWithTimeout(currentTimeouts().DockerLogs)
+	defer cancel2()
+
+	output, err = exec.CommandContext(ctx2, "docker", append([]string{"inspect"}, ids...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect containers: %v", err)
+	}
+
+	var data []inspectedContainer
+	if err := json.Unmarshal(output, &data); err != nil {
+		return nil, err
+	}
+
+	inspectAllCache = data
+	inspectAllCachedAt = time.Now()
+	return data, nil
+}
+
+// splitKeyValue splits "KEY=VAL" into its parts. A bare "KEY" only
+// requires the key to be present.
+func splitKeyValue(s string) (key, value string, hasValue bool) {
+	if idx := strings.Index(s, "="); idx >= 0 {
+		return s[:idx], s[idx+1:], true
+	}
+	return s, "", false
+}
+
+// SearchContainers returns containers whose env and/or labels match the
+// given "KEY=VAL" (or bare "KEY") queries. Empty queries are ignored.
+func SearchContainers(envQuery, labelQuery string) ([]ContainerMatch, error) {
+	if !checkDockerAvailable() {
+		return nil, fmt.Errorf("docker not available")
+	}
+
+	containers, err := inspectAllContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []ContainerMatch{}
+	for _, c := range containers {
+		if envQuery != "" && !envMatches(c.Config.Env, envQuery) {
+			continue
+		}
+		if labelQuery != "" && !labelMatches(c.Config.Labels, labelQuery) {
+			continue
+		}
+
+		id := c.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+
+		matches = append(matches, ContainerMatch{
+			ID:     id,
+			Name:   strings.TrimPrefix(c.Name, "/"),
+			Image:  c.Config.Image,
+			State:  c.State.Status,
+			Env:    c.Config.Env,
+			Labels: c.Config.Labels,
+		})
+	}
+
+	return matches, nil
+}
+
+func envMatches(env []string, query string) bool {
+	key, value, hasValue := splitKeyValue(query)
+	for _, e := range env {
+		k, v, _ := splitKeyValue(e)
+		if k == key && (!hasValue || v == value) {
+			return true
+		}
+	}
+	return false
+}
+
+func labelMatches(labels map[string]string, query string) bool {
+	key, value, hasValue := splitKeyValue(query)
+	v, ok := labels[key]
+	return ok && (!hasValue || v == value)
+}
+
+// RedactEnv replaces env values with "***", keeping the variable names.
+func RedactEnv(env []string) []string {
+	redacted := make([]string, 0, len(env))
+	for _, e := range env {
+		key, _, _ := splitKeyValue(e)
+		redacted = append(redacted, key+"=***")
+	}
+	return redacted
+}
+
+// RedactInspectEnv applies RedactEnv to the Config.Env of every object in
+// docker inspect output. Output that doesn't parse is refused rather than
+// passed through unredacted.
+func RedactInspectEnv(inspect string) (string, error) {
+	var objects []map[string]interface{}
+	if err := json.Unmarshal([]byte(inspect), &objects); err != nil {
+		return "", fmt.Errorf("invalid inspect output: %v", err)
+	}
+
+	for _, obj := range objects {
+		cfg, ok := obj["Config"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		values, ok := cfg["Env"].([]interface{})
+		if !ok {
+			continue
+		}
+		env := make([]string, 0, len(values))
+		for _, v := range values {
+			if e, ok := v.(string); ok {
+				env = append(env, e)
+			}
+		}
+		cfg["Env"] = RedactEnv(env)
+	}
+
+	// Indented like docker's own output
+	redacted, err := json.MarshalIndent(objects, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(redacted), nil
+}
+
+type DockerVolume struct {
+	Name       string   `json:"name"`
+	Driver     string   `json:"driver"`
+	Mountpoint string   `json:"mountpoint"`
+	Scope      string   `json:"scope,omitempty"`
+	CreatedAt  string   `json:"createdAt,omitempty"`
+	Size       int64    `json:"size,omitempty"` // Only reported by some drivers
+	Containers []string `json:"containers"`     // Names of containers mounting this volume
+}
+
+type DockerVolumesInfo struct {
+	Available bool           `json:"available"`
+	Volumes   []DockerVolume `json:"volumes"`
+}
+
+// GetDockerVolumes lists docker volumes along with the containers using them
+func GetDockerVolumes() (DockerVolumesInfo, error) {
+	if !checkDockerAvailable() {
+		return DockerVolumesInfo{Available: false}, nil
+	}
+
+	info := DockerVolumesInfo{Available: true, Volumes: []DockerVolume{}}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "volume", "ls", "--format", "{{json .}}").Output()
+	if err != nil {
+		return info, fmt.Errorf("failed to list volumes: %v", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var raw struct {
+			Name   string `json:"Name"`
+			Driver string `json:"Driver"`
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			continue
+		}
+		names = append(names, raw.Name)
+	}
+
+	if len(names) == 0 {
+		return info, nil
+	}
+
+	ctx2, cancel2 := contextWithTimeout(currentTimeouts().DockerLogs)
+	defer cancel2()
+
+	output, err = exec.CommandContext(ctx2, "docker", append([]string{"volume", "inspect"}, names...)...).Output()
+	if err != nil {
+		return info, fmt.Errorf("failed to inspect volumes: %v", err)
+	}
+
+	var inspectData []struct {
+		Name       string `json:"Name"`
+		Driver     string `json:"Driver"`
+		Mountpoint string `json:"Mountpoint"`
+		Scope      string `json:"Scope"`
+		CreatedAt  string `json:"CreatedAt"`
+		UsageData  *struct {
+			Size int64 `json:"Size"`
+		} `json:"UsageData"`
+	}
+	if err := json.Unmarshal(output, &inspectData); err != nil {
+		return info, err
+	}
+
+	// Cross-reference volume mounts from all containers
+	usedBy := make(map[string][]string)
+	if containers, err := inspectAllContainers(); err == nil {
+		for _, c := range containers {
+			for _, m := range c.Mounts {
+				if m.Type == "volume" && m.Name != "" {
+					usedBy[m.Name] = append(usedBy[m.Name], strings.TrimPrefix(c.Name, "/"))
+				}
+			}
+		}
+	}
+
+	for _, v := range inspectData {
+		volume := DockerVolume{
+			Name:       v.Name,
+			Driver:     v.Driver,
+			Mountpoint: v.Mountpoint,
+			Scope:      v.Scope,
+			CreatedAt:  v.CreatedAt,
+			Containers: usedBy[v.Name],
+		}
+		if v.UsageData != nil && v.UsageData.Size >= 0 {
+			volume.Size = v.UsageData.Size
+		}
+		if volume.Containers == nil {
+			volume.Containers = []string{}
+		}
+		info.Volumes = append(info.Volumes, volume)
+	}
+
+	return info, nil
+}
+
+// ComposeProject groups the containers of a docker compose project
+type ComposeProject struct {
+	Name        string      `json:"name"`
+	Status      string      `json:"status"` // running (all up), partial, stopped
+	Running     int         `json:"running"`
+	Total       int         `json:"total"`
+	CPUPercent  float64     `json:"cpuPercent"`
+	MemoryUsage uint64      `json:"memoryUsage"`
+	Containers  []Container `json:"containers"`
+}
+
+type ComposeInfo struct {
+	Available bool             `json:"available"`
+	Projects  []ComposeProject `json:"projects"`
+}
+
+// standaloneProject is the group for containers without a compose label
+const standaloneProject = "(standalone)"
+
+// GetComposeProjects groups all containers by their com.docker.compose.project
+// label, with aggregate CPU/memory from a single docker stats sample.
+func GetComposeProjects() (ComposeInfo, error) {
+	if !checkDockerAvailable() {
+		return ComposeInfo{Available: false}, nil
+	}
+
+	info := ComposeInfo{Available: true, Projects: []ComposeProject{}}
+
+	containers, err := inspectAllContainers()
+	if err != nil {
+		return info, err
+	}
+
+	var stats map[string]*containerStats
+	if enabledFeatures().DockerStats {
+		stats = getAllContainerStats()
+	}
+
+	projects := make(map[string]*ComposeProject)
+	var order []string
+
+	for _, c := range containers {
+		name := c.Config.Labels["com.docker.compose.project"]
+		if name == "" {
+			name = standaloneProject
+		}
+
+		project, ok := projects[name]
+		if !ok {
+			project = &ComposeProject{Name: name, Containers: []Container{}}
+			projects[name] = project
+			order = append(order, name)
+		}
+
+		container := Container{
+			ID:     shortID(c.ID),
+			Name:   strings.TrimPrefix(c.Name, "/"),
+			Image:  c.Config.Image,
+			State:  c.State.Status,
+			Labels: c.Config.Labels,
+		}
+		if st, ok := stats[shortID(c.ID)]; ok {
+			container.CPUPercent = st.CPUPercent
+			container.MemoryUsage = st.MemoryUsage
+			container.MemoryLimit = st.MemoryLimit
+			container.PIDs = st.PIDs
+			project.CPUPercent += st.CPUPercent
+			project.MemoryUsage += st.MemoryUsage
+		}
+
+		project.Total++
+		if container.State == "running" {
+			project.Running++
+		}
+		project.Containers = append(project.Containers, container)
+	}
+
+	sort.Strings(order)
+	for _, name := range order {
+		project := projects[name]
+		switch {
+		case project.Running == project.Total:
+			project.Status = "running"
+		case project.Running > 0:
+			project.Status = "partial"
+		default:
+			project.Status = "stopped"
+		}
+		info.Projects = append(info.Projects, *project)
+	}
+
+	return info, nil
+}
+
+// IsContainerRunning reports whether the given container exists and is running
+func IsContainerRunning(containerID string) (bool, error) {
+	if !checkDockerAvailable() {
+		return false, fmt.Errorf("docker not available")
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Docker)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.State.Running}}", containerID).Output()
+	if err != nil {
+		return false, fmt.Errorf("container not found: %s", containerID)
+	}
+
+	return strings.TrimSpace(string(output)) == "true", nil
+}
+
+// ExecResult summarizes a finished ExecInContainer call
+type ExecResult struct {
+	ExitCode  int  `json:"exitCode"`
+	Truncated bool `json:"truncated"` // Output exceeded the byte cap
+	TimedOut  bool `json:"timedOut"`
+}
+
+// ExecInContainer runs cmd inside a container via docker exec and passes the
+// combined stdout/stderr to onOutput in chunks as it arrives. Once
+// maxBytes of output have been delivered the command is killed and the
+// result is marked Truncated. It is also killed when ctx is done or the
+// timeout expires.
+func ExecInContainer(ctx context.Context, containerID string, cmd []string, timeout time.Duration, maxBytes int, onOutput func(string) error) (ExecResult, error) {
+	var result ExecResult
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := append([]string{"exec", containerID}, cmd...)
+	c := exec.CommandContext(ctx, "docker", args...)
+
+	pr, pw := io.Pipe()
+	c.Stdout = pw
+	c.Stderr = pw
+
+	if err := c.Start(); err != nil {
+		return result, err
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := c.Wait()
+		pw.Close()
+		waitErr <- err
+	}()
+
+	// Read in fixed-size chunks rather than lines, so output without
+	// newlines can't grow a buffer past maxBytes
+	sent := 0
+	buf := make([]byte, 32*1024)
+	for {
+		size := len(buf)
+		if maxBytes > 0 {
+			// One byte past the cap tells us there was more output
+			size = min(size, maxBytes-sent+1)
+		}
+		n, readErr := pr.Read(buf[:size])
+		if n > 0 {
+			chunk := buf[:n]
+			if maxBytes > 0 && sent+n > maxBytes {
+				chunk = chunk[:maxBytes-sent]
+				result.Truncated = true
+			}
+			sent += len(chunk)
+			if len(chunk) > 0 {
+				if err := onOutput(string(chunk)); err != nil {
+					// Client went away; stop the command
+					break
+				}
+			}
+			if result.Truncated {
+				break
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	cancel()
+	// Unblocks the copy from docker exec if we stopped reading early
+	pr.Close()
+
+	err := <-waitErr
+	if ctx.Err() == context.DeadlineExceeded {
+		result.TimedOut = true
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+			return result, nil
+		}
+		return result, err
+	}
+
+	return result, nil
+}
+
+// maxLogLineBytes caps a single line streamed by FollowContainerLogs
+const maxLogLineBytes = 64 * 1024
+
+// FollowContainerLogs runs docker logs -f, starting with the last tail
+// lines, and calls onLine for each line of stdout or stderr. It blocks
+// until ctx is cancelled (which kills docker logs), the container stops,
+// or onLine returns an error.
+func FollowContainerLogs(ctx context.Context, containerID string, tail int, onLine func(string) error) error {
+	if !checkDockerAvailable() {
+		return fmt.Errorf("docker not available")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "docker", "logs", "-f", "--tail", strconv.Itoa(tail), "--timestamps", containerID)
+
+	pr, pw := io.Pipe()
+	c.Stdout = pw
+	c.Stderr = pw
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := c.Wait()
+		pw.Close()
+		waitErr <- err
+	}()
+
+	var sendErr error
+	send := func(line []byte) {
+		if sendErr = onLine(strings.TrimRight(string(line), "\n")); sendErr != nil {
+			// Client went away; stop docker logs
+			cancel()
+		}
+	}
+
+	// ReadSlice never buffers more than maxLogLineBytes; a longer line is
+	// cut at that length and the rest of it dropped
+	reader := bufio.NewReaderSize(pr, maxLogLineBytes)
+	overlong := false
+	for {
+		line, readErr := reader.ReadSlice('\n')
+		if readErr == bufio.ErrBufferFull {
+			if !overlong && sendErr == nil {
+				send(line)
+			}
+			overlong = true
+			continue
+		}
+		if len(line) > 0 && !overlong && sendErr == nil {
+			send(line)
+		}
+		overlong = false
+		if readErr != nil {
+			break
+		}
+	}
+
+	err := <-waitErr
+	if sendErr != nil {
+		return sendErr
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,232 @@
+package collectors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDocker puts a docker script on PATH: "exec <id> cmd..." runs cmd,
+// "logs" runs the LOGS_CMD environment variable
+func fakeDocker(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+exec) shift 2; exec "$@" ;;
+logs) exec sh -c "$LOGS_CMD" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	prev := dockerAvailable
+	available := true
+	dockerAvailable = &available
+	t.Cleanup(func() { dockerAvailable = prev })
+}
+
+func TestExecInContainerCapsOutput(t *testing.T) {
+	fakeDocker(t)
+
+	tests := []struct {
+		name      string
+		cmd       []string
+		maxBytes  int
+		wantBytes int
+		truncated bool
+	}{
+		// yes never stops and head -c has no newlines; both must stop at the cap
+		{"endless lines", []string{"yes"}, 1000, 1000, true},
+		{"no newlines", []string{"head", "-c", "200000", "/dev/zero"}, 100000, 100000, true},
+		{"under the cap", []string{"echo", "hello"}, 1000, 6, false},
+		{"exactly the cap", []string{"printf", "12345"}, 5, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := 0
+			result, err := ExecInContainer(context.Background(), "c", tt.cmd, 10*time.Second, tt.maxBytes, func(chunk string) error {
+				got += len(chunk)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantBytes {
+				t.Errorf("got %d bytes, want %d", got, tt.wantBytes)
+			}
+			if result.Truncated != tt.truncated {
+				t.Errorf("Truncated = %v, want %v", result.Truncated, tt.truncated)
+			}
+			if result.TimedOut {
+				t.Error("TimedOut set")
+			}
+		})
+	}
+}
+
+func TestFollowContainerLogsLongLine(t *testing.T) {
+	fakeDocker(t)
+	t.Setenv("LOGS_CMD", "echo first; head -c 200000 /dev/zero | tr '\\0' x; echo; echo last")
+
+	var lines []string
+	err := FollowContainerLogs(context.Background(), "c", 10, func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if lines[0] != "first" || lines[2] != "last" {
+		t.Errorf("got lines %q and %q around the long one", lines[0], lines[2])
+	}
+	if len(lines[1]) != maxLogLineBytes || strings.Trim(lines[1], "x") != "" {
+		t.Errorf("long line is %d bytes, want %d", len(lines[1]), maxLogLineBytes)
+	}
+}
+
+func TestParseNetIO(t *testing.T) {
+	tests := []struct {
+		name   string
+		netIO  string
+		rx, tx uint64
+		ok     bool
+	}{
+		{"bridge", "1.45kB / 648B", 1450, 648, true},
+		{"large", "3.2GB / 870MB", 3200000000, 870000000, true},
+		{"binary units", "2MiB / 1KiB", 2 * 1024 * 1024, 1024, true},
+		// Host and none networking, as docker and podman print them
+		{"host network", "0B / 0B", 0, 0, true},
+		{"no network", "--", 0, 0, false},
+		{"no network, pair", "-- / --", 0, 0, false},
+		{"not available", "N/A", 0, 0, false},
+		{"empty", "", 0, 0, false},
+		{"two networks", "1kB / 2kB, 3kB / 4kB", 4000, 6000, true},
+		{"one network unknown", "1kB / 2kB, -- / --", 1000, 2000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rx, tx, ok := parseNetIO(tt.netIO)
+			if rx != tt.rx || tx != tt.tx || ok != tt.ok {
+				t.Errorf("parseNetIO(%q) = %d, %d, %v, want %d, %d, %v", tt.netIO, rx, tx, ok, tt.rx, tt.tx, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur uint64
+		want      uint64
+	}{
+		{"growth", 1000, 1500, 500},
+		{"idle", 1000, 1000, 0},
+		{"from zero", 0, 700, 700},
+		// A restarted container starts counting from zero again
+		{"reset", 5000000, 1200, 1200},
+		{"reset to zero", 5000000, 0, 0},
+		// Past the top of the counter there is no telling how far it went
+		// round, so it counts as starting over
+		{"wraparound", ^uint64(0) - 10, 5, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := counterDelta(tt.prev, tt.cur); got != tt.want {
+				t.Errorf("counterDelta(%d, %d) = %d, want %d", tt.prev, tt.cur, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerHistoryNetworkRate(t *testing.T) {
+	containerHistoryMu.Lock()
+	prev := containerHistory
+	containerHistory = make(map[string]*containerRings)
+	containerHistoryMu.Unlock()
+	t.Cleanup(func() {
+		containerHistoryMu.Lock()
+		containerHistory = prev
+		containerHistoryMu.Unlock()
+	})
+
+	const id = "0123456789ab"
+	sample := func(netIO string) map[string]*containerStats {
+		s := &containerStats{}
+		s.NetworkRx, s.NetworkTx, s.NetworkKnown = parseNetIO(netIO)
+		return map[string]*containerStats{id: s}
+	}
+
+	tests := []struct {
+		name           string
+		first, second  string
+		rxRate, txRate float64
+		samples        int
+	}{
+		{"steady traffic", "10kB / 2kB", "30kB / 6kB", 10000, 2000, 1},
+		{"idle", "10kB / 2kB", "10kB / 2kB", 0, 0, 1},
+		{"counters reset", "10MB / 5MB", "4kB / 1kB", 2000, 500, 1},
+		{"no network", "--", "--", 0, 0, 0},
+		{"network appears", "--", "8kB / 4kB", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerHistoryMu.Lock()
+			delete(containerHistory, id)
+			containerHistoryMu.Unlock()
+
+			// Two samples two seconds apart
+			now := time.Now().UnixMilli()
+			recordContainerStats(now-2000, sample(tt.first), 10)
+			recordContainerStats(now, sample(tt.second), 10)
+
+			h, err := GetContainerHistory(id, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(h.NetworkRx) != tt.samples || len(h.NetworkTx) != tt.samples {
+				t.Fatalf("%d rx and %d tx samples, want %d", len(h.NetworkRx), len(h.NetworkTx), tt.samples)
+			}
+			if tt.samples == 0 {
+				return
+			}
+			if h.NetworkRx[0].Value != tt.rxRate || h.NetworkTx[0].Value != tt.txRate {
+				t.Errorf("rates %v / %v, want %v / %v", h.NetworkRx[0].Value, h.NetworkTx[0].Value, tt.rxRate, tt.txRate)
+			}
+		})
+	}
+}
+
+func TestRedactInspectEnv(t *testing.T) {
+	inspect := `[{"Id":"abc","Config":{"Env":["TOKEN=secret","EMPTY="],"Image":"nginx"}},{"Id":"def","Config":{"Env":null}}]`
+	got, err := RedactInspectEnv(inspect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "secret") {
+		t.Errorf("value left in %s", got)
+	}
+	for _, want := range []string{`"TOKEN=***"`, `"EMPTY=***"`, `"Image": "nginx"`, `"Id": "def"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s missing from %s", want, got)
+		}
+	}
+
+	if _, err := RedactInspectEnv("not json"); err == nil {
+		t.Error("unparsable output passed through")
+	}
+}
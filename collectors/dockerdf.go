@@ -0,0 +1,173 @@
+package collectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DockerDiskUsageType is one row of docker system df: images, containers,
+// local volumes or build cache
+type DockerDiskUsageType struct {
+	Type        string `json:"type"`
+	TotalCount  int    `json:"totalCount"`
+	Active      int    `json:"active"`
+	Size        uint64 `json:"size"`
+	Reclaimable uint64 `json:"reclaimable"`
+}
+
+// DockerDiskUsageItem is one image, container, volume or cache entry of
+// docker system df -v
+type DockerDiskUsageItem struct {
+	Type  string `json:"type"` // "image", "container", "volume" or "buildCache"
+	Name  string `json:"name"`
+	Size  uint64 `json:"size"`
+	InUse bool   `json:"inUse"`
+}
+
+type DockerDiskUsage struct {
+	Available        bool                  `json:"available"`
+	Types            []DockerDiskUsageType `json:"types"`
+	TotalSize        uint64                `json:"totalSize"`
+	TotalReclaimable uint64                `json:"totalReclaimable"`
+	Items            []DockerDiskUsageItem `json:"items,omitempty"` // Only when verbose
+}
+
+// GetDockerDiskUsage reports how much disk docker uses and how much of it
+// a prune would free. verbose adds a per-object breakdown, which is slower
+// as docker has to size every container and volume.
+func GetDockerDiskUsage(verbose bool) (DockerDiskUsage, error) {
+	if !checkDockerAvailable() {
+		return DockerDiskUsage{Available: false}, nil
+	}
+
+	usage := DockerDiskUsage{Available: true, Types: []DockerDiskUsageType{}}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().DockerLogs)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "system", "df", "--format", "{{json .}}").Output()
+	if err != nil {
+		return usage, fmt.Errorf("failed to get docker disk usage: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var raw struct {
+			Type        string `json:"Type"`
+			TotalCount  string `json:"TotalCount"`
+			Active      string `json:"Active"`
+			Size        string `json:"Size"`
+			Reclaimable string `json:"Reclaimable"` // e.g. "1.2GB (50%)"
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			continue
+		}
+
+		reclaimable, _, _ := strings.Cut(raw.Reclaimable, " ")
+		t := DockerDiskUsageType{
+			Type:        raw.Type,
+			Size:        parseSize(raw.Size),
+			Reclaimable: parseSize(reclaimable),
+		}
+		t.TotalCount, _ = strconv.Atoi(raw.TotalCount)
+		t.Active, _ = strconv.Atoi(raw.Active)
+
+		usage.Types = append(usage.Types, t)
+		usage.TotalSize += t.Size
+		usage.TotalReclaimable += t.Reclaimable
+	}
+
+	if verbose {
+		items, err := getDockerDiskUsageItems()
+		if err != nil {
+			return usage, err
+		}
+		usage.Items = items
+	}
+
+	return usage, nil
+}
+
+func getDockerDiskUsageItems() ([]DockerDiskUsageItem, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().DockerLogs)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "system", "df", "-v", "--format", "{{json .}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get docker disk usage detail: %v", err)
+	}
+
+	// Every field is a string, as docker prints it in the table
+	var raw struct {
+		Images []struct {
+			ID         string `json:"ID"`
+			Repository string `json:"Repository"`
+			Tag        string `json:"Tag"`
+			Size       string `json:"Size"`
+			Containers string `json:"Containers"`
+		} `json:"Images"`
+		Containers []struct {
+			Names  string `json:"Names"`
+			Size   string `json:"Size"`
+			Status string `json:"Status"`
+		} `json:"Containers"`
+		Volumes []struct {
+			Name  string `json:"Name"`
+			Size  string `json:"Size"`
+			Links string `json:"Links"`
+		} `json:"Volumes"`
+		BuildCache []struct {
+			ID    string `json:"ID"`
+			Size  string `json:"Size"`
+			InUse string `json:"InUse"`
+		} `json:"BuildCache"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse docker disk usage detail: %v", err)
+	}
+
+	items := []DockerDiskUsageItem{}
+	for _, img := range raw.Images {
+		name := img.Repository + ":" + img.Tag
+		if img.Repository == "<none>" {
+			name = shortID(strings.TrimPrefix(img.ID, "sha256:"))
+		}
+		items = append(items, DockerDiskUsageItem{
+			Type:  "image",
+			Name:  name,
+			Size:  parseSize(img.Size),
+			InUse: img.Containers != "" && img.Containers != "0",
+		})
+	}
+	for _, c := range raw.Containers {
+		items = append(items, DockerDiskUsageItem{
+			Type:  "container",
+			Name:  c.Names,
+			Size:  parseSize(c.Size),
+			InUse: strings.HasPrefix(c.Status, "Up"),
+		})
+	}
+	for _, v := range raw.Volumes {
+		items = append(items, DockerDiskUsageItem{
+			Type:  "volume",
+			Name:  v.Name,
+			Size:  parseSize(v.Size),
+			InUse: v.Links != "" && v.Links != "0",
+		})
+	}
+	for _, b := range raw.BuildCache {
+		items = append(items, DockerDiskUsageItem{
+			Type:  "buildCache",
+			Name:  b.ID,
+			Size:  parseSize(b.Size),
+			InUse: b.InUse == "true",
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,162 @@
+package collectors
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContainerHistory is the recorded resource usage of one container
+type ContainerHistory struct {
+	ID        string          `json:"id"`        // Short (12 char) container ID
+	CPU       []HistorySample `json:"cpu"`       // Percent of one core
+	Memory    []HistorySample `json:"memory"`    // Bytes
+	NetworkRx []HistorySample `json:"networkRx"` // Bytes per second received
+	NetworkTx []HistorySample `json:"networkTx"` // Bytes per second sent
+}
+
+// containerRings holds the samples of one container
+type containerRings struct {
+	cpu    *ringBuffer
+	memory *ringBuffer
+	netRx  *ringBuffer
+	netTx  *ringBuffer
+	// Last network counters, to turn the next ones into a rate; nil until
+	// docker stats reports usable NetIO
+	lastNet *netCounters
+}
+
+type netCounters struct {
+	rx, tx uint64
+	time   int64 // Unix milliseconds
+}
+
+// counterDelta is how much a cumulative counter grew from prev to cur. A
+// counter only goes down when it starts over - the container restarted
+// or its network was re-created - so cur is then all there is since.
+func counterDelta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+var (
+	containerHistory     map[string]*containerRings
+	containerHistoryMu   sync.RWMutex
+	containerHistoryOnce sync.Once
+)
+
+// StartContainerHistory samples the CPU and memory usage of every running
+// container every interval, keeping the last size samples of each. A
+// container's history is dropped once it is no longer running.
+func StartContainerHistory(interval time.Duration, size int) {
+	if interval <= 0 || size <= 0 {
+		return
+	}
+
+	containerHistoryOnce.Do(func() {
+		containerHistoryMu.Lock()
+		containerHistory = make(map[string]*containerRings)
+		containerHistoryMu.Unlock()
+
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for now := range ticker.C {
+				if !enabledFeatures().DockerStats || !checkDockerAvailable() {
+					continue
+				}
+				// nil means docker stats failed; keep what we have rather
+				// than pruning every container on a transient error
+				stats := getAllContainerStats()
+				if stats == nil {
+					continue
+				}
+				recordContainerStats(now.UnixMilli(), stats, size)
+			}
+		}()
+	})
+}
+
+func recordContainerStats(ts int64, stats map[string]*containerStats, size int) {
+	containerHistoryMu.Lock()
+	defer containerHistoryMu.Unlock()
+
+	for id := range containerHistory {
+		if _, ok := stats[id]; !ok {
+			delete(containerHistory, id)
+		}
+	}
+
+	for id, s := range stats {
+		rings, ok := containerHistory[id]
+		if !ok {
+			rings = &containerRings{
+				cpu:    newRingBuffer(size),
+				memory: newRingBuffer(size),
+				netRx:  newRingBuffer(size),
+				netTx:  newRingBuffer(size),
+			}
+			containerHistory[id] = rings
+		}
+		rings.cpu.add(HistorySample{Time: ts, Value: s.CPUPercent})
+		rings.memory.add(HistorySample{Time: ts, Value: float64(s.MemoryUsage)})
+		rings.addNetwork(ts, s)
+	}
+}
+
+// addNetwork records the traffic rate since the previous sample
+func (r *containerRings) addNetwork(ts int64, s *containerStats) {
+	if !s.NetworkKnown {
+		return
+	}
+
+	cur := &netCounters{rx: s.NetworkRx, tx: s.NetworkTx, time: ts}
+	if prev := r.lastNet; prev != nil && ts > prev.time {
+		secs := float64(ts-prev.time) / 1000
+		r.netRx.add(HistorySample{Time: ts, Value: float64(counterDelta(prev.rx, cur.rx)) / secs})
+		r.netTx.add(HistorySample{Time: ts, Value: float64(counterDelta(prev.tx, cur.tx)) / secs})
+	}
+	r.lastNet = cur
+}
+
+// GetContainerHistory returns the samples of a container within window.
+// The ID may be the full ID or any unambiguous prefix of it.
+func GetContainerHistory(containerID string, window time.Duration) (*ContainerHistory, error) {
+	containerHistoryMu.RLock()
+	defer containerHistoryMu.RUnlock()
+
+	if containerHistory == nil {
+		return nil, fmt.Errorf("container history is disabled")
+	}
+
+	prefix := shortID(containerID)
+	id := prefix
+	rings, ok := containerHistory[id]
+	if !ok {
+		for key, r := range containerHistory {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if rings != nil {
+				return nil, fmt.Errorf("container ID %q is ambiguous", containerID)
+			}
+			id, rings = key, r
+		}
+	}
+	if rings == nil {
+		return nil, fmt.Errorf("no history for container %q", containerID)
+	}
+
+	cutoff := time.Now().Add(-window).UnixMilli()
+	return &ContainerHistory{
+		ID:        id,
+		CPU:       rings.cpu.since(cutoff),
+		Memory:    rings.memory.since(cutoff),
+		NetworkRx: rings.netRx.since(cutoff),
+		NetworkTx: rings.netTx.since(cutoff),
+	}, nil
+}
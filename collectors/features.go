@@ -0,0 +1,37 @@
+package collectors
+
+import "sync"
+
+// Features toggles the expensive parts of the collectors. Everything but
+// ProcessSocketCounts is enabled by default; operators on constrained hosts
+// can trade detail for speed by turning them off in the config.
+type Features struct {
+	CPUTemps            bool // Per-core and package temperatures from hwmon
+	ProcessFDs          bool // Open file descriptors in process detail, fd counts in the list
+	ProcessSocketCounts bool // Socket counts in the list, one readlink per descriptor
+	SocketProcesses     bool // Map socket inodes to owning processes
+	DockerStats         bool // Live CPU/memory stats for containers
+}
+
+var (
+	features = Features{
+		CPUTemps:        true,
+		ProcessFDs:      true,
+		SocketProcesses: true,
+		DockerStats:     true,
+	}
+	featuresMu sync.RWMutex
+)
+
+// SetFeatures replaces the set of enabled collector sub-features
+func SetFeatures(f Features) {
+	featuresMu.Lock()
+	features = f
+	featuresMu.Unlock()
+}
+
+func enabledFeatures() Features {
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+	return features
+}
@@ -0,0 +1,22 @@
+package collectors
+
+// DeletedFile is a file that was unlinked while a process still holds it
+// open, so its space is only reclaimed once the process closes it or exits.
+type DeletedFile struct {
+	PID  int    `json:"pid"`
+	Name string `json:"name"`
+	FD   int    `json:"fd"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type DeletedFilesInfo struct {
+	Files     []DeletedFile `json:"files"`
+	TotalSize int64         `json:"totalSize"` // Each file counted once even if open in several places
+	Truncated bool          `json:"truncated,omitempty"`
+	// Processes whose fds couldn't be read (usually needs root)
+	PermissionDenied int `json:"permissionDenied,omitempty"`
+}
+
+// maxDeletedFiles bounds the response on hosts leaking many files
+const maxDeletedFiles = 1000
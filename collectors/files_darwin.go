@@ -0,0 +1,51 @@
+//go:build darwin
+
+package collectors
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GetDeletedFiles lists open files with a link count of zero via lsof +L1
+func GetDeletedFiles() (*DeletedFilesInfo, error) {
+	info := &DeletedFilesInfo{
+		Files: []DeletedFile{},
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	// lsof exits 1 when nothing matches, so only give up on empty output
+	out, _ := exec.CommandContext(ctx, "lsof", "-nP", "+L1").Output()
+
+	// COMMAND PID USER FD TYPE DEVICE SIZE/OFF NLINK NODE NAME
+	lines := strings.Split(string(out), "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 10 || fields[4] != "REG" {
+			continue
+		}
+
+		if len(info.Files) >= maxDeletedFiles {
+			info.Truncated = true
+			break
+		}
+
+		pid, _ := strconv.Atoi(fields[1])
+		fd, _ := strconv.Atoi(strings.TrimRight(fields[3], "rwu"))
+		size, _ := strconv.ParseInt(fields[6], 10, 64)
+
+		info.Files = append(info.Files, DeletedFile{
+			PID:  pid,
+			Name: fields[0],
+			FD:   fd,
+			Path: strings.Join(fields[9:], " "),
+			Size: size,
+		})
+		info.TotalSize += size
+	}
+
+	return info, nil
+}
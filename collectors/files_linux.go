@@ -0,0 +1,87 @@
+//go:build linux
+
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// GetDeletedFiles scans /proc/*/fd for descriptors pointing at unlinked files
+func GetDeletedFiles() (*DeletedFilesInfo, error) {
+	info := &DeletedFilesInfo{
+		Files: []DeletedFile{},
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	// Count each underlying file once in TotalSize
+	type fileKey struct{ dev, ino uint64 }
+	counted := make(map[fileKey]bool)
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		links, err := readFDLinks(pid)
+		if err != nil {
+			if os.IsPermission(err) {
+				info.PermissionDenied++
+			}
+			continue
+		}
+
+		name := ""
+		for _, link := range links {
+			if !strings.HasPrefix(link.Target, "/") || !strings.HasSuffix(link.Target, " (deleted)") {
+				continue
+			}
+
+			if len(info.Files) >= maxDeletedFiles {
+				info.Truncated = true
+				return info, nil
+			}
+
+			if name == "" {
+				if comm, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm")); err == nil {
+					name = strings.TrimSpace(string(comm))
+				}
+			}
+
+			file := DeletedFile{
+				PID:  pid,
+				Name: name,
+				FD:   link.FD,
+				Path: strings.TrimSuffix(link.Target, " (deleted)"),
+			}
+
+			// Stat follows the fd link to the still-open inode
+			if st, err := os.Stat(link.Path); err == nil {
+				file.Size = st.Size()
+				if sys, ok := st.Sys().(*syscall.Stat_t); ok {
+					key := fileKey{uint64(sys.Dev), sys.Ino}
+					if !counted[key] {
+						counted[key] = true
+						info.TotalSize += file.Size
+					}
+				}
+			}
+
+			info.Files = append(info.Files, file)
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package collectors
+
+// GetDeletedFiles returns an empty list: Windows won't delete a file while
+// a handle to it is open, so the situation can't arise in the same way.
+func GetDeletedFiles() (*DeletedFilesInfo, error) {
+	return &DeletedFilesInfo{
+		Files: []DeletedFile{},
+	}, nil
+}
@@ -0,0 +1,65 @@
+package collectors
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// FirewallRaw is the unparsed ruleset as printed by the backend's own tooling
+type FirewallRaw struct {
+	Backend string `json:"backend"`
+	Command string `json:"command"`
+	Output  string `json:"output"`
+}
+
+// firewallRawCommand is the command used to dump a backend's full ruleset
+type firewallRawCommand struct {
+	backend string
+	args    []string
+}
+
+// IsFirewallBackend reports whether name is a backend known on this platform
+func IsFirewallBackend(name string) bool {
+	for _, c := range firewallRawCommands {
+		if c.backend == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetFirewallRaw returns the complete ruleset dump for the given backend. An
+// empty backend picks the first one whose dump command succeeds, in the same
+// order GetFirewallInfo probes them.
+func GetFirewallRaw(backend string) (*FirewallRaw, error) {
+	if backend != "" && !IsFirewallBackend(backend) {
+		return nil, fmt.Errorf("unknown firewall backend: %s", backend)
+	}
+
+	var lastErr error
+	for _, c := range firewallRawCommands {
+		if backend != "" && c.backend != backend {
+			continue
+		}
+
+		ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+		out, err := exec.CommandContext(ctx, c.args[0], c.args[1:]...).Output()
+		cancel()
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", c.backend, err)
+			continue
+		}
+
+		return &FirewallRaw{
+			Backend: c.backend,
+			Command: strings.Join(c.args, " "),
+			Output:  string(out),
+		}, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no firewall backend available")
+	}
+	return nil, lastErr
+}
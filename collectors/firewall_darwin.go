@@ -14,6 +14,10 @@ type FirewallRule struct {
 	Action   string `json:"action"`
 }
 
+var firewallRawCommands = []firewallRawCommand{
+	{"pf", []string{"pfctl", "-s", "all"}},
+}
+
 type FirewallInfo struct {
 	Available bool           `json:"available"`
 	Backend   string         `json:"backend,omitempty"`
@@ -22,13 +26,16 @@ type FirewallInfo struct {
 }
 
 func GetFirewallInfo() (FirewallInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	info := FirewallInfo{
 		Available: true,
 		Backend:   "pf",
 	}
 
 	// Check if pf is enabled
-	out, err := exec.Command("pfctl", "-s", "info").Output()
+	out, err := exec.CommandContext(ctx, "pfctl", "-s", "info").Output()
 	if err != nil {
 		info.Available = false
 		return info, nil
@@ -40,7 +47,7 @@ func GetFirewallInfo() (FirewallInfo, error) {
 	}
 
 	// Get rules (simplified)
-	rulesOut, err := exec.Command("pfctl", "-s", "rules").Output()
+	rulesOut, err := exec.CommandContext(ctx, "pfctl", "-s", "rules").Output()
 	if err == nil {
 		lines := strings.Split(string(rulesOut), "\n")
 		for _, line := range lines {
@@ -3,20 +3,32 @@
 package collectors
 
 import (
+	"encoding/json"
 	"os/exec"
 	"strconv"
 	"strings"
 )
 
 type FirewallRule struct {
-	Chain       string `json:"chain"`
-	Protocol    string `json:"protocol"`
-	Port        int    `json:"port"`
-	Source      string `json:"source"`
-	Destination string `json:"destination"`
-	Action      string `json:"action"`
-	Interface   string `json:"interface"`
-	Raw         string `json:"raw"`
+	Chain        string `json:"chain"`
+	Protocol     string `json:"protocol"`
+	Port         int    `json:"port"`
+	Ports        string `json:"ports,omitempty"` // Ranges or sets, e.g. "8000-8080" or "80,443"
+	Source       string `json:"source"`
+	Destination  string `json:"destination"`
+	Action       string `json:"action"`
+	Interface    string `json:"interface"`
+	InInterface  string `json:"inInterface,omitempty"`
+	OutInterface string `json:"outInterface,omitempty"`
+	Raw          string `json:"raw"`
+}
+
+// firewallRawCommands lists backends in the order GetFirewallInfo probes them
+var firewallRawCommands = []firewallRawCommand{
+	{"ufw", []string{"ufw", "status", "verbose"}},
+	{"firewalld", []string{"firewall-cmd", "--list-all-zones"}},
+	{"nftables", []string{"nft", "list", "ruleset"}},
+	{"iptables", []string{"iptables-save"}},
 }
 
 type FirewallInfo struct {
@@ -56,8 +68,11 @@ func GetFirewallInfo() (*FirewallInfo, error) {
 }
 
 func tryUFW() *FirewallInfo {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Check if ufw is available
-	cmd := exec.Command("ufw", "status", "verbose")
+	cmd := exec.CommandContext(ctx, "ufw", "status", "verbose")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
@@ -131,8 +146,11 @@ func parseUFWRule(line string) *FirewallRule {
 }
 
 func tryFirewalld() *FirewallInfo {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Check if firewalld is running
-	cmd := exec.Command("firewall-cmd", "--state")
+	cmd := exec.CommandContext(ctx, "firewall-cmd", "--state")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
@@ -146,7 +164,7 @@ func tryFirewalld() *FirewallInfo {
 	}
 
 	// Get open ports
-	cmd = exec.Command("firewall-cmd", "--list-ports")
+	cmd = exec.CommandContext(ctx, "firewall-cmd", "--list-ports")
 	output, err = cmd.Output()
 	if err == nil {
 		ports := strings.Fields(string(output))
@@ -165,7 +183,7 @@ func tryFirewalld() *FirewallInfo {
 	}
 
 	// Get services
-	cmd = exec.Command("firewall-cmd", "--list-services")
+	cmd = exec.CommandContext(ctx, "firewall-cmd", "--list-services")
 	output, err = cmd.Output()
 	if err == nil {
 		services := strings.Fields(string(output))
@@ -181,7 +199,201 @@ func tryFirewalld() *FirewallInfo {
 }
 
 func tryNftables() *FirewallInfo {
-	cmd := exec.Command("nft", "list", "ruleset")
+	if info := tryNftablesJSON(); info != nil {
+		return info
+	}
+	return tryNftablesText()
+}
+
+// nftDocument is the top level of `nft -j list ruleset`. Each element holds
+// exactly one of table, chain, rule, set, etc.; only rules are used here.
+type nftDocument struct {
+	Nftables []struct {
+		Rule *struct {
+			Family string                   `json:"family"`
+			Table  string                   `json:"table"`
+			Chain  string                   `json:"chain"`
+			Expr   []map[string]interface{} `json:"expr"`
+		} `json:"rule,omitempty"`
+	} `json:"nftables"`
+}
+
+// tryNftablesJSON parses the structured ruleset. Older nft builds without
+// -j support make this return nil so the text parser is used instead.
+func tryNftablesJSON() *FirewallInfo {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "nft", "-j", "list", "ruleset").Output()
+	if err != nil {
+		return nil
+	}
+
+	var doc nftDocument
+	if err := json.Unmarshal(output, &doc); err != nil {
+		return nil
+	}
+
+	info := &FirewallInfo{
+		Available: true,
+		Backend:   "nftables",
+		Active:    true,
+		Rules:     []FirewallRule{},
+	}
+
+	for _, item := range doc.Nftables {
+		if item.Rule == nil {
+			continue
+		}
+		info.Rules = append(info.Rules, parseNftRuleExpr(item.Rule.Chain, item.Rule.Expr))
+	}
+
+	return info
+}
+
+// parseNftRuleExpr builds a FirewallRule from the match and verdict
+// statements of one rule, and renders them back into nft-like text for Raw
+func parseNftRuleExpr(chain string, exprs []map[string]interface{}) FirewallRule {
+	rule := FirewallRule{Chain: chain}
+	var raw []string
+
+	for _, expr := range exprs {
+		for kind, value := range expr {
+			switch kind {
+			case "match":
+				m, _ := value.(map[string]interface{})
+				left := nftExprString(m["left"])
+				right := nftExprString(m["right"])
+				if op, _ := m["op"].(string); op != "" && op != "==" && op != "in" {
+					raw = append(raw, left+" "+op+" "+right)
+				} else {
+					raw = append(raw, left+" "+right)
+				}
+
+				switch nftMatchField(m["left"]) {
+				case "iifname":
+					rule.InInterface = right
+				case "oifname":
+					rule.OutInterface = right
+				case "saddr":
+					rule.Source = right
+				case "daddr":
+					rule.Destination = right
+				case "l4proto", "protocol", "nexthdr":
+					rule.Protocol = right
+				case "dport":
+					if port, err := strconv.Atoi(right); err == nil {
+						rule.Port = port
+					} else {
+						rule.Ports = right
+					}
+				}
+
+				// "tcp dport 22" implies the protocol even without an l4proto match
+				if payload, ok := nftPayload(m["left"]); ok && rule.Protocol == "" {
+					if proto, _ := payload["protocol"].(string); proto == "tcp" || proto == "udp" {
+						rule.Protocol = proto
+					}
+				}
+
+			case "accept", "drop", "reject", "return":
+				rule.Action = strings.ToUpper(kind)
+				raw = append(raw, kind)
+
+			case "jump", "goto":
+				rule.Action = "JUMP"
+				target := ""
+				if v, ok := value.(map[string]interface{}); ok {
+					target, _ = v["target"].(string)
+				}
+				raw = append(raw, kind+" "+target)
+
+			case "counter", "log", "limit", "masquerade", "snat", "dnat", "redirect":
+				raw = append(raw, kind)
+			}
+		}
+	}
+
+	rule.Interface = rule.InInterface
+	if rule.Interface == "" {
+		rule.Interface = rule.OutInterface
+	}
+	rule.Raw = strings.Join(raw, " ")
+	return rule
+}
+
+// nftPayload returns the payload object of a match's left-hand side
+func nftPayload(v interface{}) (map[string]interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	payload, ok := m["payload"].(map[string]interface{})
+	return payload, ok
+}
+
+// nftMatchField names what a match's left-hand side refers to: a payload
+// field (dport, saddr...) or a meta key (iifname, l4proto...)
+func nftMatchField(v interface{}) string {
+	if payload, ok := nftPayload(v); ok {
+		field, _ := payload["field"].(string)
+		return field
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		if meta, ok := m["meta"].(map[string]interface{}); ok {
+			key, _ := meta["key"].(string)
+			return key
+		}
+	}
+	return ""
+}
+
+// nftExprString renders a JSON expression as it would appear in nft text:
+// scalars as-is, sets as "a,b", ranges as "a-b", prefixes as "addr/len"
+func nftExprString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []interface{}:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			parts = append(parts, nftExprString(item))
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		if set, ok := val["set"]; ok {
+			return nftExprString(set)
+		}
+		if r, ok := val["range"].([]interface{}); ok && len(r) == 2 {
+			return nftExprString(r[0]) + "-" + nftExprString(r[1])
+		}
+		if prefix, ok := val["prefix"].(map[string]interface{}); ok {
+			return nftExprString(prefix["addr"]) + "/" + nftExprString(prefix["len"])
+		}
+		if payload, ok := val["payload"].(map[string]interface{}); ok {
+			return nftExprString(payload["protocol"]) + " " + nftExprString(payload["field"])
+		}
+		if meta, ok := val["meta"].(map[string]interface{}); ok {
+			return nftExprString(meta["key"])
+		}
+		if ct, ok := val["ct"].(map[string]interface{}); ok {
+			return "ct " + nftExprString(ct["key"])
+		}
+	}
+	return ""
+}
+
+func tryNftablesText() *FirewallInfo {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "nft", "list", "ruleset")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
@@ -208,44 +420,83 @@ func tryNftables() *FirewallInfo {
 			continue
 		}
 
-		// Parse rules with port specifications
-		if strings.Contains(line, "dport") || strings.Contains(line, "sport") {
-			rule := FirewallRule{
-				Chain: currentChain,
-				Raw:   line,
-			}
+		// Closing brace of a chain, sets and tables sit outside any chain
+		if line == "}" {
+			currentChain = ""
+			continue
+		}
 
-			// Extract protocol
-			if strings.Contains(line, "tcp") {
-				rule.Protocol = "tcp"
-			} else if strings.Contains(line, "udp") {
-				rule.Protocol = "udp"
-			}
+		// Skip chain headers ("type filter hook input ...", "policy drop;") and comments
+		if currentChain == "" || line == "" || strings.HasPrefix(line, "type ") ||
+			strings.HasPrefix(line, "policy ") || strings.HasPrefix(line, "#") {
+			continue
+		}
 
-			// Extract port
-			if idx := strings.Index(line, "dport "); idx != -1 {
-				portStr := strings.Fields(line[idx+6:])[0]
-				rule.Port, _ = strconv.Atoi(portStr)
-			}
+		rule := FirewallRule{
+			Chain: currentChain,
+			Raw:   line,
+		}
 
-			// Extract action
-			if strings.Contains(line, "accept") {
-				rule.Action = "ACCEPT"
-			} else if strings.Contains(line, "drop") {
-				rule.Action = "DROP"
-			} else if strings.Contains(line, "reject") {
-				rule.Action = "REJECT"
-			}
+		rule.InInterface = nftValue(line, "iifname")
+		rule.OutInterface = nftValue(line, "oifname")
+		rule.Interface = rule.InInterface
+		if rule.Interface == "" {
+			rule.Interface = rule.OutInterface
+		}
+		rule.Source = nftValue(line, "saddr")
+		rule.Destination = nftValue(line, "daddr")
+
+		// Extract protocol
+		if strings.Contains(line, "tcp") {
+			rule.Protocol = "tcp"
+		} else if strings.Contains(line, "udp") {
+			rule.Protocol = "udp"
+		}
 
-			info.Rules = append(info.Rules, rule)
+		// Extract port
+		if portStr := nftValue(line, "dport"); portStr != "" {
+			rule.Port, _ = strconv.Atoi(portStr)
 		}
+
+		// Extract action
+		if strings.Contains(line, "accept") {
+			rule.Action = "ACCEPT"
+		} else if strings.Contains(line, "drop") {
+			rule.Action = "DROP"
+		} else if strings.Contains(line, "reject") {
+			rule.Action = "REJECT"
+		} else if strings.Contains(line, "jump ") || strings.Contains(line, "goto ") {
+			rule.Action = "JUMP"
+		}
+
+		info.Rules = append(info.Rules, rule)
 	}
 
 	return info
 }
 
+// nftValue returns the token following keyword in an nft rule, unquoted
+func nftValue(line, keyword string) string {
+	fields := strings.Fields(line)
+	for i, f := range fields {
+		if f == keyword && i+1 < len(fields) {
+			value := fields[i+1]
+			// Skip comparison operators such as "!=" or "=="
+			if (value == "!=" || value == "==") && i+2 < len(fields) {
+				value = fields[i+2]
+			}
+			return strings.Trim(value, "\"")
+		}
+	}
+	return ""
+}
+
 func tryIptables() *FirewallInfo {
-	cmd := exec.Command("iptables", "-L", "-n", "--line-numbers")
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	// -v adds the pkts/bytes and in/out interface columns, -x exact counters
+	cmd := exec.CommandContext(ctx, "iptables", "-L", "-n", "-v", "-x", "--line-numbers")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
@@ -277,28 +528,34 @@ func tryIptables() *FirewallInfo {
 			continue
 		}
 
-		fields := strings.Fields(line)
-		if len(fields) < 5 {
-			continue
-		}
-
 		rule := FirewallRule{
 			Chain: currentChain,
 			Raw:   line,
 		}
 
-		// fields[1] = target (ACCEPT, DROP, REJECT, etc)
-		rule.Action = fields[1]
-
-		// fields[2] = protocol
-		rule.Protocol = fields[2]
-
-		// fields[4] = source
-		rule.Source = fields[4]
+		// num pkts bytes target prot opt in out source destination [extra]
+		// The target column is blank for rules that only count packets,
+		// which shifts everything after it one position left.
+		fields := strings.Fields(line)
+		if len(fields) >= 9 && !isIptablesOpt(fields[4]) && isIptablesOpt(fields[3]) {
+			fields = append(fields[:3], append([]string{""}, fields[3:]...)...)
+		}
 
-		// fields[5] = destination
-		if len(fields) > 5 {
-			rule.Destination = fields[5]
+		if len(fields) >= 10 {
+			rule.Action = fields[3]
+			rule.Protocol = fields[4]
+			if fields[6] != "*" {
+				rule.InInterface = fields[6]
+			}
+			if fields[7] != "*" {
+				rule.OutInterface = fields[7]
+			}
+			rule.Interface = rule.InInterface
+			if rule.Interface == "" {
+				rule.Interface = rule.OutInterface
+			}
+			rule.Source = fields[8]
+			rule.Destination = fields[9]
 		}
 
 		// Look for dpt: (destination port)
@@ -314,3 +571,8 @@ func tryIptables() *FirewallInfo {
 
 	return info
 }
+
+// isIptablesOpt reports whether f looks like the "opt" column of iptables -L
+func isIptablesOpt(f string) bool {
+	return f == "--" || f == "-f" || f == "!f"
+}
@@ -23,6 +23,10 @@ type FirewallRule struct {
 	Action   string `json:"action"`
 }
 
+var firewallRawCommands = []firewallRawCommand{
+	{"windows", []string{"netsh", "advfirewall", "firewall", "show", "rule", "name=all", "verbose"}},
+}
+
 type FirewallInfo struct {
 	Available bool           `json:"available"`
 	Backend   string         `json:"backend,omitempty"`
@@ -31,6 +35,9 @@ type FirewallInfo struct {
 }
 
 func GetFirewallInfo() (FirewallInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	firewallMu.Lock()
 	if !firewallCachedAt.IsZero() && time.Since(firewallCachedAt) < firewallTTL {
 		cached := firewallCache
@@ -45,7 +52,7 @@ func GetFirewallInfo() (FirewallInfo, error) {
 	}
 
 	// Check firewall state
-	out, err := exec.Command("netsh", "advfirewall", "show", "allprofiles", "state").Output()
+	out, err := exec.CommandContext(ctx, "netsh", "advfirewall", "show", "allprofiles", "state").Output()
 	if err != nil {
 		info.Available = false
 		return info, nil
@@ -56,7 +63,7 @@ func GetFirewallInfo() (FirewallInfo, error) {
 	}
 
 	// Get some rules (simplified - full rule parsing is complex)
-	rulesOut, err := exec.Command("netsh", "advfirewall", "firewall", "show", "rule", "name=all", "dir=in").Output()
+	rulesOut, err := exec.CommandContext(ctx, "netsh", "advfirewall", "firewall", "show", "rule", "name=all", "dir=in").Output()
 	if err == nil {
 		lines := strings.Split(string(rulesOut), "\n")
 		var currentRule *FirewallRule
@@ -44,8 +44,11 @@ func GetGPUInfo() (*GPUInfo, error) {
 }
 
 func getNvidiaGPU() (*GPUInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Check if nvidia-smi is available
-	cmd := exec.Command("nvidia-smi",
+	cmd := exec.CommandContext(ctx, "nvidia-smi",
 		"--query-gpu=name,driver_version,memory.total,memory.used,memory.free,utilization.gpu,temperature.gpu,power.draw,power.limit,fan.speed",
 		"--format=csv,noheader,nounits")
 
@@ -89,8 +92,11 @@ func getNvidiaGPU() (*GPUInfo, error) {
 }
 
 func getAMDGPU() (*GPUInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Try rocm-smi for AMD GPUs
-	cmd := exec.Command("rocm-smi", "--showtemp", "--showuse", "--showmeminfo", "vram", "--json")
+	cmd := exec.CommandContext(ctx, "rocm-smi", "--showtemp", "--showuse", "--showmeminfo", "vram", "--json")
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -29,6 +29,9 @@ type GPUInfo struct {
 }
 
 func GetGPUInfo() (GPUInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	gpuMu.Lock()
 	if !gpuCachedAt.IsZero() && time.Since(gpuCachedAt) < gpuTTL {
 		cached := gpuCache
@@ -40,7 +43,7 @@ func GetGPUInfo() (GPUInfo, error) {
 	info := GPUInfo{}
 
 	// Try nvidia-smi first
-	if out, err := exec.Command("nvidia-smi", "--query-gpu=name,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw,fan.speed", "--format=csv,noheader,nounits").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu=name,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw,fan.speed", "--format=csv,noheader,nounits").Output(); err == nil {
 		parts := strings.Split(strings.TrimSpace(string(out)), ",")
 		if len(parts) >= 7 {
 			info.Available = true
@@ -59,7 +62,7 @@ func GetGPUInfo() (GPUInfo, error) {
 	}
 
 	// Try getting basic GPU info from WMIC
-	if out, err := exec.Command("wmic", "path", "win32_VideoController", "get", "Name", "/value").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "wmic", "path", "win32_VideoController", "get", "Name", "/value").Output(); err == nil {
 		for _, line := range strings.Split(string(out), "\n") {
 			if strings.HasPrefix(line, "Name=") {
 				info.Name = strings.TrimSpace(strings.TrimPrefix(line, "Name="))
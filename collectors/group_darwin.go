@@ -4,7 +4,9 @@ package collectors
 
 import (
 	"bufio"
+	"fmt"
 	"os"
+	"os/exec"
 	"os/user"
 	"strconv"
 	"strings"
@@ -68,6 +70,20 @@ func RemoveUserFromGroup(groupName, username string) error {
 	return nil
 }
 
+// AddUserToGroup appends a user to a group's membership with dscl. This
+// requires admin privileges.
+func AddUserToGroup(groupName, username string) error {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "dscl", ".", "-append", "/Groups/"+groupName, "GroupMembership", username)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add user to group: %s - %s", err.Error(), string(output))
+	}
+	return nil
+}
+
 // ModifyUserShell changes the user's default shell on macOS
 func ModifyUserShell(username, shell string) error {
 	// On macOS, use dscl to change shell
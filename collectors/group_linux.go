@@ -17,7 +17,7 @@ type GroupInfo struct {
 	Members []string `json:"members"`
 }
 
-// GetGroupInfo returns information about a group
+// GetGroupInfo returns information about a group, by name or GID
 func GetGroupInfo(groupname string) (*GroupInfo, error) {
 	file, err := os.Open("/etc/group")
 	if err != nil {
@@ -37,8 +37,9 @@ func GetGroupInfo(groupname string) (*GroupInfo, error) {
 			continue
 		}
 
+		// Like on the other platforms, a numeric argument may be a GID
 		name := parts[0]
-		if name != groupname {
+		if name != groupname && parts[2] != groupname {
 			continue
 		}
 
@@ -106,7 +107,10 @@ func getUsersWithPrimaryGroup(gid int) []string {
 
 // RemoveUserFromGroup removes a user from a group using gpasswd
 func RemoveUserFromGroup(groupname, username string) error {
-	cmd := exec.Command("gpasswd", "-d", username, groupname)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "gpasswd", "-d", username, groupname)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to remove user from group: %s - %s", err.Error(), string(output))
@@ -114,9 +118,25 @@ func RemoveUserFromGroup(groupname, username string) error {
 	return nil
 }
 
+// AddUserToGroup adds a user to a group using gpasswd
+func AddUserToGroup(groupname, username string) error {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "gpasswd", "-a", username, groupname)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add user to group: %s - %s", err.Error(), string(output))
+	}
+	return nil
+}
+
 // ModifyUserShell changes a user's shell using chsh
 func ModifyUserShell(username, shell string) error {
-	cmd := exec.Command("chsh", "-s", shell, username)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "chsh", "-s", shell, username)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to change shell: %s - %s", err.Error(), string(output))
@@ -126,7 +146,10 @@ func ModifyUserShell(username, shell string) error {
 
 // ModifyUserHome changes a user's home directory using usermod
 func ModifyUserHome(username, home string) error {
-	cmd := exec.Command("usermod", "-d", home, username)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "usermod", "-d", home, username)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to change home directory: %s - %s", err.Error(), string(output))
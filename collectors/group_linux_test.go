@@ -0,0 +1,27 @@
+//go:build linux
+
+package collectors
+
+import "testing"
+
+func TestGetGroupInfoByNameOrGID(t *testing.T) {
+	byName, err := GetGroupInfo("root")
+	if err != nil {
+		t.Skipf("no root group: %v", err)
+	}
+
+	byGID, err := GetGroupInfo("0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byGID.Name != "root" || byGID.GID != 0 {
+		t.Errorf("GID 0 resolved to %q (%d), want root", byGID.Name, byGID.GID)
+	}
+	if byName.Name != byGID.Name {
+		t.Errorf("name and GID lookups disagree: %q vs %q", byName.Name, byGID.Name)
+	}
+
+	if _, err := GetGroupInfo("4294967000"); err == nil {
+		t.Error("unknown GID found")
+	}
+}
@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"fmt"
 	"os/exec"
 	"os/user"
 	"strconv"
@@ -16,6 +17,9 @@ type GroupInfo struct {
 }
 
 func GetGroupInfo(groupName string) (*GroupInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	var g *user.Group
 	var err error
 
@@ -40,7 +44,7 @@ func GetGroupInfo(groupName string) (*GroupInfo, error) {
 	parts := strings.Split(g.Name, "\\")
 	localName := parts[len(parts)-1]
 
-	if out, err := exec.Command("net", "localgroup", localName).Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "net", "localgroup", localName).Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		inMembers := false
 		for _, line := range lines {
@@ -70,6 +74,22 @@ func RemoveUserFromGroup(groupName, username string) error {
 	return nil
 }
 
+// AddUserToGroup adds a user to a local group with net localgroup. This
+// requires admin privileges.
+func AddUserToGroup(groupName, username string) error {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	// Lookups return "HOST\Group"; net localgroup wants the bare name
+	parts := strings.Split(groupName, "\\")
+	cmd := exec.CommandContext(ctx, "net", "localgroup", parts[len(parts)-1], username, "/add")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add user to group: %s - %s", err.Error(), string(output))
+	}
+	return nil
+}
+
 // ModifyUserShell is not applicable on Windows (no shell concept like Unix)
 // Returns nil as a no-op
 func ModifyUserShell(username, shell string) error {
@@ -0,0 +1,128 @@
+package collectors
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HistorySample is one point of a metric's history
+type HistorySample struct {
+	Time  int64   `json:"time"` // Unix milliseconds
+	Value float64 `json:"value"`
+}
+
+// HistoryMetrics are the metrics recorded by the history sampler
+var HistoryMetrics = []string{"cpu", "memory", "netRx", "netTx"}
+
+// ringBuffer is a fixed-size circular buffer of samples
+type ringBuffer struct {
+	samples []HistorySample
+	next    int
+	full    bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{samples: make([]HistorySample, size)}
+}
+
+func (rb *ringBuffer) add(s HistorySample) {
+	rb.samples[rb.next] = s
+	rb.next = (rb.next + 1) % len(rb.samples)
+	if rb.next == 0 {
+		rb.full = true
+	}
+}
+
+// since returns the samples newer than cutoff, oldest first
+func (rb *ringBuffer) since(cutoff int64) []HistorySample {
+	result := []HistorySample{}
+	start, count := 0, rb.next
+	if rb.full {
+		start, count = rb.next, len(rb.samples)
+	}
+	for i := 0; i < count; i++ {
+		s := rb.samples[(start+i)%len(rb.samples)]
+		if s.Time >= cutoff {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+var (
+	history     map[string]*ringBuffer
+	historyMu   sync.RWMutex
+	historyOnce sync.Once
+)
+
+// StartHistory samples CPU usage, memory used percent and network
+// throughput (bytes/sec) every interval, keeping the last size samples of
+// each in memory.
+func StartHistory(interval time.Duration, size int) {
+	if interval <= 0 || size <= 0 {
+		return
+	}
+
+	historyOnce.Do(func() {
+		historyMu.Lock()
+		history = make(map[string]*ringBuffer, len(HistoryMetrics))
+		for _, metric := range HistoryMetrics {
+			history[metric] = newRingBuffer(size)
+		}
+		historyMu.Unlock()
+
+		go func() {
+			var prevRx, prevTx uint64
+			var prevAt time.Time
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for now := range ticker.C {
+				ts := now.UnixMilli()
+				samples := map[string]float64{}
+
+				if cpu, err := GetCPUInfo(); err == nil {
+					samples["cpu"] = cpu.UsagePercent
+				}
+				if mem, err := GetMemoryInfo(); err == nil {
+					samples["memory"] = mem.UsedPercent
+				}
+				// Compute throughput from the byte counters so this doesn't
+				// depend on how often other callers sample the network
+				if net, err := GetNetworkInfo(); err == nil {
+					if !prevAt.IsZero() && net.TotalRxBytes >= prevRx && net.TotalTxBytes >= prevTx {
+						elapsed := now.Sub(prevAt).Seconds()
+						samples["netRx"] = float64(net.TotalRxBytes-prevRx) / elapsed
+						samples["netTx"] = float64(net.TotalTxBytes-prevTx) / elapsed
+					}
+					prevRx, prevTx, prevAt = net.TotalRxBytes, net.TotalTxBytes, now
+				}
+
+				historyMu.Lock()
+				for metric, value := range samples {
+					history[metric].add(HistorySample{Time: ts, Value: value})
+				}
+				historyMu.Unlock()
+			}
+		}()
+	})
+}
+
+// GetHistory returns the recorded samples of metric within window
+func GetHistory(metric string, window time.Duration) ([]HistorySample, error) {
+	historyMu.RLock()
+	defer historyMu.RUnlock()
+
+	if history == nil {
+		return nil, fmt.Errorf("history is disabled")
+	}
+
+	rb, ok := history[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	return rb.since(time.Now().Add(-window).UnixMilli()), nil
+}
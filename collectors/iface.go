@@ -0,0 +1,154 @@
+package collectors
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SetInterfaceState brings a network interface up or down. The name must
+// match an existing interface, so nothing else can reach the command line.
+func SetInterfaceState(name string, up bool) error {
+	if _, err := net.InterfaceByName(name); err != nil {
+		return fmt.Errorf("unknown interface: %s", name)
+	}
+	return setInterfaceState(name, up)
+}
+
+// InterfaceForIP returns the name of the interface holding ip, if any
+func InterfaceForIP(ip net.IP) string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+				return iface.Name
+			}
+		}
+	}
+	return ""
+}
+
+// InterfaceAddress is one address of an interface, split out of its CIDR
+// notation
+type InterfaceAddress struct {
+	Address      string `json:"address"`
+	PrefixLength int    `json:"prefixLength"`
+	Family       string `json:"family"` // ipv4 or ipv6
+	Scope        string `json:"scope"`  // host, link, private or global
+}
+
+// interfaceAddresses converts the addresses from net.Interface.Addrs
+func interfaceAddresses(addrs []net.Addr) []InterfaceAddress {
+	result := []InterfaceAddress{}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ones, _ := ipNet.Mask.Size()
+		a := InterfaceAddress{
+			Address:      ipNet.IP.String(),
+			PrefixLength: ones,
+			Family:       "ipv6",
+			Scope:        addressScope(ipNet.IP),
+		}
+		if ipNet.IP.To4() != nil {
+			a.Family = "ipv4"
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// addressScope classifies ip the way the UI groups addresses. Private
+// covers RFC 1918, CGNAT and IPv6 unique local addresses.
+func addressScope(ip net.IP) string {
+	switch {
+	case ip.IsLoopback():
+		return "host"
+	case ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast():
+		return "link"
+	case ip.IsPrivate() || cgnatRange.Contains(ip):
+		return "private"
+	}
+	return "global"
+}
+
+// cgnatRange is the RFC 6598 shared address space used by carrier-grade
+// NAT and Tailscale
+var cgnatRange = &net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)}
+
+// Interface types reported in NetworkInterface.Type
+const (
+	InterfaceEthernet = "ethernet"
+	InterfaceWireless = "wireless"
+	InterfaceLoopback = "loopback"
+	InterfaceBridge   = "bridge"
+	InterfaceVirtual  = "virtual"
+	InterfaceTunnel   = "tunnel"
+)
+
+// interfaceNamePrefixes maps well-known name prefixes to interface types
+var interfaceNamePrefixes = []struct {
+	prefix string
+	kind   string
+}{
+	{"loopback", InterfaceLoopback},
+	{"veth", InterfaceVirtual}, // also Windows "vEthernet (...)"
+	{"vmnet", InterfaceVirtual},
+	{"vboxnet", InterfaceVirtual},
+	{"virbr", InterfaceBridge},
+	{"br-", InterfaceBridge},
+	{"bridge", InterfaceBridge},
+	{"docker", InterfaceBridge},
+	{"cni", InterfaceBridge},
+	{"wg", InterfaceTunnel},
+	{"tun", InterfaceTunnel},
+	{"tap", InterfaceTunnel},
+	{"utun", InterfaceTunnel},
+	{"gif", InterfaceTunnel},
+	{"stf", InterfaceTunnel},
+	{"ppp", InterfaceTunnel},
+	{"ipsec", InterfaceTunnel},
+	{"wl", InterfaceWireless},
+	{"wi-fi", InterfaceWireless},
+	{"awdl", InterfaceWireless},
+}
+
+// classifyInterfaceName guesses the interface type from its name alone.
+// Unrecognised names are assumed to be physical ethernet.
+func classifyInterfaceName(name string) string {
+	lower := strings.ToLower(name)
+	if lower == "lo" || (strings.HasPrefix(lower, "lo") && len(lower) > 2 && lower[2] >= '0' && lower[2] <= '9') {
+		return InterfaceLoopback
+	}
+	for _, p := range interfaceNamePrefixes {
+		if strings.HasPrefix(lower, p.prefix) {
+			return p.kind
+		}
+	}
+	return InterfaceEthernet
+}
+
+// IsVirtualInterfaceType reports whether t is a bridge, virtual or tunnel type
+func IsVirtualInterfaceType(t string) bool {
+	return t == InterfaceBridge || t == InterfaceVirtual || t == InterfaceTunnel
+}
+
+// FilterVirtualInterfaces drops bridge, virtual and tunnel interfaces
+func FilterVirtualInterfaces(ifaces []NetworkInterface) []NetworkInterface {
+	result := []NetworkInterface{}
+	for _, ni := range ifaces {
+		if !IsVirtualInterfaceType(ni.Type) {
+			result = append(result, ni)
+		}
+	}
+	return result
+}
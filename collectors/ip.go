@@ -1,25 +1,28 @@
 package collectors
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 )
 
 type IPInfo struct {
-	IP            string   `json:"ip"`
-	Hostname      string   `json:"hostname,omitempty"`
-	IsPrivate     bool     `json:"isPrivate"`
-	IsLoopback    bool     `json:"isLoopback"`
-	Version       string   `json:"version"` // "IPv4" or "IPv6"
-	Whois         string   `json:"whois,omitempty"`
-	ReverseDNS    []string `json:"reverseDns,omitempty"`
-	GeoIP         *GeoInfo `json:"geoip,omitempty"`
-	RelatedProcs  []int    `json:"relatedProcs,omitempty"`  // PIDs using this IP
+	IP                string   `json:"ip"`
+	Hostname          string   `json:"hostname,omitempty"`
+	IsPrivate         bool     `json:"isPrivate"`
+	IsLoopback        bool     `json:"isLoopback"`
+	Version           string   `json:"version"` // "IPv4" or "IPv6"
+	Whois             *Whois   `json:"whois,omitempty"`
+	ReverseDNS        []string `json:"reverseDns,omitempty"`
+	GeoIP             *GeoInfo `json:"geoip,omitempty"`
+	RelatedProcs      []int    `json:"relatedProcs,omitempty"`      // PIDs using this IP
+	EnrichmentSkipped bool     `json:"enrichmentSkipped,omitempty"` // External lookups skipped (budget exhausted)
 }
 
 type GeoInfo struct {
@@ -33,7 +36,191 @@ type GeoInfo struct {
 	Longitude   float64 `json:"longitude,omitempty"`
 }
 
+// geoDB holds the local MaxMind databases, when configured. With a local
+// database GeoIP never goes out to ip-api.com.
+var geoDB struct {
+	mu   sync.RWMutex
+	city *mmdbReader // GeoLite2-City or -Country
+	asn  *mmdbReader // GeoLite2-ASN, optional
+}
+
+// SetGeoIPDatabase loads local MaxMind .mmdb files used for GeoIP instead
+// of ip-api.com. Empty paths leave the corresponding database unset.
+func SetGeoIPDatabase(dbPath, asnDBPath string) error {
+	var city, asn *mmdbReader
+	var err error
+
+	if dbPath != "" {
+		if city, err = openMMDB(dbPath); err != nil {
+			return err
+		}
+	}
+	if asnDBPath != "" {
+		if asn, err = openMMDB(asnDBPath); err != nil {
+			return err
+		}
+	}
+
+	geoDB.mu.Lock()
+	geoDB.city = city
+	geoDB.asn = asn
+	geoDB.mu.Unlock()
+	return nil
+}
+
+// hasLocalGeoIP reports whether a local GeoIP database is loaded
+func hasLocalGeoIP() bool {
+	geoDB.mu.RLock()
+	defer geoDB.mu.RUnlock()
+	return geoDB.city != nil || geoDB.asn != nil
+}
+
+// lookupLocalGeoIP resolves ip against the local databases. Any database
+// may carry ASN fields, so both are checked for them.
+func lookupLocalGeoIP(ip net.IP) *GeoInfo {
+	geoDB.mu.RLock()
+	city, asnDB := geoDB.city, geoDB.asn
+	geoDB.mu.RUnlock()
+
+	geo := &GeoInfo{}
+	found := false
+
+	for _, db := range []*mmdbReader{city, asnDB} {
+		if db == nil {
+			continue
+		}
+		record, err := db.lookup(ip)
+		if err != nil || record == nil {
+			continue
+		}
+		found = true
+
+		if v, ok := mmdbPath(record, "country", "names", "en").(string); ok {
+			geo.Country = v
+		}
+		if v, ok := mmdbPath(record, "country", "iso_code").(string); ok {
+			geo.CountryCode = v
+		}
+		if v, ok := mmdbPath(record, "subdivisions", 0, "names", "en").(string); ok {
+			geo.Region = v
+		}
+		if v, ok := mmdbPath(record, "city", "names", "en").(string); ok {
+			geo.City = v
+		}
+		if v, ok := mmdbPath(record, "location", "latitude").(float64); ok {
+			geo.Latitude = v
+		}
+		if v, ok := mmdbPath(record, "location", "longitude").(float64); ok {
+			geo.Longitude = v
+		}
+		if n := mmdbUint(record["autonomous_system_number"]); n != 0 {
+			geo.ASN = fmt.Sprintf("AS%d", n)
+		}
+		if v, ok := record["autonomous_system_organization"].(string); ok {
+			geo.Org = v
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return geo
+}
+
+// lookupBudget is a token bucket shared by every code path that reaches
+// out to external services (whois, GeoIP, reverse DNS), so listing many IPs
+// can't get us rate-limited upstream or stall responses.
+var lookupBudget = struct {
+	mu        sync.Mutex
+	perMinute int
+	tokens    float64
+	last      time.Time
+}{perMinute: 60, tokens: 60}
+
+// SetIPLookupRate sets the max external lookups per minute (0 = unlimited).
+// Setting the current rate again is a no-op, so a config reload doesn't
+// refill a drained budget; a lower rate caps the tokens left.
+func SetIPLookupRate(perMinute int) {
+	lookupBudget.mu.Lock()
+	defer lookupBudget.mu.Unlock()
+
+	if perMinute == lookupBudget.perMinute {
+		return
+	}
+	if lookupBudget.perMinute <= 0 || lookupBudget.tokens > float64(perMinute) {
+		lookupBudget.tokens = float64(perMinute)
+	}
+	lookupBudget.perMinute = perMinute
+	lookupBudget.last = time.Now()
+}
+
+// allowExternalLookup takes a token from the budget. It never blocks:
+// when the bucket is empty the caller should skip the lookup.
+func allowExternalLookup() bool {
+	lookupBudget.mu.Lock()
+	defer lookupBudget.mu.Unlock()
+
+	if lookupBudget.perMinute <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	if !lookupBudget.last.IsZero() {
+		refill := now.Sub(lookupBudget.last).Minutes() * float64(lookupBudget.perMinute)
+		lookupBudget.tokens += refill
+		if lookupBudget.tokens > float64(lookupBudget.perMinute) {
+			lookupBudget.tokens = float64(lookupBudget.perMinute)
+		}
+	}
+	lookupBudget.last = now
+
+	if lookupBudget.tokens < 1 {
+		return false
+	}
+	lookupBudget.tokens--
+	return true
+}
+
+// addressFamilies reports whether a list of interface addresses (as returned
+// by net.Addr.String(), e.g. "192.168.1.2/24") has IPv4 and/or IPv6 entries.
+func addressFamilies(addrs []string) (hasIPv4, hasIPv6 bool) {
+	for _, a := range addrs {
+		ipStr := a
+		if idx := strings.Index(ipStr, "/"); idx >= 0 {
+			ipStr = ipStr[:idx]
+		}
+		ip := net.ParseIP(ipStr)
+		if ip == nil {
+			continue
+		}
+		if ip.To4() != nil {
+			hasIPv4 = true
+		} else {
+			hasIPv6 = true
+		}
+	}
+	return hasIPv4, hasIPv6
+}
+
+// countStack adds an interface to the system-wide dual-stack tally
+func (info *NetworkInfo) countStack(hasIPv4, hasIPv6 bool) {
+	switch {
+	case hasIPv4 && hasIPv6:
+		info.DualStack++
+	case hasIPv4:
+		info.IPv4Only++
+	case hasIPv6:
+		info.IPv6Only++
+	}
+}
+
 func GetIPInfo(ipStr string) (*IPInfo, error) {
+	return getIPInfo(ipStr, findProcessesUsingIP)
+}
+
+// getIPInfo is GetIPInfo with the PID lookup supplied by the caller, so a
+// batch can share one socket scan across all its addresses
+func getIPInfo(ipStr string, relatedProcs func(ip string) []int) (*IPInfo, error) {
 	ip := net.ParseIP(ipStr)
 	if ip == nil {
 		return nil, fmt.Errorf("invalid IP address: %s", ipStr)
@@ -52,63 +239,157 @@ func GetIPInfo(ipStr string) (*IPInfo, error) {
 		info.Version = "IPv6"
 	}
 
-	// Reverse DNS lookup
-	names, err := net.LookupAddr(ipStr)
-	if err == nil && len(names) > 0 {
-		info.ReverseDNS = names
-		info.Hostname = strings.TrimSuffix(names[0], ".")
+	// Reverse DNS lookup. The cache is shared with the sockets view and
+	// checked first, so only a miss spends a lookup token.
+	if host, ok := hostCache.get(ipStr); ok {
+		if name := host.(string); name != "" {
+			info.Hostname = name
+			info.ReverseDNS = []string{name}
+		}
+	} else if allowExternalLookup() {
+		names, err := net.LookupAddr(ipStr)
+		if err == nil && len(names) > 0 {
+			info.ReverseDNS = names
+			info.Hostname = strings.TrimSuffix(names[0], ".")
+			hostCache.put(ipStr, info.Hostname)
+		} else if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			hostCache.put(ipStr, "")
+		}
+	} else {
+		info.EnrichmentSkipped = true
 	}
 
-	// For public IPs, get more info
+	// For public IPs, get more info. Only parts that succeeded are
+	// cached, so a part missing from the cache is looked up again.
 	if !info.IsPrivate && !info.IsLoopback {
-		// Get whois info (run in background, with timeout)
-		info.Whois = getWhoisInfo(ipStr)
+		cached, _ := getCachedIPLookup(ipStr)
+		info.Whois = cached.whois
+		info.GeoIP = cached.geo
+
+		if info.Whois == nil {
+			if allowExternalLookup() {
+				info.Whois = getWhoisInfo(ipStr)
+			} else {
+				info.EnrichmentSkipped = true
+			}
+		}
+
+		// Prefer the local MaxMind database; otherwise ask ip-api.com
+		// (free, no API key needed)
+		if info.GeoIP == nil {
+			if hasLocalGeoIP() {
+				info.GeoIP = lookupLocalGeoIP(ip)
+			} else if allowExternalLookup() {
+				info.GeoIP = getGeoIPInfo(ipStr)
+			} else {
+				info.EnrichmentSkipped = true
+			}
+		}
 
-		// Get GeoIP info from ip-api.com (free, no API key needed)
-		info.GeoIP = getGeoIPInfo(ipStr)
+		if info.Whois != cached.whois || info.GeoIP != cached.geo {
+			putCachedIPLookup(ipStr, info.Whois, info.GeoIP)
+		}
 	}
 
 	// Find processes using this IP
-	info.RelatedProcs = findProcessesUsingIP(ipStr)
+	info.RelatedProcs = relatedProcs(ipStr)
 
 	return info, nil
 }
 
-func getWhoisInfo(ip string) string {
-	cmd := exec.Command("timeout", "5", "whois", ip)
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
+// MaxIPBatch caps how many addresses one GetIPInfoBatch call looks up
+const MaxIPBatch = 100
+
+// ipBatchWorkers bounds how many lookups run at once
+const ipBatchWorkers = 8
+
+// IPBatchResult holds the lookups finished before the deadline; addresses
+// missing from both maps were still pending
+type IPBatchResult struct {
+	Results map[string]*IPInfo `json:"results"`
+	Errors  map[string]string  `json:"errors,omitempty"`
+	Partial bool               `json:"partial"`
+}
+
+// GetIPInfoBatch runs GetIPInfo for several addresses concurrently. The
+// sockets are scanned once for the whole batch, on first use. When ctx
+// expires it returns what has finished; lookups still running complete in
+// the background and land in the IP cache for the next call.
+func GetIPInfoBatch(ctx context.Context, ips []string) *IPBatchResult {
+	type lookup struct {
+		ip   string
+		info *IPInfo
+		err  error
+	}
+
+	// Drop duplicates so a repeated address is looked up once
+	var unique []string
+	seen := make(map[string]bool)
+	for _, ip := range ips {
+		if !seen[ip] {
+			seen[ip] = true
+			unique = append(unique, ip)
+		}
 	}
 
-	// Parse and simplify whois output - extract key fields
-	lines := strings.Split(string(output), "\n")
-	var relevantLines []string
-	keywords := []string{"OrgName", "Organization", "org-name", "NetName", "netname",
-		"Country", "country", "descr", "abuse", "Address", "address", "inet6num", "route6"}
+	procIndex := sync.OnceValue(indexProcessesByIP)
+	relatedProcs := func(ip string) []int { return procIndex()[ip] }
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "%") {
-			continue
+	jobs := make(chan string)
+	done := make(chan lookup, len(unique)) // Buffered: late workers never block
+	for i := 0; i < min(ipBatchWorkers, len(unique)); i++ {
+		go func() {
+			for ip := range jobs {
+				info, err := getIPInfo(ip, relatedProcs)
+				done <- lookup{ip: ip, info: info, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, ip := range unique {
+			select {
+			case jobs <- ip:
+			case <-ctx.Done():
+				return
+			}
 		}
-		for _, kw := range keywords {
-			if strings.Contains(strings.ToLower(line), strings.ToLower(kw)) {
-				relevantLines = append(relevantLines, line)
-				break
+	}()
+
+	result := &IPBatchResult{
+		Results: make(map[string]*IPInfo),
+		Errors:  make(map[string]string),
+	}
+	for range unique {
+		select {
+		case l := <-done:
+			if l.err != nil {
+				result.Errors[l.ip] = l.err.Error()
+			} else {
+				result.Results[l.ip] = l.info
 			}
+		case <-ctx.Done():
+			result.Partial = true
+			return result
 		}
 	}
+	return result
+}
 
-	if len(relevantLines) > 20 {
-		relevantLines = relevantLines[:20]
+func getWhoisInfo(ip string) *Whois {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Whois)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "whois", ip).Output()
+	if err != nil {
+		return nil
 	}
 
-	return strings.Join(relevantLines, "\n")
+	return parseWhois(string(output))
 }
 
 func getGeoIPInfo(ip string) *GeoInfo {
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := &http.Client{Timeout: currentTimeouts().GeoIP}
 
 	resp, err := client.Get(fmt.Sprintf("http://ip-api.com/json/%s?fields=status,country,countryCode,region,city,lat,lon,org,as", ip))
 	if err != nil {
@@ -148,34 +429,44 @@ func getGeoIPInfo(ip string) *GeoInfo {
 	}
 }
 
+// getSocketInfo is GetSocketInfo; tests replace it to count scans
+var getSocketInfo = GetSocketInfo
+
 func findProcessesUsingIP(ip string) []int {
-	sockets, err := GetSocketInfo()
+	return indexProcessesByIP()[ip]
+}
+
+// indexProcessesByIP maps each local and remote socket address to the PIDs
+// using it, from one scan of the socket table
+func indexProcessesByIP() map[string][]int {
+	sockets, err := getSocketInfo()
 	if err != nil {
 		return nil
 	}
 
-	pidMap := make(map[int]bool)
-
-	for _, sock := range sockets.TCP {
-		if sock.LocalAddr == ip || sock.RemoteAddr == ip {
-			if sock.PID > 0 {
-				pidMap[sock.PID] = true
-			}
+	pidMap := make(map[string]map[int]bool)
+	add := func(addr string, pid int) {
+		if pid <= 0 {
+			return
+		}
+		if pidMap[addr] == nil {
+			pidMap[addr] = make(map[int]bool)
 		}
+		pidMap[addr][pid] = true
 	}
 
-	for _, sock := range sockets.UDP {
-		if sock.LocalAddr == ip || sock.RemoteAddr == ip {
-			if sock.PID > 0 {
-				pidMap[sock.PID] = true
-			}
+	for _, socks := range [][]Socket{sockets.TCP, sockets.UDP} {
+		for _, sock := range socks {
+			add(sock.LocalAddr, sock.PID)
+			add(sock.RemoteAddr, sock.PID)
 		}
 	}
 
-	var pids []int
-	for pid := range pidMap {
-		pids = append(pids, pid)
+	index := make(map[string][]int, len(pidMap))
+	for addr, pids := range pidMap {
+		for pid := range pids {
+			index[addr] = append(index[addr], pid)
+		}
 	}
-
-	return pids
+	return index
 }
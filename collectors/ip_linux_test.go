@@ -0,0 +1,49 @@
+//go:build linux
+
+package collectors
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestGetIPInfoBatchScansSocketsOnce(t *testing.T) {
+	emptyLookupBudget(t)
+	ips := []string{"10.3.0.1", "10.3.0.2", "10.3.0.3"}
+	for _, ip := range ips {
+		hostCache.put(ip, "")
+	}
+
+	scans := 0
+	prev := getSocketInfo
+	getSocketInfo = func() (*SocketInfo, error) {
+		scans++
+		return &SocketInfo{
+			TCP: []Socket{
+				{LocalAddr: "10.3.0.1", RemoteAddr: "10.3.0.2", PID: 100},
+				{LocalAddr: "10.3.0.1", RemoteAddr: "10.3.0.9", PID: 200},
+			},
+			UDP: []Socket{{LocalAddr: "10.3.0.1", PID: 100}},
+		}, nil
+	}
+	t.Cleanup(func() { getSocketInfo = prev })
+
+	result := GetIPInfoBatch(context.Background(), ips)
+	if scans != 1 {
+		t.Errorf("%d socket scans for one batch, want 1", scans)
+	}
+
+	want := map[string][]int{"10.3.0.1": {100, 200}, "10.3.0.2": {100}, "10.3.0.3": nil}
+	for ip, pids := range want {
+		info := result.Results[ip]
+		if info == nil {
+			t.Fatalf("no result for %s", ip)
+		}
+		got := slices.Clone(info.RelatedProcs)
+		slices.Sort(got)
+		if !slices.Equal(got, pids) {
+			t.Errorf("%s: RelatedProcs %v, want %v", ip, got, pids)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// setLookupBudget replaces the rate and the tokens left until the test ends
+func setLookupBudget(t *testing.T, perMinute int, tokens float64) {
+	t.Helper()
+	lookupBudget.mu.Lock()
+	prevRate, prevTokens := lookupBudget.perMinute, lookupBudget.tokens
+	lookupBudget.perMinute, lookupBudget.tokens = perMinute, tokens
+	lookupBudget.last = time.Now()
+	lookupBudget.mu.Unlock()
+
+	t.Cleanup(func() {
+		lookupBudget.mu.Lock()
+		lookupBudget.perMinute, lookupBudget.tokens = prevRate, prevTokens
+		lookupBudget.mu.Unlock()
+	})
+}
+
+// emptyLookupBudget leaves the budget and the caches empty until the test ends
+func emptyLookupBudget(t *testing.T) {
+	t.Helper()
+	setLookupBudget(t, 1, 0)
+	SetIPLookupCache(0, 0)
+	SetIPLookupCache(time.Hour, 1000)
+	t.Cleanup(func() {
+		SetIPLookupCache(0, 0)
+		SetIPLookupCache(time.Hour, 1000)
+	})
+}
+
+func TestGetIPInfoUsesHostCache(t *testing.T) {
+	emptyLookupBudget(t)
+	hostCache.put("10.1.2.3", "db.internal")
+	hostCache.put("10.1.2.4", "")
+
+	info, err := GetIPInfo("10.1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Hostname != "db.internal" || len(info.ReverseDNS) != 1 {
+		t.Errorf("Hostname %q, ReverseDNS %v, want the cached name", info.Hostname, info.ReverseDNS)
+	}
+	if info.EnrichmentSkipped {
+		t.Error("cache hit reported as skipped")
+	}
+
+	// A cached "no name" is an answer too
+	info, err = GetIPInfo("10.1.2.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Hostname != "" || info.EnrichmentSkipped {
+		t.Errorf("Hostname %q, EnrichmentSkipped %v, want neither", info.Hostname, info.EnrichmentSkipped)
+	}
+
+	// A miss with no budget left is skipped
+	info, err = GetIPInfo("10.1.2.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.EnrichmentSkipped {
+		t.Error("miss without budget not reported as skipped")
+	}
+}
+
+func TestGetIPInfoUsesIPCache(t *testing.T) {
+	emptyLookupBudget(t)
+	hostCache.put("203.0.113.7", "")
+	putCachedIPLookup("203.0.113.7", &Whois{}, &GeoInfo{Country: "Testland"})
+
+	info, err := GetIPInfo("203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.GeoIP == nil || info.GeoIP.Country != "Testland" {
+		t.Errorf("GeoIP %+v, want the cached result", info.GeoIP)
+	}
+	if info.EnrichmentSkipped {
+		t.Error("cache hit reported as skipped")
+	}
+}
+
+func TestGetIPInfoRetriesFailedParts(t *testing.T) {
+	emptyLookupBudget(t)
+	hostCache.put("203.0.113.8", "")
+
+	// Both parts failed: nothing to keep
+	putCachedIPLookup("203.0.113.8", nil, nil)
+	if _, ok := getCachedIPLookup("203.0.113.8"); ok {
+		t.Error("failed lookup cached")
+	}
+
+	// Whois answered, GeoIP timed out: the GeoIP part is looked up again,
+	// which the empty budget then skips
+	putCachedIPLookup("203.0.113.8", &Whois{OrgName: "Example"}, nil)
+	info, err := GetIPInfo("203.0.113.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Whois == nil || info.Whois.OrgName != "Example" {
+		t.Errorf("Whois %+v, want the cached result", info.Whois)
+	}
+	if !info.EnrichmentSkipped {
+		t.Error("GeoIP missing from the cache not retried")
+	}
+}
+
+func TestResolveRemoteHostsSkipped(t *testing.T) {
+	emptyLookupBudget(t)
+	hostCache.put("192.0.2.1", "cached.example")
+
+	sockets := []Socket{{RemoteAddr: "192.0.2.1"}, {RemoteAddr: "127.0.0.1"}}
+	if ResolveRemoteHosts(context.Background(), sockets) {
+		t.Error("skipped with every address cached or local")
+	}
+	if sockets[0].RemoteHost != "cached.example" {
+		t.Errorf("RemoteHost %q, want the cached name", sockets[0].RemoteHost)
+	}
+
+	sockets = []Socket{{RemoteAddr: "192.0.2.1"}, {RemoteAddr: "192.0.2.2"}}
+	if !ResolveRemoteHosts(context.Background(), sockets) {
+		t.Error("not skipped with an uncached address and no budget")
+	}
+	if sockets[0].RemoteHost != "cached.example" || sockets[1].RemoteHost != "" {
+		t.Errorf("RemoteHost %q and %q, want only the cached one", sockets[0].RemoteHost, sockets[1].RemoteHost)
+	}
+}
+
+func TestSetIPLookupRateKeepsBudget(t *testing.T) {
+	setLookupBudget(t, 60, 0)
+
+	// A reload with the same rate must not refill a drained budget
+	SetIPLookupRate(60)
+	if allowExternalLookup() {
+		t.Error("same rate refilled the budget")
+	}
+
+	// A lower rate caps what is left
+	setLookupBudget(t, 60, 50)
+	SetIPLookupRate(10)
+	lookupBudget.mu.Lock()
+	tokens := lookupBudget.tokens
+	lookupBudget.mu.Unlock()
+	if tokens != 10 {
+		t.Errorf("%v tokens after lowering the rate to 10", tokens)
+	}
+
+	// Coming from unlimited starts with a full bucket
+	setLookupBudget(t, 0, 0)
+	SetIPLookupRate(5)
+	if !allowExternalLookup() {
+		t.Error("no token after leaving unlimited")
+	}
+}
+
+func TestLRUCacheConfigure(t *testing.T) {
+	c := newLRUCache(time.Hour, 4)
+	for _, k := range []string{"a", "b", "c", "d"} {
+		c.put(k, k)
+	}
+	c.get("a") // a is now the most recently used
+
+	// Same limits: nothing dropped
+	c.configure(time.Hour, 4)
+	if c.order.Len() != 4 {
+		t.Fatalf("%d entries after reconfiguring with the same limits, want 4", c.order.Len())
+	}
+
+	// Shrinking keeps the most recently used
+	c.configure(time.Hour, 2)
+	for k, want := range map[string]bool{"a": true, "d": true, "b": false, "c": false} {
+		if _, ok := c.get(k); ok != want {
+			t.Errorf("after shrinking, %q cached = %v, want %v", k, ok, want)
+		}
+	}
+
+	// Growing and a new TTL keep everything
+	c.configure(time.Minute, 10)
+	if c.order.Len() != 2 {
+		t.Errorf("%d entries after growing, want 2", c.order.Len())
+	}
+
+	// Disabling empties it
+	c.configure(0, 10)
+	if _, ok := c.get("a"); ok {
+		t.Error("entry kept with the cache disabled")
+	}
+}
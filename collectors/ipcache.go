@@ -0,0 +1,166 @@
+package collectors
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruCache is a size-bounded, TTL-expiring cache keyed by IP string
+type lruCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func newLRUCache(ttl time.Duration, size int) *lruCache {
+	return &lruCache{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// configure replaces the limits. Entries are kept, apart from the least
+// recently used ones beyond a smaller size; they expire on the ttl they
+// were stored with. A ttl or size of 0 disables the cache and empties it.
+func (c *lruCache) configure(ttl time.Duration, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+	c.size = size
+	if ttl <= 0 || size <= 0 {
+		c.order.Init()
+		c.entries = map[string]*list.Element{}
+		return
+	}
+	c.evict()
+}
+
+// evict drops the least recently used entries beyond the size limit.
+// c.mu must be held.
+func (c *lruCache) evict() {
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// get returns a live value for key, refreshing its recency
+func (c *lruCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// put stores a value, evicting the least recently used entries beyond the
+// size limit
+func (c *lruCache) put(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 || c.size <= 0 {
+		return
+	}
+
+	entry := &lruEntry{
+		key:     key,
+		value:   value,
+		expires: time.Now().Add(c.ttl),
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	c.evict()
+}
+
+// purgeExpired drops expired entries; get already ignores them
+func (c *lruCache) purgeExpired(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.order.Back(); elem != nil; {
+		prev := elem.Prev()
+		entry := elem.Value.(*lruEntry)
+		if now.After(entry.expires) {
+			c.order.Remove(elem)
+			delete(c.entries, entry.key)
+		}
+		elem = prev
+	}
+}
+
+// ipLookupResult holds the slow, external parts of an IP lookup
+type ipLookupResult struct {
+	whois *Whois
+	geo   *GeoInfo
+}
+
+var (
+	// ipCache holds whois/GeoIP results. Only public addresses are stored;
+	// private and loopback ones never do those lookups.
+	ipCache = newLRUCache(time.Hour, 1000)
+
+	// hostCache holds reverse DNS names ("" when an address has none)
+	hostCache = newLRUCache(time.Hour, 1000)
+)
+
+// SetIPLookupCache sets how long lookup results are reused and how many
+// addresses are kept. A ttl or size of 0 disables the cache. Cached results
+// survive a change of limits, so a config reload doesn't throw them away.
+func SetIPLookupCache(ttl time.Duration, size int) {
+	ipCache.configure(ttl, size)
+	hostCache.configure(ttl, size)
+}
+
+func getCachedIPLookup(ip string) (ipLookupResult, bool) {
+	if v, ok := ipCache.get(ip); ok {
+		return v.(ipLookupResult), true
+	}
+	return ipLookupResult{}, false
+}
+
+// putCachedIPLookup stores the parts of a lookup that succeeded. A nil part
+// is a timeout or error, not an answer, so it is left out to be retried.
+func putCachedIPLookup(ip string, whois *Whois, geo *GeoInfo) {
+	if whois == nil && geo == nil {
+		return
+	}
+	ipCache.put(ip, ipLookupResult{whois: whois, geo: geo})
+}
+
+// purgeExpiredIPLookups drops expired entries from the IP caches
+func purgeExpiredIPLookups(now time.Time) {
+	ipCache.purgeExpired(now)
+	hostCache.purgeExpired(now)
+}
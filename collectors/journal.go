@@ -0,0 +1,187 @@
+package collectors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JournalEntry is a single journald record as streamed by FollowJournal
+type JournalEntry struct {
+	Unit      string `json:"unit"`
+	Timestamp string `json:"timestamp"`
+	Priority  int    `json:"priority"`
+	PID       int    `json:"pid,omitempty"`
+	Message   string `json:"message"`
+}
+
+// FollowJournal runs journalctl -f for the given units and calls onEntry for
+// each parsed record, starting with the last backlog entries. It blocks
+// until ctx is cancelled (which kills journalctl), journalctl exits, or
+// onEntry returns an error.
+func FollowJournal(ctx context.Context, units []string, backlog int, onEntry func(JournalEntry) error) error {
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		return fmt.Errorf("journalctl not available")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	args := []string{"-f", "--no-pager", "-o", "json", "-n", strconv.Itoa(backlog)}
+	for _, unit := range units {
+		args = append(args, "-u", unit)
+	}
+
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		// journalctl -f never exits on its own: kill it before reaping it,
+		// or Wait blocks forever once onEntry fails
+		cancel()
+		cmd.Wait()
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry, ok := parseJournalLine(scanner.Bytes())
+		if !ok {
+			continue
+		}
+		if err := onEntry(entry); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func parseJournalLine(line []byte) (JournalEntry, bool) {
+	var raw struct {
+		Unit        string          `json:"UNIT"`
+		SystemdUnit string          `json:"_SYSTEMD_UNIT"`
+		Realtime    string          `json:"__REALTIME_TIMESTAMP"`
+		Priority    string          `json:"PRIORITY"`
+		PID         string          `json:"_PID"`
+		Message     json.RawMessage `json:"MESSAGE"`
+	}
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return JournalEntry{}, false
+	}
+
+	entry := JournalEntry{Unit: raw.SystemdUnit}
+	// Messages systemd logs about a unit ("Started nginx") carry UNIT=
+	if raw.Unit != "" {
+		entry.Unit = raw.Unit
+	}
+
+	if usec, err := strconv.ParseInt(raw.Realtime, 10, 64); err == nil {
+		entry.Timestamp = time.UnixMicro(usec).Format(time.RFC3339Nano)
+	}
+	entry.Priority, _ = strconv.Atoi(raw.Priority)
+	entry.PID, _ = strconv.Atoi(raw.PID)
+
+	// MESSAGE is a string, or an array of bytes when it isn't valid UTF-8
+	var msg string
+	if err := json.Unmarshal(raw.Message, &msg); err == nil {
+		entry.Message = msg
+	} else {
+		var b []byte
+		var ints []int
+		if err := json.Unmarshal(raw.Message, &ints); err == nil {
+			for _, i := range ints {
+				b = append(b, byte(i))
+			}
+			entry.Message = strings.ToValidUTF8(string(b), "?")
+		}
+	}
+
+	return entry, true
+}
+
+// journalPriorities are the syslog priority names journalctl -p accepts
+var journalPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// journalTimeRegex matches what journalctl takes for --since/--until:
+// "2024-05-01 10:00:00", "yesterday", "-1h", "@1714557600"...
+var journalTimeRegex = regexp.MustCompile(`^[0-9A-Za-z :.+@-]{1,40}$`)
+
+// maxLogGrep bounds the length of a journalctl -g pattern
+const maxLogGrep = 200
+
+// LogFilter narrows a service log query. Empty fields don't filter.
+type LogFilter struct {
+	Priority string // Name or 0-7, or a range such as "err..warning"
+	Since    string
+	Until    string
+	Grep     string // Pattern matched against the message
+}
+
+// IsZero reports whether f doesn't filter anything
+func (f LogFilter) IsZero() bool {
+	return f == LogFilter{}
+}
+
+// Validate checks that every field is something journalctl understands.
+// Values are passed as --flag=value arguments, never through a shell, so
+// this is about clear errors rather than quoting.
+func (f LogFilter) Validate() error {
+	if f.Priority != "" {
+		for _, p := range strings.SplitN(f.Priority, "..", 2) {
+			if !isJournalPriority(p) {
+				return fmt.Errorf("invalid priority %q", p)
+			}
+		}
+	}
+	if f.Since != "" && !journalTimeRegex.MatchString(f.Since) {
+		return fmt.Errorf("invalid since %q", f.Since)
+	}
+	if f.Until != "" && !journalTimeRegex.MatchString(f.Until) {
+		return fmt.Errorf("invalid until %q", f.Until)
+	}
+	if len(f.Grep) > maxLogGrep {
+		return fmt.Errorf("grep pattern longer than %d characters", maxLogGrep)
+	}
+	if strings.ContainsAny(f.Grep, "\x00\n\r") {
+		return fmt.Errorf("grep pattern contains control characters")
+	}
+	return nil
+}
+
+func isJournalPriority(p string) bool {
+	if n, err := strconv.Atoi(p); err == nil {
+		return n >= 0 && n <= 7
+	}
+	return slices.Contains(journalPriorities, p)
+}
+
+// journalArgs returns the journalctl options for f
+func (f LogFilter) journalArgs() []string {
+	var args []string
+	if f.Priority != "" {
+		args = append(args, "--priority="+f.Priority)
+	}
+	if f.Since != "" {
+		args = append(args, "--since="+f.Since)
+	}
+	if f.Until != "" {
+		args = append(args, "--until="+f.Until)
+	}
+	if f.Grep != "" {
+		args = append(args, "--grep="+f.Grep)
+	}
+	return args
+}
@@ -0,0 +1,51 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFollowJournalStopsOnEntryError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake journalctl is a shell script")
+	}
+
+	// Prints one entry, then follows forever like journalctl -f
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo '{"_SYSTEMD_UNIT":"test.service","PRIORITY":"6","_PID":"42","MESSAGE":"hello","__REALTIME_TIMESTAMP":"1700000000000000"}'
+exec sleep 60
+`
+	if err := os.WriteFile(filepath.Join(dir, "journalctl"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	errGone := errors.New("client went away")
+	var entries []JournalEntry
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowJournal(context.Background(), []string{"test.service"}, 10, func(e JournalEntry) error {
+			entries = append(entries, e)
+			return errGone
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != errGone {
+			t.Errorf("FollowJournal returned %v, want the onEntry error", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("FollowJournal still waiting on journalctl after onEntry failed")
+	}
+
+	if len(entries) != 1 || entries[0].Message != "hello" || entries[0].PID != 42 {
+		t.Errorf("got entries %+v", entries)
+	}
+}
@@ -0,0 +1,32 @@
+package collectors
+
+import "time"
+
+// PurgeExpiredCaches drops cache entries that are past their TTL. Lookups
+// already ignore them, this just releases the memory.
+func PurgeExpiredCaches() {
+	now := time.Now()
+
+	smartCacheMu.Lock()
+	for device, entry := range smartCache {
+		if now.Sub(entry.at) >= smartCacheTTL {
+			delete(smartCache, device)
+		}
+	}
+	smartCacheMu.Unlock()
+
+	inspectAllMu.Lock()
+	if !inspectAllCachedAt.IsZero() && now.Sub(inspectAllCachedAt) >= inspectAllTTL {
+		inspectAllCache = nil
+		inspectAllCachedAt = time.Time{}
+	}
+	inspectAllMu.Unlock()
+
+	purgeExpiredIPLookups(now)
+}
+
+// PurgeStaleSamples removes previous-sample entries (used to compute rates)
+// for processes, disks, interfaces and cores that no longer exist.
+func PurgeStaleSamples() {
+	purgeStaleSamples()
+}
@@ -0,0 +1,8 @@
+//go:build darwin
+
+package collectors
+
+// previousNetworkStats is only touched from GetNetworkInfo without a lock,
+// so it can't be purged safely from here; GetNetworkInfo evicts gone
+// interfaces on each pass instead.
+func purgeStaleSamples() {}
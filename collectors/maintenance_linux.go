@@ -0,0 +1,49 @@
+//go:build linux
+
+package collectors
+
+import (
+	"fmt"
+	"os"
+)
+
+func purgeStaleSamples() {
+	processMutex.Lock()
+	for pid := range previousCPUTicks {
+		if !pathExists(fmt.Sprintf("/proc/%d", pid)) {
+			delete(previousCPUTicks, pid)
+		}
+	}
+	processMutex.Unlock()
+
+	diskMutex.Lock()
+	for device := range previousDiskIO {
+		if !pathExists("/sys/class/block/" + device) {
+			delete(previousDiskIO, device)
+		}
+	}
+	diskMutex.Unlock()
+
+	netMutex.Lock()
+	for iface := range previousNetStats {
+		if !pathExists("/sys/class/net/" + iface) {
+			delete(previousNetStats, iface)
+		}
+	}
+	netMutex.Unlock()
+
+	// Offlined/hot-unplugged cores; -1 is the aggregate "cpu" line
+	cpuMutex.Lock()
+	for coreID := range previousCPUTimes {
+		if coreID >= 0 && !pathExists(fmt.Sprintf("/sys/devices/system/cpu/cpu%d", coreID)) {
+			delete(previousCPUTimes, coreID)
+			delete(previousTotalTimes, coreID)
+		}
+	}
+	cpuMutex.Unlock()
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
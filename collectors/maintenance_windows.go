@@ -0,0 +1,7 @@
+//go:build windows
+
+package collectors
+
+// prevProcCPU is rebuilt from scratch on every process list pass, so
+// there is nothing to purge.
+func purgeStaleSamples() {}
@@ -23,15 +23,18 @@ type MemoryInfo struct {
 }
 
 func GetMemoryInfo() (MemoryInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	info := MemoryInfo{}
 
 	// Get total memory
-	if out, err := exec.Command("sysctl", "-n", "hw.memsize").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.memsize").Output(); err == nil {
 		info.Total, _ = strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
 	}
 
 	// Get memory pressure from vm_stat
-	if out, err := exec.Command("vm_stat").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "vm_stat").Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		var pageSize uint64 = 4096
 		var free, active, inactive, wired, compressed uint64
@@ -75,7 +78,7 @@ func GetMemoryInfo() (MemoryInfo, error) {
 	}
 
 	// Get swap info
-	if out, err := exec.Command("sysctl", "-n", "vm.swapusage").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "vm.swapusage").Output(); err == nil {
 		// Format: total = 2048.00M  used = 1024.00M  free = 1024.00M
 		str := string(out)
 		for _, part := range strings.Split(str, "  ") {
@@ -0,0 +1,346 @@
+package collectors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"os"
+)
+
+// mmdbReader is a minimal reader for MaxMind DB files (GeoLite2/GeoIP2).
+// It supports what lookups need: the metadata, the binary search tree and
+// the data section decoder. See https://maxmind.github.io/MaxMind-DB/
+type mmdbReader struct {
+	buf        []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	treeSize   uint
+	dataStart  uint
+	ipv4Start  uint
+}
+
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// openMMDB reads a .mmdb file into memory and parses its metadata
+func openMMDB(path string) (*mmdbReader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := bytes.LastIndex(buf, mmdbMetadataMarker)
+	if idx == -1 {
+		return nil, fmt.Errorf("%s: not a MaxMind DB file", path)
+	}
+	metaStart := uint(idx + len(mmdbMetadataMarker))
+
+	meta, _, err := decodeMMDB(buf[metaStart:], 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid metadata: %v", path, err)
+	}
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid metadata", path)
+	}
+
+	r := &mmdbReader{buf: buf}
+	r.nodeCount = uint(mmdbUint(m["node_count"]))
+	r.recordSize = uint(mmdbUint(m["record_size"]))
+	r.ipVersion = uint(mmdbUint(m["ip_version"]))
+
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("%s: unsupported record size %d", path, r.recordSize)
+	}
+
+	r.treeSize = r.recordSize * 2 / 8 * r.nodeCount
+	// 16 zero bytes separate the tree from the data section
+	r.dataStart = r.treeSize + 16
+	if r.dataStart > metaStart {
+		return nil, fmt.Errorf("%s: corrupt search tree", path)
+	}
+
+	// IPv4 addresses live under ::/96 in IPv6 databases
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node = r.readRecord(node, 0)
+		}
+		r.ipv4Start = node
+	}
+
+	return r, nil
+}
+
+// readRecord returns the left (bit 0) or right (bit 1) record of a node
+func (r *mmdbReader) readRecord(node uint, bit uint) uint {
+	switch r.recordSize {
+	case 24:
+		off := node*6 + bit*3
+		b := r.buf[off : off+3]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		off := node * 7
+		b := r.buf[off : off+7]
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		off := node*8 + bit*4
+		return uint(binary.BigEndian.Uint32(r.buf[off : off+4]))
+	}
+}
+
+// lookup returns the decoded record for ip, or nil when it's not in the DB
+func (r *mmdbReader) lookup(ip net.IP) (map[string]interface{}, error) {
+	node := uint(0)
+	addr := ip.To16()
+	bits := 128
+
+	if v4 := ip.To4(); v4 != nil {
+		addr = v4
+		bits = 32
+		node = r.ipv4Start
+	} else if r.ipVersion == 4 {
+		return nil, nil
+	}
+
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		bit := uint(addr[i/8]>>(7-uint(i%8))) & 1
+		node = r.readRecord(node, bit)
+	}
+
+	if node <= r.nodeCount {
+		// Equal means "no data"; below means the address ran out of bits
+		return nil, nil
+	}
+
+	offset := node - r.nodeCount - 16
+	if r.dataStart+offset >= uint(len(r.buf)) {
+		return nil, fmt.Errorf("invalid data pointer")
+	}
+
+	value, _, err := decodeMMDB(r.buf[r.dataStart:], offset)
+	if err != nil {
+		return nil, err
+	}
+	record, _ := value.(map[string]interface{})
+	return record, nil
+}
+
+// MaxMind DB data types
+const (
+	mmdbExtended = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBool
+	mmdbFloat
+)
+
+// mmdbMaxDepth bounds the nesting of maps, arrays and pointers, the same
+// limit libmaxminddb uses. A crafted file can point a value back at its
+// own container, which would otherwise recurse until the stack overflows.
+const mmdbMaxDepth = 512
+
+// decodeMMDB decodes the value at offset within section, returning it and
+// the offset just past it. Pointers are relative to the start of section.
+func decodeMMDB(section []byte, offset uint) (interface{}, uint, error) {
+	return decodeMMDBDepth(section, offset, 0)
+}
+
+func decodeMMDBDepth(section []byte, offset uint, depth int) (interface{}, uint, error) {
+	if depth > mmdbMaxDepth {
+		return nil, 0, fmt.Errorf("data nested deeper than %d levels", mmdbMaxDepth)
+	}
+	if offset >= uint(len(section)) {
+		return nil, 0, fmt.Errorf("offset out of range")
+	}
+
+	ctrl := section[offset]
+	offset++
+	typ := uint(ctrl >> 5)
+
+	if typ == mmdbPointer {
+		ss := uint(ctrl>>3) & 0x3
+		vvv := uint(ctrl & 0x7)
+		if offset+ss+1 > uint(len(section)) {
+			return nil, 0, fmt.Errorf("pointer out of range")
+		}
+		b := section[offset : offset+ss+1]
+
+		var ptr uint
+		switch ss {
+		case 0:
+			ptr = vvv<<8 | uint(b[0])
+		case 1:
+			ptr = (vvv<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+		case 2:
+			ptr = (vvv<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+		default:
+			ptr = uint(binary.BigEndian.Uint32(b))
+		}
+
+		// A pointer to a pointer is invalid per the spec
+		if ptr < uint(len(section)) && section[ptr]>>5 == mmdbPointer {
+			return nil, 0, fmt.Errorf("pointer to a pointer")
+		}
+		value, _, err := decodeMMDBDepth(section, ptr, depth+1)
+		return value, offset + ss + 1, err
+	}
+
+	if typ == mmdbExtended {
+		if offset >= uint(len(section)) {
+			return nil, 0, fmt.Errorf("offset out of range")
+		}
+		typ = 7 + uint(section[offset])
+		offset++
+	}
+
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(section)) {
+			return nil, 0, fmt.Errorf("size out of range")
+		}
+		var extra uint
+		for _, c := range section[offset : offset+n] {
+			extra = extra<<8 | uint(c)
+		}
+		offset += n
+		switch size {
+		case 29:
+			size = 29 + extra
+		case 30:
+			size = 285 + extra
+		default:
+			size = 65821 + extra
+		}
+	}
+
+	// Every entry takes at least one byte, so a size larger than what is
+	// left of the section is a lie and must not size an allocation
+	capacity := min(size, uint(len(section))-offset)
+
+	switch typ {
+	case mmdbMap:
+		m := make(map[string]interface{}, capacity)
+		for i := uint(0); i < size; i++ {
+			key, next, err := decodeMMDBDepth(section, offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, after, err := decodeMMDBDepth(section, next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, _ := key.(string)
+			m[k] = value
+			offset = after
+		}
+		return m, offset, nil
+
+	case mmdbArray:
+		arr := make([]interface{}, 0, capacity)
+		for i := uint(0); i < size; i++ {
+			value, next, err := decodeMMDBDepth(section, offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			arr = append(arr, value)
+			offset = next
+		}
+		return arr, offset, nil
+
+	case mmdbBool:
+		return size != 0, offset, nil
+
+	case mmdbContainer, mmdbEndMarker:
+		return nil, offset, nil
+	}
+
+	if offset+size > uint(len(section)) {
+		return nil, 0, fmt.Errorf("value out of range")
+	}
+	b := section[offset : offset+size]
+	offset += size
+
+	switch typ {
+	case mmdbString:
+		return string(b), offset, nil
+	case mmdbBytes:
+		return append([]byte(nil), b...), offset, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid double size")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid float size")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case mmdbInt32:
+		var v uint32
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+		return int64(int32(v)), offset, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, offset, nil
+	case mmdbUint128:
+		// Not used by GeoIP fields; keep the raw bytes
+		return append([]byte(nil), b...), offset, nil
+	}
+
+	return nil, 0, fmt.Errorf("unknown data type %d", typ)
+}
+
+// mmdbUint reads an unsigned value from a decoded record
+func mmdbUint(v interface{}) uint64 {
+	switch n := v.(type) {
+	case uint64:
+		return n
+	case int64:
+		return uint64(n)
+	}
+	return 0
+}
+
+// mmdbPath walks nested maps (and array indexes given as ints) in a decoded record
+func mmdbPath(v interface{}, path ...interface{}) interface{} {
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[key]
+		case int:
+			arr, ok := v.([]interface{})
+			if !ok || key >= len(arr) {
+				return nil
+			}
+			v = arr[key]
+		}
+	}
+	return v
+}
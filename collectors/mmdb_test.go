@@ -0,0 +1,114 @@
+package collectors
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMMDB(t *testing.T) {
+	long := strings.Repeat("x", 30)
+
+	tests := []struct {
+		name string
+		data []byte
+		want interface{}
+	}{
+		{"string", []byte{0x42, 'h', 'i'}, "hi"},
+		{"empty string", []byte{0x40}, ""},
+		{"string with extended size", append([]byte{0x5d, 0x01}, long...), long},
+		{"uint16", []byte{0xa2, 0x01, 0x02}, uint64(258)},
+		{"uint32 zero", []byte{0xc0}, uint64(0)},
+		{"uint32", []byte{0xc3, 0x01, 0x00, 0x00}, uint64(65536)},
+		{"uint64", []byte{0x02, 0x02, 0x01, 0x00}, uint64(256)},
+		{"int32 negative", []byte{0x04, 0x01, 0xff, 0xff, 0xff, 0xff}, int64(-1)},
+		{"bool", []byte{0x01, 0x07}, true},
+		{"double", []byte{0x68, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, 1.5},
+		{"map", []byte{0xe2, 0x41, 'a', 0xa1, 0x01, 0x41, 'b', 0x42, 'o', 'k'},
+			map[string]interface{}{"a": uint64(1), "b": "ok"}},
+		{"array", []byte{0x02, 0x04, 0xa1, 0x01, 0x41, 'b'},
+			[]interface{}{uint64(1), "b"}},
+		{"nested", []byte{0xe1, 0x41, 'n', 0x01, 0x04, 0xe1, 0x41, 'k', 0x40},
+			map[string]interface{}{"n": []interface{}{map[string]interface{}{"k": ""}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, next, err := decodeMMDB(tt.data, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+			if next != uint(len(tt.data)) {
+				t.Errorf("next offset %d, want %d", next, len(tt.data))
+			}
+		})
+	}
+}
+
+func TestDecodeMMDBPointers(t *testing.T) {
+	// "hello" at 0, then a map at 6 whose values point back at it, once
+	// with the 1-byte form (ss=0) and once with the 4-byte form (ss=3)
+	section := []byte{0x45, 'h', 'e', 'l', 'l', 'o'}
+	mapStart := uint(len(section))
+	section = append(section,
+		0xe2,
+		0x41, 'a', 0x20, 0x00,
+		0x41, 'b', 0x38, 0x00, 0x00, 0x00, 0x00,
+	)
+
+	got, next, err := decodeMMDB(section, mapStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"a": "hello", "b": "hello"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+	// The offset continues after the pointer, not after what it points to
+	if next != uint(len(section)) {
+		t.Errorf("next offset %d, want %d", next, len(section))
+	}
+}
+
+func TestDecodeMMDBErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		// A map whose value points back at the map itself
+		{"cyclic pointer", []byte{0xe1, 0x41, 'k', 0x20, 0x00}},
+		{"pointer to a pointer", []byte{0x20, 0x02, 0x20, 0x00}},
+		{"pointer out of range", []byte{0x20, 0x50}},
+		{"truncated pointer", []byte{0x38, 0x00}},
+		{"truncated string", []byte{0x45, 'h'}},
+		{"truncated map", []byte{0xe2, 0x41, 'a', 0xa1, 0x01}},
+		// Claims 16M entries with nothing behind them
+		{"oversized map", []byte{0xff, 0xff, 0xff, 0xff}},
+		{"invalid double size", []byte{0x64, 0, 0, 0, 0}},
+		{"empty", []byte{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, _, err := decodeMMDB(tt.data, 0); err == nil {
+				t.Errorf("decoded %#v, want an error", v)
+			}
+		})
+	}
+}
+
+func TestDecodeMMDBDepthLimit(t *testing.T) {
+	// mmdbMaxDepth arrays of one element each, around an empty string
+	nested := func(levels int) []byte {
+		return append(bytes.Repeat([]byte{0x01, 0x04}, levels), 0x40)
+	}
+
+	if _, _, err := decodeMMDB(nested(mmdbMaxDepth), 0); err != nil {
+		t.Errorf("%d levels: %v", mmdbMaxDepth, err)
+	}
+	if _, _, err := decodeMMDB(nested(mmdbMaxDepth+1), 0); err == nil {
+		t.Errorf("%d levels decoded, want an error", mmdbMaxDepth+1)
+	}
+}
@@ -0,0 +1,71 @@
+package collectors
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRoute is a default gateway and the interface it is reached through
+type DefaultRoute struct {
+	Interface string `json:"interface"`
+	Gateway   string `json:"gateway"`
+	Family    string `json:"family"` // ipv4 or ipv6
+}
+
+// netConfig is the host's routing and resolver setup reported alongside the
+// interfaces
+type netConfig struct {
+	gateways      []DefaultRoute
+	dnsServers    []string
+	searchDomains []string
+}
+
+// netConfigTTL is how long the gateways and DNS servers are reused. They
+// rarely change, and on macOS and Windows reading them runs a command on
+// every network refresh otherwise.
+const netConfigTTL = 15 * time.Second
+
+var (
+	netConfigCache   netConfig
+	netConfigCacheAt time.Time
+	netConfigMu      sync.Mutex
+)
+
+// cachedNetConfig returns readNetConfig's result, at most netConfigTTL old
+func cachedNetConfig() netConfig {
+	netConfigMu.Lock()
+	defer netConfigMu.Unlock()
+	if time.Since(netConfigCacheAt) > netConfigTTL {
+		netConfigCache = readNetConfig()
+		netConfigCacheAt = time.Now()
+	}
+	return netConfigCache
+}
+
+// readResolvConf returns the nameserver and search entries of a
+// resolv.conf file
+func readResolvConf(path string) (servers, search []string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			servers = append(servers, fields[1])
+		case "search", "domain":
+			search = append(search, fields[1:]...)
+		}
+	}
+	return servers, search
+}
@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"fmt"
 	"net"
 	"os/exec"
 	"strconv"
@@ -11,14 +12,18 @@ import (
 )
 
 type NetworkInterface struct {
-	Name        string   `json:"name"`
-	IPAddresses []string `json:"ipAddresses"`
-	IsUp        bool     `json:"isUp"`
-	IsLoopback  bool     `json:"isLoopback"`
-	RxBytes     uint64   `json:"rxBytes"`
-	TxBytes     uint64   `json:"txBytes"`
-	RxSpeed     uint64   `json:"rxSpeed"`
-	TxSpeed     uint64   `json:"txSpeed"`
+	Name        string             `json:"name"`
+	IPAddresses []string           `json:"ipAddresses"`
+	Addresses   []InterfaceAddress `json:"addresses"`
+	IsUp        bool               `json:"isUp"`
+	IsLoopback  bool               `json:"isLoopback"`
+	Type        string             `json:"type"`
+	RxBytes     uint64             `json:"rxBytes"`
+	TxBytes     uint64             `json:"txBytes"`
+	RxSpeed     uint64             `json:"rxSpeed"`
+	TxSpeed     uint64             `json:"txSpeed"`
+	HasIPv4     bool               `json:"hasIpv4"`
+	HasIPv6     bool               `json:"hasIpv6"`
 }
 
 type NetworkInfo struct {
@@ -27,6 +32,14 @@ type NetworkInfo struct {
 	TotalTxBytes uint64             `json:"totalTxBytes"`
 	TotalRxSpeed uint64             `json:"totalRxSpeed"`
 	TotalTxSpeed uint64             `json:"totalTxSpeed"`
+	// Interfaces (excluding loopback) by address family
+	IPv4Only  int `json:"ipv4Only"`
+	IPv6Only  int `json:"ipv6Only"`
+	DualStack int `json:"dualStack"`
+	// Routing and resolver configuration
+	Gateways      []DefaultRoute `json:"gateways"`
+	DNSServers    []string       `json:"dnsServers"`
+	SearchDomains []string       `json:"searchDomains,omitempty"`
 }
 
 var previousNetworkStats map[string]struct {
@@ -36,6 +49,9 @@ var previousNetworkStats map[string]struct {
 }
 
 func GetNetworkInfo() (NetworkInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	if previousNetworkStats == nil {
 		previousNetworkStats = make(map[string]struct {
 			rxBytes uint64
@@ -55,7 +71,7 @@ func GetNetworkInfo() (NetworkInfo, error) {
 
 	// Get stats from netstat
 	statsMap := make(map[string]struct{ rx, tx uint64 })
-	if out, err := exec.Command("netstat", "-ibn").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "netstat", "-ibn").Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		for _, line := range lines {
 			fields := strings.Fields(line)
@@ -76,11 +92,19 @@ func GetNetworkInfo() (NetworkInfo, error) {
 		}
 	}
 
+	// Forget interfaces that are gone (utun tunnels come and go)
+	for name := range previousNetworkStats {
+		if _, ok := statsMap[name]; !ok {
+			delete(previousNetworkStats, name)
+		}
+	}
+
 	for _, iface := range interfaces {
 		ni := NetworkInterface{
 			Name:       iface.Name,
 			IsUp:       iface.Flags&net.FlagUp != 0,
 			IsLoopback: iface.Flags&net.FlagLoopback != 0,
+			Type:       classifyInterfaceName(iface.Name),
 		}
 
 		// Get IP addresses
@@ -88,6 +112,7 @@ func GetNetworkInfo() (NetworkInfo, error) {
 		for _, addr := range addrs {
 			ni.IPAddresses = append(ni.IPAddresses, addr.String())
 		}
+		ni.Addresses = interfaceAddresses(addrs)
 
 		// Get stats
 		if stats, ok := statsMap[iface.Name]; ok {
@@ -119,8 +144,84 @@ func GetNetworkInfo() (NetworkInfo, error) {
 			info.TotalTxSpeed += ni.TxSpeed
 		}
 
+		ni.HasIPv4, ni.HasIPv6 = addressFamilies(ni.IPAddresses)
+		if !ni.IsLoopback {
+			info.countStack(ni.HasIPv4, ni.HasIPv6)
+		}
+
 		info.Interfaces = append(info.Interfaces, ni)
 	}
 
+	cfg := cachedNetConfig()
+	info.Gateways = cfg.gateways
+	info.DNSServers = cfg.dnsServers
+	info.SearchDomains = cfg.searchDomains
+
 	return info, nil
 }
+
+func setInterfaceState(name string, up bool) error {
+	state := "down"
+	if up {
+		state = "up"
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx, "ifconfig", name, state).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// readNetConfig collects the default routes from route(8) and the DNS
+// servers from /etc/resolv.conf, which macOS keeps in sync with the
+// primary resolver
+func readNetConfig() netConfig {
+	cfg := netConfig{gateways: []DefaultRoute{}}
+	for _, family := range []string{"ipv4", "ipv6"} {
+		args := []string{"-n", "get", "default"}
+		if family == "ipv6" {
+			args = []string{"-n", "get", "-inet6", "default"}
+		}
+		if route, ok := readDarwinDefaultRoute(args); ok {
+			route.Family = family
+			cfg.gateways = append(cfg.gateways, route)
+		}
+	}
+
+	cfg.dnsServers, cfg.searchDomains = readResolvConf("/etc/resolv.conf")
+	if cfg.dnsServers == nil {
+		cfg.dnsServers = []string{}
+	}
+	return cfg
+}
+
+// readDarwinDefaultRoute parses the "gateway:" and "interface:" lines of
+// route get. Routes without a gateway (e.g. over a point-to-point VPN)
+// are skipped.
+func readDarwinDefaultRoute(args []string) (DefaultRoute, bool) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "route", args...).Output()
+	if err != nil {
+		return DefaultRoute{}, false
+	}
+
+	var route DefaultRoute
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "gateway":
+			route.Gateway = strings.TrimSpace(value)
+		case "interface":
+			route.Interface = strings.TrimSpace(value)
+		}
+	}
+	return route, route.Gateway != ""
+}
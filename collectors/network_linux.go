@@ -4,24 +4,39 @@ package collectors
 
 import (
 	"bufio"
+	"encoding/hex"
+	"fmt"
 	"net"
 	"os"
+	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 )
 
 type NetworkInterface struct {
-	Name        string   `json:"name"`
-	IPAddresses []string `json:"ipAddresses"`
-	MAC         string   `json:"mac"`
-	RxBytes     uint64   `json:"rxBytes"`
-	TxBytes     uint64   `json:"txBytes"`
-	RxSpeed     uint64   `json:"rxSpeed"`
-	TxSpeed     uint64   `json:"txSpeed"`
-	RxPackets   uint64   `json:"rxPackets"`
-	TxPackets   uint64   `json:"txPackets"`
-	IsUp        bool     `json:"isUp"`
+	Name        string             `json:"name"`
+	IPAddresses []string           `json:"ipAddresses"`
+	Addresses   []InterfaceAddress `json:"addresses"`
+	MAC         string             `json:"mac"`
+	Gateway     string             `json:"gateway,omitempty"`   // Default IPv4 route through this interface
+	GatewayV6   string             `json:"gatewayV6,omitempty"` // Default IPv6 route through this interface
+	Type        string             `json:"type"`
+	RxBytes     uint64             `json:"rxBytes"`
+	TxBytes     uint64             `json:"txBytes"`
+	RxSpeed     uint64             `json:"rxSpeed"`
+	TxSpeed     uint64             `json:"txSpeed"`
+	RxPackets   uint64             `json:"rxPackets"`
+	TxPackets   uint64             `json:"txPackets"`
+	IsUp        bool               `json:"isUp"`
+	HasIPv4     bool               `json:"hasIpv4"`
+	HasIPv6     bool               `json:"hasIpv6"`
+	// Wireless details, zero for wired interfaces
+	Wireless    bool   `json:"wireless,omitempty"`
+	SSID        string `json:"ssid,omitempty"`
+	SignalDBm   int    `json:"signalDbm,omitempty"`
+	LinkQuality int    `json:"linkQuality,omitempty"` // As reported by the driver, usually out of 70
 }
 
 type NetworkInfo struct {
@@ -30,6 +45,14 @@ type NetworkInfo struct {
 	TotalTxBytes uint64             `json:"totalTxBytes"`
 	TotalRxSpeed uint64             `json:"totalRxSpeed"`
 	TotalTxSpeed uint64             `json:"totalTxSpeed"`
+	// Interfaces (excluding loopback) by address family
+	IPv4Only  int `json:"ipv4Only"`
+	IPv6Only  int `json:"ipv6Only"`
+	DualStack int `json:"dualStack"`
+	// Routing and resolver configuration
+	Gateways      []DefaultRoute `json:"gateways"`
+	DNSServers    []string       `json:"dnsServers"`
+	SearchDomains []string       `json:"searchDomains,omitempty"`
 }
 
 var previousNetStats map[string]NetworkInterface
@@ -94,8 +117,20 @@ func GetNetworkInfo() (*NetworkInfo, error) {
 				txPackets uint64
 			}{rxBytes, txBytes, rxPackets, txPackets}
 		}
+
+		// Forget interfaces that are gone (VPN tunnels, veths, ...)
+		netMutex.Lock()
+		for name := range previousNetStats {
+			if _, exists := netStats[name]; !exists {
+				delete(previousNetStats, name)
+			}
+		}
+		netMutex.Unlock()
 	}
 
+	gateways := readDefaultGateways()
+	gatewaysV6 := readDefaultGatewaysV6()
+
 	for _, iface := range ifaces {
 		// Skip loopback
 		if iface.Flags&net.FlagLoopback != 0 {
@@ -116,6 +151,15 @@ func GetNetworkInfo() (*NetworkInfo, error) {
 				ni.IPAddresses = append(ni.IPAddresses, addr.String())
 			}
 		}
+		ni.Addresses = interfaceAddresses(addrs)
+		ni.Gateway = gateways[iface.Name]
+		ni.GatewayV6 = gatewaysV6[iface.Name]
+
+		ni.Type = classifyInterface(iface.Name)
+		if ni.Type == InterfaceWireless {
+			ni.Wireless = true
+			fillWirelessInfo(&ni)
+		}
 
 		// Get stats
 		if stats, exists := netStats[iface.Name]; exists {
@@ -144,8 +188,196 @@ func GetNetworkInfo() (*NetworkInfo, error) {
 			info.TotalTxSpeed += ni.TxSpeed
 		}
 
+		ni.HasIPv4, ni.HasIPv6 = addressFamilies(ni.IPAddresses)
+		info.countStack(ni.HasIPv4, ni.HasIPv6)
+
 		info.Interfaces = append(info.Interfaces, ni)
 	}
 
+	cfg := cachedNetConfig()
+	info.Gateways = cfg.gateways
+	info.DNSServers = cfg.dnsServers
+	info.SearchDomains = cfg.searchDomains
+
 	return info, nil
 }
+
+// fillWirelessInfo reads link quality and signal from /proc/net/wireless,
+// then SSID (and a more precise signal) from iw when it is installed
+// classifyInterface derives the interface type from sysfs, falling back to
+// name prefixes for anything sysfs doesn't settle
+func classifyInterface(name string) string {
+	base := "/sys/class/net/" + name
+
+	// ARPHRD_* values from if_arp.h
+	arpType := 0
+	if data, err := os.ReadFile(base + "/type"); err == nil {
+		arpType, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+
+	switch {
+	case arpType == 772:
+		return InterfaceLoopback
+	case pathExists(base + "/wireless"):
+		return InterfaceWireless
+	case pathExists(base + "/bridge"):
+		return InterfaceBridge
+	case pathExists(base + "/tun_flags"):
+		return InterfaceTunnel
+	case arpType == 65534, arpType == 768, arpType == 769, arpType == 776, arpType == 778, arpType == 823:
+		// none (wireguard), ipip, ip6ip6, sit, gre, ip6gre
+		return InterfaceTunnel
+	}
+
+	kind := classifyInterfaceName(name)
+	if kind == InterfaceEthernet && pathExists("/sys/devices/virtual/net/"+name) {
+		// No backing device, e.g. dummy, macvlan or vxlan
+		return InterfaceVirtual
+	}
+	return kind
+}
+
+func fillWirelessInfo(ni *NetworkInterface) {
+	if data, err := os.ReadFile("/proc/net/wireless"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			// wlan0: 0000   70.  -40.  -256  ...
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) != ni.Name {
+				continue
+			}
+			fields := strings.Fields(parts[1])
+			if len(fields) >= 3 {
+				quality, _ := strconv.ParseFloat(strings.TrimSuffix(fields[1], "."), 64)
+				level, _ := strconv.ParseFloat(strings.TrimSuffix(fields[2], "."), 64)
+				ni.LinkQuality = int(quality)
+				ni.SignalDBm = int(level)
+			}
+			break
+		}
+	}
+
+	if _, err := exec.LookPath("iw"); err != nil {
+		return
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "iw", "dev", ni.Name, "link").Output()
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "SSID:") {
+			ni.SSID = strings.TrimSpace(strings.TrimPrefix(line, "SSID:"))
+		} else if strings.HasPrefix(line, "signal:") {
+			// signal: -40 dBm
+			fields := strings.Fields(strings.TrimPrefix(line, "signal:"))
+			if len(fields) > 0 {
+				if dbm, err := strconv.Atoi(fields[0]); err == nil {
+					ni.SignalDBm = dbm
+				}
+			}
+		}
+	}
+}
+
+func setInterfaceState(name string, up bool) error {
+	state := "down"
+	if up {
+		state = "up"
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx, "ip", "link", "set", "dev", name, state).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// readDefaultGateways maps each interface to the gateway of its default
+// IPv4 route, from /proc/net/route. Addresses there are little-endian hex.
+func readDefaultGateways() map[string]string {
+	gateways := make(map[string]string)
+	data, err := os.ReadFile("/proc/net/route")
+	if err != nil {
+		return gateways
+	}
+
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+		fields := strings.Fields(line)
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		gw, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil || gw == 0 {
+			continue
+		}
+		if _, exists := gateways[fields[0]]; !exists {
+			gateways[fields[0]] = net.IPv4(byte(gw), byte(gw>>8), byte(gw>>16), byte(gw>>24)).String()
+		}
+	}
+	return gateways
+}
+
+// readDefaultGatewaysV6 is readDefaultGateways for /proc/net/ipv6_route,
+// where addresses are plain 32-digit hex
+func readDefaultGatewaysV6() map[string]string {
+	gateways := make(map[string]string)
+	data, err := os.ReadFile("/proc/net/ipv6_route")
+	if err != nil {
+		return gateways
+	}
+
+	const zero = "00000000000000000000000000000000"
+	for _, line := range strings.Split(string(data), "\n") {
+		// dest dest_len src src_len next_hop metric refcnt use flags iface
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[0] != zero || fields[1] != "00" || fields[4] == zero {
+			continue
+		}
+		hop, err := hex.DecodeString(fields[4])
+		if err != nil || len(hop) != net.IPv6len {
+			continue
+		}
+		if _, exists := gateways[fields[9]]; !exists {
+			gateways[fields[9]] = net.IP(hop).String()
+		}
+	}
+	return gateways
+}
+
+// readNetConfig collects the default routes and the resolver setup. With
+// systemd-resolved, resolv.conf only lists its local stub, so the upstream
+// servers are read from the file it keeps for that purpose.
+func readNetConfig() netConfig {
+	cfg := netConfig{gateways: []DefaultRoute{}}
+	for family, gateways := range map[string]map[string]string{"ipv4": readDefaultGateways(), "ipv6": readDefaultGatewaysV6()} {
+		for iface, gw := range gateways {
+			cfg.gateways = append(cfg.gateways, DefaultRoute{Interface: iface, Gateway: gw, Family: family})
+		}
+	}
+	sort.Slice(cfg.gateways, func(i, j int) bool {
+		a, b := cfg.gateways[i], cfg.gateways[j]
+		if a.Family != b.Family {
+			return a.Family < b.Family
+		}
+		return a.Interface < b.Interface
+	})
+
+	cfg.dnsServers, cfg.searchDomains = readResolvConf("/etc/resolv.conf")
+	if len(cfg.dnsServers) == 1 && cfg.dnsServers[0] == "127.0.0.53" {
+		if upstream, _ := readResolvConf("/run/systemd/resolve/resolv.conf"); len(upstream) > 0 {
+			cfg.dnsServers = upstream
+		}
+	}
+	if cfg.dnsServers == nil {
+		cfg.dnsServers = []string{}
+	}
+	return cfg
+}
@@ -3,7 +3,10 @@
 package collectors
 
 import (
+	"fmt"
 	"net"
+	"os/exec"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,14 +14,18 @@ import (
 )
 
 type NetworkInterface struct {
-	Name        string   `json:"name"`
-	IPAddresses []string `json:"ipAddresses"`
-	IsUp        bool     `json:"isUp"`
-	IsLoopback  bool     `json:"isLoopback"`
-	RxBytes     uint64   `json:"rxBytes"`
-	TxBytes     uint64   `json:"txBytes"`
-	RxSpeed     uint64   `json:"rxSpeed"`
-	TxSpeed     uint64   `json:"txSpeed"`
+	Name        string             `json:"name"`
+	IPAddresses []string           `json:"ipAddresses"`
+	Addresses   []InterfaceAddress `json:"addresses"`
+	IsUp        bool               `json:"isUp"`
+	IsLoopback  bool               `json:"isLoopback"`
+	Type        string             `json:"type"`
+	RxBytes     uint64             `json:"rxBytes"`
+	TxBytes     uint64             `json:"txBytes"`
+	RxSpeed     uint64             `json:"rxSpeed"`
+	TxSpeed     uint64             `json:"txSpeed"`
+	HasIPv4     bool               `json:"hasIpv4"`
+	HasIPv6     bool               `json:"hasIpv6"`
 }
 
 type NetworkInfo struct {
@@ -27,6 +34,14 @@ type NetworkInfo struct {
 	TotalTxBytes uint64             `json:"totalTxBytes"`
 	TotalRxSpeed uint64             `json:"totalRxSpeed"`
 	TotalTxSpeed uint64             `json:"totalTxSpeed"`
+	// Interfaces (excluding loopback) by address family
+	IPv4Only  int `json:"ipv4Only"`
+	IPv6Only  int `json:"ipv6Only"`
+	DualStack int `json:"dualStack"`
+	// Routing and resolver configuration
+	Gateways      []DefaultRoute `json:"gateways"`
+	DNSServers    []string       `json:"dnsServers"`
+	SearchDomains []string       `json:"searchDomains,omitempty"`
 }
 
 type netIOSnapshot struct {
@@ -64,15 +79,20 @@ func GetNetworkInfo() (NetworkInfo, error) {
 			Name:    s.Name,
 			RxBytes: s.BytesRecv,
 			TxBytes: s.BytesSent,
+			Type:    classifyInterfaceName(s.Name),
 		}
 
 		if ifc, ok := ifMap[s.Name]; ok {
 			ni.IsUp = ifc.Flags&net.FlagUp != 0
 			ni.IsLoopback = ifc.Flags&net.FlagLoopback != 0
+			if ni.IsLoopback {
+				ni.Type = InterfaceLoopback
+			}
 			if addrs, err := ifc.Addrs(); err == nil {
 				for _, addr := range addrs {
 					ni.IPAddresses = append(ni.IPAddresses, addr.String())
 				}
+				ni.Addresses = interfaceAddresses(addrs)
 			}
 		}
 
@@ -97,8 +117,65 @@ func GetNetworkInfo() (NetworkInfo, error) {
 			info.TotalTxSpeed += ni.TxSpeed
 		}
 
+		ni.HasIPv4, ni.HasIPv6 = addressFamilies(ni.IPAddresses)
+		if !ni.IsLoopback {
+			info.countStack(ni.HasIPv4, ni.HasIPv6)
+		}
+
 		info.Interfaces = append(info.Interfaces, ni)
 	}
 
+	cfg := cachedNetConfig()
+	info.Gateways = cfg.gateways
+	info.DNSServers = cfg.dnsServers
+	info.SearchDomains = cfg.searchDomains
+
 	return info, nil
 }
+
+func setInterfaceState(name string, up bool) error {
+	state := "disabled"
+	if up {
+		state = "enabled"
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx, "netsh", "interface", "set", "interface", "name="+name, "admin="+state).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// readNetConfig collects the default routes and DNS servers with one
+// PowerShell call. On-link routes (next hop 0.0.0.0 or ::) have no
+// gateway and are skipped.
+func readNetConfig() netConfig {
+	cfg := netConfig{gateways: []DefaultRoute{}, dnsServers: []string{}}
+
+	script := `Get-NetRoute -DestinationPrefix '0.0.0.0/0','::/0' -ErrorAction SilentlyContinue | ForEach-Object { "route|$($_.InterfaceAlias)|$($_.NextHop)|$($_.AddressFamily)" }
+Get-DnsClientServerAddress -ErrorAction SilentlyContinue | ForEach-Object { $_.ServerAddresses } | Select-Object -Unique | ForEach-Object { "dns|$_" }`
+	output, err := runPowerShell(script)
+	if err != nil {
+		return cfg
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		switch {
+		case len(parts) == 4 && parts[0] == "route":
+			if parts[2] == "0.0.0.0" || parts[2] == "::" {
+				continue
+			}
+			family := "ipv4"
+			if parts[3] == "IPv6" {
+				family = "ipv6"
+			}
+			cfg.gateways = append(cfg.gateways, DefaultRoute{Interface: parts[1], Gateway: parts[2], Family: family})
+		case len(parts) == 2 && parts[0] == "dns":
+			cfg.dnsServers = append(cfg.dnsServers, parts[1])
+		}
+	}
+	return cfg
+}
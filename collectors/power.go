@@ -0,0 +1,31 @@
+package collectors
+
+import "time"
+
+// Battery is one battery or UPS reported by the OS
+type Battery struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"` // charging, discharging, full, not charging, unknown
+	Percent float64 `json:"percent"`
+	// Energy in Wh and draw in W, when the platform reports them
+	EnergyNow  float64 `json:"energyNow,omitempty"`
+	EnergyFull float64 `json:"energyFull,omitempty"`
+	PowerNow   float64 `json:"powerNow,omitempty"`
+	// Time until empty while discharging, or until full while charging
+	TimeRemaining        string `json:"timeRemaining,omitempty"`
+	TimeRemainingSeconds int64  `json:"timeRemainingSeconds,omitempty"`
+}
+
+type PowerInfo struct {
+	Available bool      `json:"available"` // At least one battery was found
+	OnAC      bool      `json:"onAc"`
+	Batteries []Battery `json:"batteries"`
+}
+
+func (b *Battery) setTimeRemaining(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	b.TimeRemainingSeconds = int64(d.Seconds())
+	b.TimeRemaining = formatCronDuration(d)
+}
@@ -0,0 +1,60 @@
+//go:build darwin
+
+package collectors
+
+import (
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pmset -g batt prints e.g.
+//
+//	Now drawing from 'AC Power'
+//	 -InternalBattery-0 (id=4653155)	85%; charging; 1:23 remaining present: true
+var pmsetBatteryRegex = regexp.MustCompile(`^\s*-(\S+).*?\t(\d+)%;\s*([^;]+);\s*(?:(\d+):(\d+) remaining)?`)
+
+// GetPowerInfo parses pmset's battery report
+func GetPowerInfo() (PowerInfo, error) {
+	info := PowerInfo{Batteries: []Battery{}}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "pmset", "-g", "batt").Output()
+	if err != nil {
+		return info, nil
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "Now drawing from") {
+			info.OnAC = strings.Contains(line, "AC Power")
+			continue
+		}
+
+		m := pmsetBatteryRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		bat := Battery{Name: m[1], Status: strings.TrimSpace(m[3])}
+		bat.Percent, _ = strconv.ParseFloat(m[2], 64)
+		switch bat.Status {
+		case "charged", "finishing charge":
+			bat.Status = "full"
+		case "AC attached":
+			bat.Status = "not charging"
+		}
+		if m[4] != "" {
+			hours, _ := strconv.Atoi(m[4])
+			minutes, _ := strconv.Atoi(m[5])
+			bat.setTimeRemaining(time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute)
+		}
+		info.Batteries = append(info.Batteries, bat)
+	}
+
+	info.Available = len(info.Batteries) > 0
+	return info, nil
+}
@@ -0,0 +1,106 @@
+//go:build linux
+
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const powerSupplyPath = "/sys/class/power_supply"
+
+// GetPowerInfo reads batteries and AC adapters from sysfs. Values there are
+// in µWh/µW, or µAh/µA/µV on batteries that only report charge.
+func GetPowerInfo() (PowerInfo, error) {
+	info := PowerInfo{Batteries: []Battery{}}
+
+	entries, err := os.ReadDir(powerSupplyPath)
+	if err != nil {
+		return info, nil // No power_supply class, e.g. in a container
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(powerSupplyPath, entry.Name())
+		switch readSysfsString(dir, "type") {
+		case "Mains":
+			if readSysfsString(dir, "online") == "1" {
+				info.OnAC = true
+			}
+		case "Battery":
+			// Peripheral batteries (mice, keyboards) have scope=Device
+			if readSysfsString(dir, "scope") == "Device" {
+				continue
+			}
+			if readSysfsString(dir, "present") == "0" {
+				continue
+			}
+			info.Batteries = append(info.Batteries, readBattery(entry.Name(), dir))
+		}
+	}
+
+	info.Available = len(info.Batteries) > 0
+	return info, nil
+}
+
+func readBattery(name, dir string) Battery {
+	bat := Battery{
+		Name:   name,
+		Status: strings.ToLower(readSysfsString(dir, "status")),
+	}
+	if bat.Status == "" {
+		bat.Status = "unknown"
+	}
+
+	energyNow := readSysfsFloat(dir, "energy_now")
+	energyFull := readSysfsFloat(dir, "energy_full")
+	powerNow := readSysfsFloat(dir, "power_now")
+
+	// Charge-based batteries: convert with the current voltage
+	if energyNow == 0 {
+		voltage := readSysfsFloat(dir, "voltage_now") / 1e6
+		energyNow = readSysfsFloat(dir, "charge_now") * voltage
+		energyFull = readSysfsFloat(dir, "charge_full") * voltage
+		if powerNow == 0 {
+			powerNow = readSysfsFloat(dir, "current_now") * voltage
+		}
+	}
+
+	bat.EnergyNow = energyNow / 1e6
+	bat.EnergyFull = energyFull / 1e6
+	bat.PowerNow = powerNow / 1e6
+
+	if capacity := readSysfsString(dir, "capacity"); capacity != "" {
+		bat.Percent, _ = strconv.ParseFloat(capacity, 64)
+	} else if bat.EnergyFull > 0 {
+		bat.Percent = bat.EnergyNow / bat.EnergyFull * 100
+	}
+
+	if bat.PowerNow > 0 {
+		var hours float64
+		switch bat.Status {
+		case "discharging":
+			hours = bat.EnergyNow / bat.PowerNow
+		case "charging":
+			hours = (bat.EnergyFull - bat.EnergyNow) / bat.PowerNow
+		}
+		bat.setTimeRemaining(time.Duration(hours * float64(time.Hour)))
+	}
+
+	return bat
+}
+
+func readSysfsString(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readSysfsFloat(dir, name string) float64 {
+	v, _ := strconv.ParseFloat(readSysfsString(dir, name), 64)
+	return v
+}
@@ -0,0 +1,61 @@
+//go:build windows
+
+package collectors
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Win32_Battery reports EstimatedRunTime as this value while on AC power
+const batteryRunTimeUnknown = 71582788
+
+// GetPowerInfo queries Win32_Battery. Desktops and servers without a
+// battery (or UPS exposed as one) return no rows.
+func GetPowerInfo() (PowerInfo, error) {
+	info := PowerInfo{Batteries: []Battery{}}
+
+	script := `Get-CimInstance Win32_Battery | ForEach-Object {
+		"$($_.DeviceID)|$($_.EstimatedChargeRemaining)|$($_.BatteryStatus)|$($_.EstimatedRunTime)"
+	}`
+	output, err := runPowerShell(script)
+	if err != nil {
+		return info, nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) < 4 {
+			continue
+		}
+
+		bat := Battery{Name: parts[0], Status: "unknown"}
+		bat.Percent, _ = strconv.ParseFloat(parts[1], 64)
+
+		// BatteryStatus: 1 discharging, 2 on AC, 3 fully charged,
+		// 6-9 charging, see the Win32_Battery documentation
+		status, _ := strconv.Atoi(parts[2])
+		switch status {
+		case 1:
+			bat.Status = "discharging"
+		case 2:
+			bat.Status = "not charging"
+			info.OnAC = true
+		case 3:
+			bat.Status = "full"
+			info.OnAC = true
+		case 6, 7, 8, 9:
+			bat.Status = "charging"
+			info.OnAC = true
+		}
+
+		if minutes, err := strconv.Atoi(parts[3]); err == nil && minutes != batteryRunTimeUnknown && bat.Status == "discharging" {
+			bat.setTimeRemaining(time.Duration(minutes) * time.Minute)
+		}
+		info.Batteries = append(info.Batteries, bat)
+	}
+
+	info.Available = len(info.Batteries) > 0
+	return info, nil
+}
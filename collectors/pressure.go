@@ -0,0 +1,25 @@
+package collectors
+
+// PressureStall is one line of a PSI file: the share of wall time in which
+// some (or all) runnable tasks were stalled on the resource, averaged over
+// 10s, 60s and 300s, plus the total stall time in microseconds.
+type PressureStall struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	Total  uint64  `json:"total"`
+}
+
+// PressureResource holds the "some" and "full" lines for one resource.
+// The kernel reports no "full" line for CPU on older versions.
+type PressureResource struct {
+	Some *PressureStall `json:"some,omitempty"`
+	Full *PressureStall `json:"full,omitempty"`
+}
+
+type PressureInfo struct {
+	Available bool              `json:"available"`
+	CPU       *PressureResource `json:"cpu,omitempty"`
+	Memory    *PressureResource `json:"memory,omitempty"`
+	IO        *PressureResource `json:"io,omitempty"`
+}
@@ -0,0 +1,69 @@
+//go:build linux
+
+package collectors
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetPressureInfo reads the pressure stall information from /proc/pressure.
+// Kernels without PSI (or with it disabled via psi=0) report unavailable.
+func GetPressureInfo() (PressureInfo, error) {
+	info := PressureInfo{}
+
+	info.CPU = readPressureFile("/proc/pressure/cpu")
+	info.Memory = readPressureFile("/proc/pressure/memory")
+	info.IO = readPressureFile("/proc/pressure/io")
+	info.Available = info.CPU != nil || info.Memory != nil || info.IO != nil
+
+	return info, nil
+}
+
+// readPressureFile parses lines like
+// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPressureFile(path string) *PressureResource {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	res := &PressureResource{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		stall := &PressureStall{}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "avg10":
+				stall.Avg10, _ = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stall.Avg60, _ = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stall.Avg300, _ = strconv.ParseFloat(value, 64)
+			case "total":
+				stall.Total, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+
+		switch fields[0] {
+		case "some":
+			res.Some = stall
+		case "full":
+			res.Full = stall
+		}
+	}
+
+	if res.Some == nil && res.Full == nil {
+		return nil
+	}
+	return res
+}
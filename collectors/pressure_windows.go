@@ -0,0 +1,9 @@
+//go:build windows
+
+package collectors
+
+// GetPressureInfo reports PSI as unavailable: pressure stall information is
+// a Linux kernel feature
+func GetPressureInfo() (PressureInfo, error) {
+	return PressureInfo{Available: false}, nil
+}
@@ -0,0 +1,27 @@
+package collectors
+
+import "runtime"
+
+type ProcessEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ProcessLimit is one resource limit of a process, e.g. open files. Soft
+// and Hard are "unlimited" when there is no limit.
+type ProcessLimit struct {
+	Name string `json:"name"`
+	Soft string `json:"soft"`
+	Hard string `json:"hard"`
+	Unit string `json:"unit,omitempty"`
+}
+
+// NormalizeCPU rescales every cpuPercent from "percent of one core", which
+// exceeds 100 for multithreaded processes, to percent of the whole machine
+// (0-100). cpuPercentRaw keeps the per-core value.
+func (l *ProcessList) NormalizeCPU() {
+	cores := float64(runtime.NumCPU())
+	for i := range l.Processes {
+		l.Processes[i].CPUPercent = l.Processes[i].CPUPercentRaw / cores
+	}
+}
@@ -3,39 +3,48 @@
 package collectors
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"os/exec"
 	"os/user"
+	"regexp"
 	"strconv"
 	"strings"
 	"syscall"
 )
 
 type ProcessInfo struct {
-	PID           int      `json:"pid"`
-	PPID          int      `json:"ppid"`
-	Name          string   `json:"name"`
-	Command       string   `json:"command"`
-	CommandLine   []string `json:"commandLine,omitempty"`
-	State         string   `json:"state"`
-	User          string   `json:"user"`
-	UID           int      `json:"uid"`
-	GID           int      `json:"gid"`
-	CPUPercent    float64  `json:"cpuPercent"`
-	MemoryPercent float64  `json:"memoryPercent"`
-	MemoryBytes   uint64   `json:"memoryBytes"`
-	Threads       int      `json:"threads"`
-	Nice          int      `json:"nice"`
-	VmSize        uint64   `json:"vmSize,omitempty"`
-	VmRss         uint64   `json:"vmRss,omitempty"`
-	VmSwap        uint64   `json:"vmSwap,omitempty"`
-	IoReadBytes   uint64   `json:"ioReadBytes,omitempty"`
-	IoWriteBytes  uint64   `json:"ioWriteBytes,omitempty"`
-	Exe           string   `json:"exe,omitempty"`
-	Cwd           string   `json:"cwd,omitempty"`
-	Uptime        string   `json:"uptime,omitempty"`
-	Children      []int    `json:"children,omitempty"`
-	Connections   []Socket `json:"connections,omitempty"`
-	FDs           []FD     `json:"fds,omitempty"`
+	PID           int             `json:"pid"`
+	PPID          int             `json:"ppid"`
+	Name          string          `json:"name"`
+	Command       string          `json:"command"`
+	CommandLine   []string        `json:"commandLine,omitempty"`
+	State         string          `json:"state"`
+	User          string          `json:"user"`
+	UID           int             `json:"uid"`
+	GID           int             `json:"gid"`
+	CPUPercent    float64         `json:"cpuPercent"`
+	CPUPercentRaw float64         `json:"cpuPercentRaw"` // Percent of one core, can exceed 100
+	MemoryPercent float64         `json:"memoryPercent"`
+	MemoryBytes   uint64          `json:"memoryBytes"`
+	Threads       int             `json:"threads"`
+	FDCount       int             `json:"fdCount"` // Only collected by GetProcessDetail
+	SocketCount   int             `json:"socketCount"`
+	Nice          int             `json:"nice"`
+	VmSize        uint64          `json:"vmSize,omitempty"`
+	VmRss         uint64          `json:"vmRss,omitempty"`
+	VmSwap        uint64          `json:"vmSwap,omitempty"`
+	IoReadBytes   uint64          `json:"ioReadBytes,omitempty"`
+	IoWriteBytes  uint64          `json:"ioWriteBytes,omitempty"`
+	Exe           string          `json:"exe,omitempty"`
+	Cwd           string          `json:"cwd,omitempty"`
+	Uptime        string          `json:"uptime,omitempty"`
+	Children      []int           `json:"children,omitempty"`
+	Connections   []Socket        `json:"connections,omitempty"`
+	FDs           []FD            `json:"fds,omitempty"`
+	Environ       []ProcessEnvVar `json:"environ,omitempty"`
+	Limits        []ProcessLimit  `json:"limits,omitempty"` // Only known for syspeek's own process
 }
 
 type FD struct {
@@ -47,15 +56,25 @@ type FD struct {
 type ProcessList struct {
 	Processes  []ProcessInfo `json:"processes"`
 	TotalCount int           `json:"totalCount"`
+	Partial    bool          `json:"partial,omitempty"`
 }
 
 func GetProcessList() (ProcessList, error) {
+	return GetProcessListContext(context.Background())
+}
+
+// GetProcessListContext is GetProcessList bounded by ctx; ps is killed if
+// ctx ends first.
+func GetProcessListContext(ctx context.Context) (ProcessList, error) {
 	list := ProcessList{}
 
 	// Use ps to get process list
 	// Format: pid,ppid,user,state,%cpu,%mem,rss,vsz,command
-	out, err := exec.Command("ps", "-axo", "pid,ppid,user,state,%cpu,%mem,rss,vsz,comm").Output()
+	out, err := exec.CommandContext(ctx, "ps", "-axo", "pid,ppid,user,state,%cpu,%mem,rss,vsz,comm").Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return list, ctx.Err()
+		}
 		return list, err
 	}
 
@@ -83,6 +102,7 @@ func GetProcessList() (ProcessList, error) {
 			User:          fields[2],
 			State:         fields[3],
 			CPUPercent:    cpuPercent,
+			CPUPercentRaw: cpuPercent,
 			MemoryPercent: memPercent,
 			MemoryBytes:   rss * 1024, // rss is in KB
 			VmRss:         rss * 1024,
@@ -105,6 +125,9 @@ func GetProcessList() (ProcessList, error) {
 }
 
 func GetProcessDetail(pid int) (*ProcessInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	list, err := GetProcessList()
 	if err != nil {
 		return nil, err
@@ -113,10 +136,37 @@ func GetProcessDetail(pid int) (*ProcessInfo, error) {
 	for _, p := range list.Processes {
 		if p.PID == pid {
 			// Get full command line
-			if out, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "command=").Output(); err == nil {
+			if out, err := exec.CommandContext(ctx, "ps", "-ww", "-p", strconv.Itoa(pid), "-o", "command=").Output(); err == nil {
 				p.Command = strings.TrimSpace(string(out))
 				p.CommandLine = strings.Fields(p.Command)
 			}
+
+			// ps -E appends the environment after the command; it is only
+			// shown for our own processes unless running as root
+			if out, err := exec.CommandContext(ctx, "ps", "-E", "-ww", "-p", strconv.Itoa(pid), "-o", "command=").Output(); err == nil {
+				env := strings.TrimPrefix(strings.TrimSpace(string(out)), p.Command)
+				p.Environ = parsePsEnviron(env)
+			}
+
+			if enabledFeatures().ProcessFDs {
+				p.FDs = getLsofFDs(ctx, &p)
+				p.FDCount = len(p.FDs)
+				for _, fd := range p.FDs {
+					if fd.Type == "socket" {
+						p.SocketCount++
+					}
+				}
+			}
+
+			for _, c := range list.Processes {
+				if c.PPID == pid && c.PID != pid {
+					p.Children = append(p.Children, c.PID)
+				}
+			}
+
+			if pid == os.Getpid() {
+				p.Limits = getOwnLimits()
+			}
 			return &p, nil
 		}
 	}
@@ -124,6 +174,133 @@ func GetProcessDetail(pid int) (*ProcessInfo, error) {
 	return nil, nil
 }
 
+// envNameRegex matches the start of a NAME=value environment entry
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// parsePsEnviron splits the environment printed by ps -E. Entries are only
+// separated by spaces, so a word that doesn't start a new NAME= entry is
+// taken to be part of the previous value.
+func parsePsEnviron(s string) []ProcessEnvVar {
+	var env []ProcessEnvVar
+	for _, word := range strings.Fields(s) {
+		if envNameRegex.MatchString(word) {
+			parts := strings.SplitN(word, "=", 2)
+			env = append(env, ProcessEnvVar{Name: parts[0], Value: parts[1]})
+		} else if len(env) > 0 {
+			env[len(env)-1].Value += " " + word
+		}
+	}
+	return env
+}
+
+// getLsofFDs lists the open files of p with lsof's field output, one
+// "f<fd>", "t<type>" and "n<name>" line per file. The cwd and txt entries
+// fill in Cwd and Exe instead of being listed.
+func getLsofFDs(ctx context.Context, p *ProcessInfo) []FD {
+	out, err := exec.CommandContext(ctx, "lsof", "-n", "-P", "-a", "-p", strconv.Itoa(p.PID), "-F", "ftn").Output()
+	if err != nil && len(out) == 0 {
+		return nil
+	}
+
+	var fds []FD
+	var fd, fdType string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line == "" {
+			continue
+		}
+		value := line[1:]
+		switch line[0] {
+		case 'f':
+			fd, fdType = value, ""
+		case 't':
+			fdType = value
+		case 'n':
+			switch fd {
+			case "cwd":
+				p.Cwd = value
+			case "txt":
+				if p.Exe == "" {
+					p.Exe = value
+				}
+			default:
+				num, err := strconv.Atoi(fd)
+				if err != nil {
+					continue // mem, rtd and other non-descriptor entries
+				}
+				fds = append(fds, FD{FD: num, Type: lsofFDType(fdType), Target: value})
+			}
+		}
+	}
+	return fds
+}
+
+// lsofFDType maps lsof's TYPE column to the types used on Linux
+func lsofFDType(t string) string {
+	switch t {
+	case "REG", "DIR", "CHR":
+		return "file"
+	case "IPv4", "IPv6", "unix", "systm":
+		return "socket"
+	case "PIPE", "FIFO":
+		return "pipe"
+	case "KQUEUE":
+		return "anon_inode"
+	}
+	return "unknown"
+}
+
+// getOwnLimits returns our own rlimits. macOS has no interface to read
+// another process's limits, so other PIDs report none.
+func getOwnLimits() []ProcessLimit {
+	resources := []struct {
+		name     string
+		resource int
+		unit     string
+	}{
+		{"Max cpu time", syscall.RLIMIT_CPU, "seconds"},
+		{"Max file size", syscall.RLIMIT_FSIZE, "bytes"},
+		{"Max data size", syscall.RLIMIT_DATA, "bytes"},
+		{"Max stack size", syscall.RLIMIT_STACK, "bytes"},
+		{"Max core file size", syscall.RLIMIT_CORE, "bytes"},
+		{"Max address space", syscall.RLIMIT_AS, "bytes"},
+		{"Max open files", syscall.RLIMIT_NOFILE, "files"},
+	}
+
+	format := func(v uint64) string {
+		if v >= rlimInfinity {
+			return "unlimited"
+		}
+		return strconv.FormatUint(v, 10)
+	}
+
+	var limits []ProcessLimit
+	for _, r := range resources {
+		var rl syscall.Rlimit
+		if err := syscall.Getrlimit(r.resource, &rl); err != nil {
+			continue
+		}
+		limits = append(limits, ProcessLimit{
+			Name: r.name,
+			Soft: format(rl.Cur),
+			Hard: format(rl.Max),
+			Unit: r.unit,
+		})
+	}
+	return limits
+}
+
+// GetProcessLimits returns the rlimits of pid, which macOS only exposes
+// for the calling process; other PIDs get an empty list
+func GetProcessLimits(pid int) ([]ProcessLimit, error) {
+	if pid == os.Getpid() {
+		return getOwnLimits(), nil
+	}
+	return []ProcessLimit{}, nil
+}
+
+// rlimInfinity is RLIM_INFINITY on macOS
+const rlimInfinity = 1<<63 - 1
+
 func GetProcessesByUser(username string) ([]ProcessInfo, error) {
 	list, err := GetProcessList()
 	if err != nil {
@@ -144,8 +321,22 @@ func KillProcess(pid int, signal syscall.Signal) error {
 	return syscall.Kill(pid, signal)
 }
 
+// IsKernelThread is always false: kernel threads aren't visible as
+// processes on this platform
+func IsKernelThread(pid int) bool {
+	return false
+}
+
 // ReniceProcess changes the nice value of a process on macOS
 func ReniceProcess(pid int, priority int) error {
-	cmd := exec.Command("renice", strconv.Itoa(priority), "-p", strconv.Itoa(pid))
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "renice", strconv.Itoa(priority), "-p", strconv.Itoa(pid))
 	return cmd.Run()
 }
+
+// SetSchedPolicy is only supported on Linux.
+func SetSchedPolicy(pid int, policy string) error {
+	return fmt.Errorf("scheduling policies are not supported on this platform")
+}
@@ -4,6 +4,8 @@ package collectors
 
 import (
 	"bufio"
+	"context"
+	"encoding/binary"
 	"fmt"
 	"net"
 	"os"
@@ -13,6 +15,7 @@ import (
 	"sync"
 	"syscall"
 	"time"
+	"unsafe"
 )
 
 type ProcessBasic struct {
@@ -23,12 +26,16 @@ type ProcessBasic struct {
 	User        string  `json:"user"`
 	State       string  `json:"state"`
 	CPUPercent  float64 `json:"cpuPercent"`
+	CPUPercentRaw float64 `json:"cpuPercentRaw"` // Percent of one core, can exceed 100
 	MemoryBytes uint64  `json:"memoryBytes"`
 	MemoryPercent float64 `json:"memoryPercent"`
 	Threads     int     `json:"threads"`
 	Nice        int     `json:"nice"`
 	StartTime   int64   `json:"startTime"`
 	Uptime      string  `json:"uptime"`
+	Restarted   bool    `json:"restarted,omitempty"` // PID was reused by a new process since the last sample
+	FDCount     int     `json:"fdCount"`
+	SocketCount int     `json:"socketCount"`
 }
 
 type ProcessConnection struct {
@@ -46,11 +53,6 @@ type ProcessFD struct {
 	Target string `json:"target"`
 }
 
-type ProcessEnvVar struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
-}
-
 type ProcessDetail struct {
 	ProcessBasic
 	CommandLine   []string            `json:"commandLine"`
@@ -71,26 +73,110 @@ type ProcessDetail struct {
 	VmSwap        uint64              `json:"vmSwap"`
 	IOReadBytes   uint64              `json:"ioReadBytes"`
 	IOWriteBytes  uint64              `json:"ioWriteBytes"`
+	IORChar       uint64              `json:"ioRchar"`  // Bytes read via read() and friends, including page cache
+	IOWChar       uint64              `json:"ioWchar"`  // Bytes written via write() and friends
+	IOSyscR       uint64              `json:"ioSyscr"`  // Read syscalls
+	IOSyscW       uint64              `json:"ioSyscw"`  // Write syscalls
+	IOCancelledWriteBytes  uint64     `json:"ioCancelledWriteBytes"`
+	SchedRunTime  uint64              `json:"schedRunTime"`    // Nanoseconds spent on CPU
+	SchedWaitTime uint64              `json:"schedWaitTime"`   // Nanoseconds spent runnable, waiting for a CPU
+	SchedTimeslices uint64            `json:"schedTimeslices"` // Number of timeslices run on a CPU
 	VoluntaryCtxSwitches   uint64     `json:"voluntaryCtxSwitches"`
 	InvoluntaryCtxSwitches uint64     `json:"involuntaryCtxSwitches"`
+	SchedPolicy   string              `json:"schedPolicy"` // SCHED_OTHER, SCHED_FIFO, SCHED_RR, ...
+	RTPriority    int                 `json:"rtPriority"`  // 1-99 for real-time policies, 0 otherwise
+	Limits        []ProcessLimit      `json:"limits"`
+	Cgroup        *ProcessCgroup      `json:"cgroup,omitempty"`
 }
 
 type ProcessList struct {
 	Processes  []ProcessBasic `json:"processes"`
 	TotalCount int            `json:"totalCount"`
+	Partial    bool           `json:"partial,omitempty"` // Deadline hit before every PID was read
+}
+
+// cpuSample is the last CPU tick count seen for a PID. The process start
+// time is kept alongside so a recycled PID isn't diffed against the ticks
+// of the process that previously owned it, and the time it was read so a
+// PID skipped by a partial pass is still divided by its own interval.
+type cpuSample struct {
+	ticks     uint64
+	startTime uint64
+	at        time.Time
+}
+
+// percentTo is the CPU usage between prev and cur. restarted reports that
+// the PID belongs to a new process, whose ticks can't be diffed against
+// prev, so the percent is left at 0.
+func (prev cpuSample) percentTo(cur cpuSample) (percent float64, restarted bool) {
+	if prev.startTime != cur.startTime {
+		return 0, true
+	}
+	if cur.ticks < prev.ticks {
+		return 0, false
+	}
+	elapsed := cur.at.Sub(prev.at).Seconds()
+	if elapsed < 0.1 {
+		elapsed = 0.1
+	}
+	return ticksToPercent(cur.ticks-prev.ticks, clkTck, elapsed), false
 }
 
 var (
-	previousCPUTicks map[int]uint64
-	previousTime     time.Time
+	previousCPUTicks map[int]cpuSample
 	systemBootTime   int64
 	totalMemory      uint64
 	processMutex     sync.Mutex
 )
 
+// clkTck is the kernel's USER_HZ, the unit of the tick counts in
+// /proc/<pid>/stat. It is almost always 100, but not guaranteed to be.
+var clkTck = readClockTicks()
+
+// atClkTck is the auxiliary vector entry holding sysconf(_SC_CLK_TCK)
+const atClkTck = 17
+
+// readClockTicks reads AT_CLKTCK from our own auxiliary vector, which is
+// where libc's sysconf gets it, falling back to 100
+func readClockTicks() uint64 {
+	data, err := os.ReadFile("/proc/self/auxv")
+	if err != nil {
+		return 100
+	}
+	return clockTicksFromAuxv(data, strconv.IntSize/8)
+}
+
+// clockTicksFromAuxv finds AT_CLKTCK in an auxiliary vector of word-byte
+// machine words, falling back to 100
+func clockTicksFromAuxv(data []byte, word int) uint64 {
+	// The vector is pairs of native-endian machine words: type, value
+	for i := 0; i+2*word <= len(data); i += 2 * word {
+		var key, value uint64
+		if word == 8 {
+			key = binary.NativeEndian.Uint64(data[i:])
+			value = binary.NativeEndian.Uint64(data[i+word:])
+		} else {
+			key = uint64(binary.NativeEndian.Uint32(data[i:]))
+			value = uint64(binary.NativeEndian.Uint32(data[i+word:]))
+		}
+		if key == atClkTck && value > 0 {
+			return value
+		}
+	}
+	return 100
+}
+
+// ticksToPercent turns a CPU tick delta over elapsed seconds into a percent
+// of one core
+func ticksToPercent(ticksDelta, ticksPerSecond uint64, elapsed float64) float64 {
+	if ticksPerSecond == 0 || elapsed <= 0 {
+		return 0
+	}
+	return 100 * (float64(ticksDelta) / float64(ticksPerSecond)) / elapsed
+}
+
 func init() {
-	previousCPUTicks = make(map[int]uint64)
-	previousTime = time.Now()
+	previousCPUTicks = make(map[int]cpuSample)
 
 	// Get system boot time
 	uptime, _ := os.ReadFile("/proc/uptime")
@@ -120,6 +206,12 @@ func init() {
 }
 
 func GetProcessList() (*ProcessList, error) {
+	return GetProcessListContext(context.Background())
+}
+
+// GetProcessListContext is GetProcessList bounded by ctx. When ctx is done
+// midway the processes read so far are returned with Partial set.
+func GetProcessListContext(ctx context.Context) (*ProcessList, error) {
 	list := &ProcessList{
 		Processes: []ProcessBasic{},
 	}
@@ -129,15 +221,15 @@ func GetProcessList() (*ProcessList, error) {
 		return nil, err
 	}
 
-	now := time.Now()
-	processMutex.Lock()
-	elapsed := now.Sub(previousTime).Seconds()
-	processMutex.Unlock()
-	if elapsed < 0.1 {
-		elapsed = 0.1
-	}
+	// PIDs seen in this pass; anything else in previousCPUTicks has exited
+	seen := make(map[int]bool, len(entries))
 
 	for _, entry := range entries {
+		if ctx.Err() != nil {
+			list.Partial = true
+			break
+		}
+
 		if !entry.IsDir() {
 			continue
 		}
@@ -147,23 +239,31 @@ func GetProcessList() (*ProcessList, error) {
 			continue
 		}
 
-		proc, err := getProcessBasic(pid, elapsed)
+		proc, err := getProcessBasic(pid)
 		if err != nil {
 			continue
 		}
 
+		seen[pid] = true
 		list.Processes = append(list.Processes, *proc)
 	}
 
 	processMutex.Lock()
-	previousTime = now
+	// A partial pass didn't visit every PID, so unseen ones may still exist
+	if !list.Partial {
+		for pid := range previousCPUTicks {
+			if !seen[pid] {
+				delete(previousCPUTicks, pid)
+			}
+		}
+	}
 	processMutex.Unlock()
 	list.TotalCount = len(list.Processes)
 
 	return list, nil
 }
 
-func getProcessBasic(pid int, elapsed float64) (*ProcessBasic, error) {
+func getProcessBasic(pid int) (*ProcessBasic, error) {
 	proc := &ProcessBasic{PID: pid}
 	procPath := fmt.Sprintf("/proc/%d", pid)
 
@@ -173,6 +273,7 @@ func getProcessBasic(pid int, elapsed float64) (*ProcessBasic, error) {
 	if err != nil {
 		return nil, err
 	}
+	readAt := time.Now()
 
 	// Parse stat - careful with comm field which can contain spaces and parens
 	statStr := string(statData)
@@ -194,24 +295,27 @@ func getProcessBasic(pid int, elapsed float64) (*ProcessBasic, error) {
 	proc.PPID, _ = strconv.Atoi(fields[1])
 	proc.Nice, _ = strconv.Atoi(fields[16])
 	proc.Threads, _ = strconv.Atoi(fields[17])
+	if f := enabledFeatures(); f.ProcessFDs {
+		proc.FDCount, proc.SocketCount = countFDs(pid, f.ProcessSocketCounts)
+	}
 
 	// Calculate CPU usage
 	utime, _ := strconv.ParseUint(fields[11], 10, 64)
 	stime, _ := strconv.ParseUint(fields[12], 10, 64)
 	totalTicks := utime + stime
+	starttime, _ := strconv.ParseUint(fields[19], 10, 64)
 
+	sample := cpuSample{ticks: totalTicks, startTime: starttime, at: readAt}
 	processMutex.Lock()
-	if prevTicks, exists := previousCPUTicks[pid]; exists {
-		ticksDelta := totalTicks - prevTicks
-		// Convert ticks to percentage (assuming 100 ticks/sec)
-		proc.CPUPercent = float64(ticksDelta) / elapsed
+	if prev, exists := previousCPUTicks[pid]; exists {
+		proc.CPUPercent, proc.Restarted = prev.percentTo(sample)
+		proc.CPUPercentRaw = proc.CPUPercent
 	}
-	previousCPUTicks[pid] = totalTicks
+	previousCPUTicks[pid] = sample
 	processMutex.Unlock()
 
-	// Get start time and calculate uptime
-	starttime, _ := strconv.ParseUint(fields[19], 10, 64)
-	proc.StartTime = systemBootTime + int64(starttime/100)
+	// Calculate uptime from start time
+	proc.StartTime = systemBootTime + int64(starttime/clkTck)
 	if proc.StartTime > 0 {
 		uptimeSecs := time.Now().Unix() - proc.StartTime
 		proc.Uptime = formatUptime(float64(uptimeSecs))
@@ -264,8 +368,48 @@ func getProcessBasic(pid int, elapsed float64) (*ProcessBasic, error) {
 	return proc, nil
 }
 
+// GetProcessLimits parses /proc/<pid>/limits. The file is a fixed-width
+// table, so columns are cut at the header's "Soft Limit", "Hard Limit"
+// and "Units" positions; limit names contain spaces.
+func GetProcessLimits(pid int) ([]ProcessLimit, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	header := lines[0]
+	softCol := strings.Index(header, "Soft Limit")
+	hardCol := strings.Index(header, "Hard Limit")
+	unitCol := strings.Index(header, "Units")
+	if softCol < 0 || hardCol < softCol || unitCol < hardCol {
+		return nil, fmt.Errorf("unexpected limits format for PID %d", pid)
+	}
+
+	column := func(line string, from, to int) string {
+		if from >= len(line) {
+			return ""
+		}
+		return strings.TrimSpace(line[from:min(to, len(line))])
+	}
+
+	limits := []ProcessLimit{}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		limits = append(limits, ProcessLimit{
+			Name: column(line, 0, softCol),
+			Soft: column(line, softCol, hardCol),
+			Hard: column(line, hardCol, unitCol),
+			Unit: column(line, unitCol, len(line)),
+		})
+	}
+	return limits, nil
+}
+
 func GetProcessDetail(pid int) (*ProcessDetail, error) {
-	basic, err := getProcessBasic(pid, 1.0)
+	basic, err := getProcessBasic(pid)
 	if err != nil {
 		return nil, err
 	}
@@ -277,6 +421,7 @@ func GetProcessDetail(pid int) (*ProcessDetail, error) {
 		Connections:  []ProcessConnection{},
 		Children:     []int{},
 		Groups:       []int{},
+		Limits:       []ProcessLimit{},
 	}
 
 	procPath := fmt.Sprintf("/proc/%d", pid)
@@ -322,10 +467,20 @@ func GetProcessDetail(pid int) (*ProcessDetail, error) {
 		}
 	}
 
+	// Get scheduling policy
+	detail.SchedPolicy, detail.RTPriority = getSchedPolicy(pid)
+
+	// Get resource limits
+	if limits, err := GetProcessLimits(pid); err == nil {
+		detail.Limits = limits
+	}
+	detail.Cgroup = getProcessCgroup(pid)
+
 	// Get file descriptors
 	fdPath := filepath.Join(procPath, "fd")
 	fds, err := os.ReadDir(fdPath)
-	if err == nil {
+	if err == nil && enabledFeatures().ProcessFDs {
+		sockets := 0
 		for _, fd := range fds {
 			fdNum, _ := strconv.Atoi(fd.Name())
 			target, err := os.Readlink(filepath.Join(fdPath, fd.Name()))
@@ -349,7 +504,14 @@ func GetProcessDetail(pid int) (*ProcessDetail, error) {
 				Type:   fdType,
 				Target: target,
 			})
+			if fdType == "socket" {
+				sockets++
+			}
 		}
+		// The links are resolved here anyway, so the detail always has
+		// the socket count the list only computes when asked to
+		detail.FDCount = len(detail.FDs)
+		detail.SocketCount = sockets
 	}
 
 	// Get network connections for this process
@@ -445,10 +607,31 @@ func GetProcessDetail(pid int) (*ProcessDetail, error) {
 				detail.IOReadBytes, _ = strconv.ParseUint(fields[1], 10, 64)
 			case "write_bytes":
 				detail.IOWriteBytes, _ = strconv.ParseUint(fields[1], 10, 64)
+			case "rchar":
+				detail.IORChar, _ = strconv.ParseUint(fields[1], 10, 64)
+			case "wchar":
+				detail.IOWChar, _ = strconv.ParseUint(fields[1], 10, 64)
+			case "syscr":
+				detail.IOSyscR, _ = strconv.ParseUint(fields[1], 10, 64)
+			case "syscw":
+				detail.IOSyscW, _ = strconv.ParseUint(fields[1], 10, 64)
+			case "cancelled_write_bytes":
+				detail.IOCancelledWriteBytes, _ = strconv.ParseUint(fields[1], 10, 64)
 			}
 		}
 	}
 
+	// Get scheduler stats: run time, wait time (both ns) and timeslices
+	schedData, err := os.ReadFile(filepath.Join(procPath, "schedstat"))
+	if err == nil {
+		fields := strings.Fields(string(schedData))
+		if len(fields) >= 3 {
+			detail.SchedRunTime, _ = strconv.ParseUint(fields[0], 10, 64)
+			detail.SchedWaitTime, _ = strconv.ParseUint(fields[1], 10, 64)
+			detail.SchedTimeslices, _ = strconv.ParseUint(fields[2], 10, 64)
+		}
+	}
+
 	// Calculate uptime
 	if detail.StartTime > 0 {
 		uptime := time.Now().Unix() - detail.StartTime
@@ -458,24 +641,77 @@ func GetProcessDetail(pid int) (*ProcessDetail, error) {
 	return detail, nil
 }
 
-func getProcessConnections(pid int) []ProcessConnection {
-	connections := []ProcessConnection{}
+// fdLink is an open file descriptor and what /proc/<pid>/fd/<n> points to
+type fdLink struct {
+	FD     int
+	Path   string // /proc/<pid>/fd/<n>
+	Target string
+}
 
-	// Get socket inodes for this process
-	socketInodes := make(map[string]bool)
+// countFDs counts a process's open descriptors by listing the fd directory
+// by name only. Sockets are counted only when withSockets is set, since
+// that takes a readlink per descriptor. Processes we may not inspect count
+// as 0.
+func countFDs(pid int, withSockets bool) (fds, sockets int) {
+	fdPath := fmt.Sprintf("/proc/%d/fd", pid)
+	dir, err := os.Open(fdPath)
+	if err != nil {
+		return 0, 0
+	}
+	names, _ := dir.Readdirnames(-1)
+	dir.Close()
+
+	if !withSockets {
+		return len(names), 0
+	}
+	for _, name := range names {
+		target, err := os.Readlink(fdPath + "/" + name)
+		if err != nil {
+			continue // Closed since the listing
+		}
+		fds++
+		if strings.HasPrefix(target, "socket:") {
+			sockets++
+		}
+	}
+	return fds, sockets
+}
+
+// readFDLinks resolves every fd of a process. Unreadable links are skipped;
+// the error is only set when the fd directory itself can't be read.
+func readFDLinks(pid int) ([]fdLink, error) {
 	fdPath := fmt.Sprintf("/proc/%d/fd", pid)
 	fds, err := os.ReadDir(fdPath)
 	if err != nil {
-		return connections
+		return nil, err
 	}
 
+	links := make([]fdLink, 0, len(fds))
 	for _, fd := range fds {
-		target, err := os.Readlink(filepath.Join(fdPath, fd.Name()))
+		path := filepath.Join(fdPath, fd.Name())
+		target, err := os.Readlink(path)
 		if err != nil {
 			continue
 		}
-		if strings.HasPrefix(target, "socket:[") {
-			inode := strings.TrimPrefix(strings.TrimSuffix(target, "]"), "socket:[")
+		fdNum, _ := strconv.Atoi(fd.Name())
+		links = append(links, fdLink{FD: fdNum, Path: path, Target: target})
+	}
+	return links, nil
+}
+
+func getProcessConnections(pid int) []ProcessConnection {
+	connections := []ProcessConnection{}
+
+	// Get socket inodes for this process
+	socketInodes := make(map[string]bool)
+	links, err := readFDLinks(pid)
+	if err != nil {
+		return connections
+	}
+
+	for _, link := range links {
+		if strings.HasPrefix(link.Target, "socket:[") {
+			inode := strings.TrimPrefix(strings.TrimSuffix(link.Target, "]"), "socket:[")
 			socketInodes[inode] = true
 		}
 	}
@@ -618,7 +854,146 @@ func KillProcess(pid int, signal syscall.Signal) error {
 	return syscall.Kill(pid, signal)
 }
 
+// pfKthread is the PF_KTHREAD bit of the flags field in /proc/<pid>/stat
+const pfKthread = 0x00200000
+
+// IsKernelThread reports whether pid is a kernel thread: kthreadd and the
+// threads it spawns (PPID 2). The PF_KTHREAD flag is checked rather than
+// the PPID, since inside a PID namespace PID 2 can be an ordinary process.
+func IsKernelThread(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	stat := string(data)
+	closeParen := strings.LastIndex(stat, ")")
+	if closeParen == -1 || closeParen+2 > len(stat) {
+		return false
+	}
+	fields := strings.Fields(stat[closeParen+2:])
+	if len(fields) < 7 {
+		return false
+	}
+	flags, _ := strconv.ParseUint(fields[6], 10, 64)
+	return flags&pfKthread != 0
+}
+
 // ReniceProcess changes the priority of a process
 func ReniceProcess(pid int, priority int) error {
 	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, priority)
 }
+
+// Scheduling policies from <sched.h>
+var schedPolicyNames = map[int]string{
+	0: "SCHED_OTHER",
+	1: "SCHED_FIFO",
+	2: "SCHED_RR",
+	3: "SCHED_BATCH",
+	5: "SCHED_IDLE",
+	6: "SCHED_DEADLINE",
+}
+
+// getSchedPolicy reads the rt_priority and policy fields (40 and 41) of /proc/<pid>/stat
+func getSchedPolicy(pid int) (string, int) {
+	statData, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", 0
+	}
+
+	statStr := string(statData)
+	closeParen := strings.LastIndex(statStr, ")")
+	if closeParen == -1 || closeParen+2 > len(statStr) {
+		return "", 0
+	}
+
+	// Fields after the comm start at field 3
+	fields := strings.Fields(statStr[closeParen+2:])
+	if len(fields) < 39 {
+		return "", 0
+	}
+
+	rtPriority, _ := strconv.Atoi(fields[37])
+	policy, _ := strconv.Atoi(fields[38])
+
+	name, ok := schedPolicyNames[policy]
+	if !ok {
+		name = fmt.Sprintf("UNKNOWN(%d)", policy)
+	}
+	return name, rtPriority
+}
+
+// SetSchedPolicy moves a process to one of the non-real-time policies
+// (SCHED_OTHER, SCHED_BATCH, SCHED_IDLE). Real-time policies are refused
+// since a runaway FIFO/RR process can starve the whole system, and so are
+// processes already running under one: demoting audio or watchdog threads
+// is not something to do from a dashboard.
+//
+// sched_setscheduler only changes the thread it is given, so the policy
+// is applied to every thread in /proc/<pid>/task.
+func SetSchedPolicy(pid int, policy string) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid PID %d", pid)
+	}
+
+	var policyID int
+	switch strings.ToUpper(policy) {
+	case "SCHED_OTHER", "OTHER":
+		policyID = 0
+	case "SCHED_BATCH", "BATCH":
+		policyID = 3
+	case "SCHED_IDLE", "IDLE":
+		policyID = 5
+	default:
+		return fmt.Errorf("unsupported policy %q, allowed: SCHED_OTHER, SCHED_BATCH, SCHED_IDLE", policy)
+	}
+
+	tids, err := processThreadIDs(pid)
+	if err != nil {
+		return err
+	}
+
+	// Check every thread before touching any, so a refusal leaves the
+	// process as it was
+	for _, tid := range tids {
+		if current, _ := getSchedPolicy(tid); isRealtimePolicy(current) {
+			return fmt.Errorf("PID %d runs a %s thread (TID %d), refusing to change its policy", pid, current, tid)
+		}
+	}
+
+	// struct sched_param { int sched_priority; } - always 0 for these policies
+	var param int32
+	for _, tid := range tids {
+		_, _, errno := syscall.Syscall(syscall.SYS_SCHED_SETSCHEDULER, uintptr(tid), uintptr(policyID), uintptr(unsafe.Pointer(&param)))
+		// ESRCH: the thread exited since we listed it
+		if errno != 0 && errno != syscall.ESRCH {
+			return fmt.Errorf("failed to set policy of TID %d: %v", tid, errno)
+		}
+	}
+	return nil
+}
+
+func isRealtimePolicy(policy string) bool {
+	return policy == "SCHED_FIFO" || policy == "SCHED_RR" || policy == "SCHED_DEADLINE"
+}
+
+// processThreadIDs lists the thread IDs of a process from /proc/<pid>/task
+func processThreadIDs(pid int) ([]int, error) {
+	entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("process %d not found", pid)
+		}
+		return nil, err
+	}
+
+	tids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if tid, err := strconv.Atoi(e.Name()); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+	if len(tids) == 0 {
+		return nil, fmt.Errorf("process %d not found", pid)
+	}
+	return tids, nil
+}
@@ -0,0 +1,307 @@
+//go:build linux
+
+package collectors
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSetSchedPolicyAllThreads(t *testing.T) {
+	pid := os.Getpid()
+	if current, _ := getSchedPolicy(pid); current != "SCHED_OTHER" {
+		t.Skipf("test process runs under %s", current)
+	}
+
+	// Pin a goroutine to its own thread so there is more than one to change
+	done := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		close(started)
+		<-done
+	}()
+	<-started
+	defer close(done)
+
+	tids, err := processThreadIDs(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tids) < 2 {
+		t.Skipf("only %d thread(s)", len(tids))
+	}
+
+	if err := SetSchedPolicy(pid, "SCHED_BATCH"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetSchedPolicy(pid, "SCHED_OTHER")
+
+	tids, _ = processThreadIDs(pid)
+	for _, tid := range tids {
+		if policy, _ := getSchedPolicy(tid); policy != "SCHED_BATCH" {
+			t.Errorf("TID %d runs under %s, want SCHED_BATCH", tid, policy)
+		}
+	}
+}
+
+func TestSetSchedPolicyRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		pid    int
+		policy string
+	}{
+		{"zero PID", 0, "SCHED_OTHER"},
+		{"negative PID", -1, "SCHED_OTHER"},
+		{"real-time policy", os.Getpid(), "SCHED_FIFO"},
+		{"unknown policy", os.Getpid(), "SCHED_FAST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetSchedPolicy(tt.pid, tt.policy); err == nil {
+				t.Errorf("SetSchedPolicy(%d, %q) succeeded", tt.pid, tt.policy)
+			}
+		})
+	}
+}
+
+func TestIsRealtimePolicy(t *testing.T) {
+	for policy, want := range map[string]bool{
+		"SCHED_OTHER":    false,
+		"SCHED_BATCH":    false,
+		"SCHED_IDLE":     false,
+		"SCHED_FIFO":     true,
+		"SCHED_RR":       true,
+		"SCHED_DEADLINE": true,
+		"":               false,
+	} {
+		if got := isRealtimePolicy(policy); got != want {
+			t.Errorf("isRealtimePolicy(%q) = %v, want %v", policy, got, want)
+		}
+	}
+}
+
+func TestCPUSamplePercentTo(t *testing.T) {
+	prevTck := clkTck
+	clkTck = 100
+	defer func() { clkTck = prevTck }()
+
+	t0 := time.Now()
+	at := func(seconds int) time.Time { return t0.Add(time.Duration(seconds) * time.Second) }
+
+	tests := []struct {
+		name      string
+		prev, cur cpuSample
+		want      float64
+		restarted bool
+	}{
+		{"one core busy", cpuSample{ticks: 1000, startTime: 50, at: at(0)}, cpuSample{ticks: 1200, startTime: 50, at: at(2)}, 100, false},
+		{"idle", cpuSample{ticks: 1000, startTime: 50, at: at(0)}, cpuSample{ticks: 1000, startTime: 50, at: at(2)}, 0, false},
+		// A PID a partial pass skipped is diffed over both intervals
+		{"sample two passes old", cpuSample{ticks: 1000, startTime: 50, at: at(0)}, cpuSample{ticks: 1400, startTime: 50, at: at(4)}, 100, false},
+		// The new process has fewer ticks than the old one had; diffing
+		// them would underflow or, the other way round, report a spike
+		{"PID reused, fewer ticks", cpuSample{ticks: 90000, startTime: 50, at: at(0)}, cpuSample{ticks: 10, startTime: 7000, at: at(2)}, 0, true},
+		{"PID reused, more ticks", cpuSample{ticks: 10, startTime: 50, at: at(0)}, cpuSample{ticks: 90000, startTime: 7000, at: at(2)}, 0, true},
+		{"ticks went backwards", cpuSample{ticks: 1000, startTime: 50, at: at(0)}, cpuSample{ticks: 900, startTime: 50, at: at(2)}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, restarted := tt.prev.percentTo(tt.cur)
+			if got != tt.want || restarted != tt.restarted {
+				t.Errorf("percentTo = %v, %v, want %v, %v", got, restarted, tt.want, tt.restarted)
+			}
+		})
+	}
+}
+
+func TestGetProcessBasicRestarted(t *testing.T) {
+	pid := os.Getpid()
+
+	processMutex.Lock()
+	prev, hadPrev := previousCPUTicks[pid]
+	// Ticks from an earlier owner of our PID, with another start time
+	previousCPUTicks[pid] = cpuSample{ticks: 0, startTime: 1}
+	processMutex.Unlock()
+	defer func() {
+		processMutex.Lock()
+		if hadPrev {
+			previousCPUTicks[pid] = prev
+		} else {
+			delete(previousCPUTicks, pid)
+		}
+		processMutex.Unlock()
+	}()
+
+	proc, err := getProcessBasic(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proc.Restarted {
+		t.Error("Restarted not set for a PID with a new start time")
+	}
+	if proc.CPUPercent != 0 {
+		t.Errorf("CPUPercent %v computed from the previous owner's ticks", proc.CPUPercent)
+	}
+
+	// The new process is now the baseline
+	processMutex.Lock()
+	stored := previousCPUTicks[pid]
+	processMutex.Unlock()
+	if stored.startTime == 1 {
+		t.Error("baseline not replaced")
+	}
+}
+
+func TestProcessSampleEviction(t *testing.T) {
+	// No live process can have this PID (above PID_MAX_LIMIT)
+	const gone = 1 << 23
+
+	seed := func() {
+		processMutex.Lock()
+		previousCPUTicks[gone] = cpuSample{ticks: 1, startTime: 1}
+		processMutex.Unlock()
+	}
+	tracked := func(pid int) bool {
+		processMutex.Lock()
+		defer processMutex.Unlock()
+		_, ok := previousCPUTicks[pid]
+		return ok
+	}
+	t.Cleanup(func() {
+		processMutex.Lock()
+		delete(previousCPUTicks, gone)
+		processMutex.Unlock()
+	})
+
+	// A pass cut short hasn't visited every PID, so it must keep them all
+	seed()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	list, err := GetProcessListContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !list.Partial {
+		t.Fatal("pass with a cancelled context not marked Partial")
+	}
+	if !tracked(gone) {
+		t.Error("partial pass evicted a PID it didn't visit")
+	}
+
+	// A full pass drops exited PIDs and keeps the live ones
+	list, err = GetProcessListContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Partial {
+		t.Fatal("full pass marked Partial")
+	}
+	if tracked(gone) {
+		t.Error("full pass kept an exited PID")
+	}
+	if !tracked(os.Getpid()) {
+		t.Error("full pass dropped a live PID")
+	}
+	processMutex.Lock()
+	size := len(previousCPUTicks)
+	processMutex.Unlock()
+	if size > len(list.Processes) {
+		t.Errorf("%d samples kept for %d processes", size, len(list.Processes))
+	}
+}
+
+func TestTicksToPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		delta   uint64
+		tck     uint64
+		elapsed float64
+		want    float64
+	}{
+		{"one core at USER_HZ 100", 100, 100, 1, 100},
+		{"half a core at USER_HZ 100", 100, 100, 2, 50},
+		{"two cores", 400, 100, 2, 200},
+		// Same ticks mean a quarter of the CPU time at USER_HZ 1000
+		{"USER_HZ 1000", 100, 1000, 1, 10},
+		{"USER_HZ 250", 250, 250, 1, 100},
+		{"idle", 0, 100, 1, 0},
+		{"no clock rate", 100, 0, 1, 0},
+		{"no time elapsed", 100, 100, 0, 0},
+		{"negative elapsed", 100, 100, -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ticksToPercent(tt.delta, tt.tck, tt.elapsed); got != tt.want {
+				t.Errorf("ticksToPercent(%d, %d, %v) = %v, want %v", tt.delta, tt.tck, tt.elapsed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClockTicksFromAuxv(t *testing.T) {
+	// auxv builds a vector of 64- or 32-bit type/value pairs
+	auxv := func(word int, pairs ...uint64) []byte {
+		var b []byte
+		for _, v := range pairs {
+			if word == 8 {
+				b = binary.NativeEndian.AppendUint64(b, v)
+			} else {
+				b = binary.NativeEndian.AppendUint32(b, uint32(v))
+			}
+		}
+		return b
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		word int
+		want uint64
+	}{
+		{"64-bit", auxv(8, 6, 4096, atClkTck, 250, 0, 0), 8, 250},
+		{"32-bit", auxv(4, 6, 4096, atClkTck, 1000, 0, 0), 4, 1000},
+		{"missing", auxv(8, 6, 4096, 0, 0), 8, 100},
+		{"zero value", auxv(8, atClkTck, 0, 0, 0), 8, 100},
+		{"truncated pair", auxv(8, atClkTck, 250)[:12], 8, 100},
+		{"empty", nil, 8, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clockTicksFromAuxv(tt.data, tt.word); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if clkTck == 0 {
+		t.Error("clkTck is 0")
+	}
+}
+
+func TestCountFDs(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+
+	pid := os.Getpid()
+	fds, sockets := countFDs(pid, false)
+	if fds == 0 {
+		t.Fatal("no descriptors counted for the test process")
+	}
+	if sockets != 0 {
+		t.Errorf("sockets counted without asking: %d", sockets)
+	}
+
+	if _, sockets = countFDs(pid, true); sockets < 1 {
+		t.Errorf("listening socket not counted, got %d", sockets)
+	}
+}
@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -16,31 +17,44 @@ import (
 )
 
 type ProcessInfo struct {
-	PID           int      `json:"pid"`
-	PPID          int      `json:"ppid"`
-	Name          string   `json:"name"`
-	Command       string   `json:"command"`
-	CommandLine   []string `json:"commandLine,omitempty"`
-	State         string   `json:"state"`
-	User          string   `json:"user"`
-	UID           int      `json:"uid"`
-	GID           int      `json:"gid"`
-	CPUPercent    float64  `json:"cpuPercent"`
-	MemoryPercent float64  `json:"memoryPercent"`
-	MemoryBytes   uint64   `json:"memoryBytes"`
-	Threads       int      `json:"threads"`
-	Nice          int      `json:"nice"`
-	VmSize        uint64   `json:"vmSize,omitempty"`
-	VmRss         uint64   `json:"vmRss,omitempty"`
-	VmSwap        uint64   `json:"vmSwap,omitempty"`
-	IoReadBytes   uint64   `json:"ioReadBytes,omitempty"`
-	IoWriteBytes  uint64   `json:"ioWriteBytes,omitempty"`
-	Exe           string   `json:"exe,omitempty"`
-	Cwd           string   `json:"cwd,omitempty"`
-	Uptime        string   `json:"uptime,omitempty"`
-	Children      []int    `json:"children,omitempty"`
-	Connections   []Socket `json:"connections,omitempty"`
-	FDs           []FD     `json:"fds,omitempty"`
+	PID           int             `json:"pid"`
+	PPID          int             `json:"ppid"`
+	Name          string          `json:"name"`
+	Command       string          `json:"command"`
+	CommandLine   []string        `json:"commandLine,omitempty"`
+	State         string          `json:"state"`
+	User          string          `json:"user"`
+	UID           int             `json:"uid"`
+	GID           int             `json:"gid"`
+	CPUPercent    float64         `json:"cpuPercent"`
+	CPUPercentRaw float64         `json:"cpuPercentRaw"` // Percent of one core, can exceed 100
+	MemoryPercent float64         `json:"memoryPercent"`
+	MemoryBytes   uint64          `json:"memoryBytes"`
+	Threads       int             `json:"threads"`
+	FDCount       int             `json:"fdCount"` // Not collected on this platform
+	SocketCount   int             `json:"socketCount"`
+	Nice          int             `json:"nice"`
+	VmSize        uint64          `json:"vmSize,omitempty"`
+	VmRss         uint64          `json:"vmRss,omitempty"`
+	VmSwap        uint64          `json:"vmSwap,omitempty"`
+	IoReadBytes   uint64          `json:"ioReadBytes,omitempty"`
+	IoWriteBytes  uint64          `json:"ioWriteBytes,omitempty"`
+	Exe           string          `json:"exe,omitempty"`
+	Cwd           string          `json:"cwd,omitempty"`
+	Uptime        string          `json:"uptime,omitempty"`
+	Children      []int           `json:"children,omitempty"`
+	Connections   []Socket        `json:"connections,omitempty"`
+	FDs           []FD            `json:"fds,omitempty"`
+	Environ       []ProcessEnvVar `json:"environ,omitempty"`
+	Handles       int             `json:"handles,omitempty"` // Open kernel object handles, the Windows counterpart of fds
+	Modules       []ProcessModule `json:"modules,omitempty"`
+}
+
+// ProcessModule is a DLL or executable image loaded by a process
+type ProcessModule struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size uint64 `json:"size"`
 }
 
 type FD struct {
@@ -52,6 +66,7 @@ type FD struct {
 type ProcessList struct {
 	Processes  []ProcessInfo `json:"processes"`
 	TotalCount int           `json:"totalCount"`
+	Partial    bool          `json:"partial,omitempty"`
 }
 
 
@@ -66,6 +81,13 @@ var (
 )
 
 func GetProcessList() (ProcessList, error) {
+	return GetProcessListContext(context.Background())
+}
+
+// GetProcessListContext is GetProcessList bounded by ctx. Workers stop
+// picking up PIDs once ctx ends and whatever was read is returned with
+// Partial set; partial lists are not cached.
+func GetProcessListContext(ctx context.Context) (ProcessList, error) {
 	processListMu.Lock()
 	if !processListCachedAt.IsZero() && time.Since(processListCachedAt) < processListTTL {
 		cached := processListCache
@@ -116,6 +138,9 @@ func GetProcessList() (ProcessList, error) {
 		go func() {
 			defer wg.Done()
 			for pid := range in {
+				if ctx.Err() != nil {
+					continue
+				}
 				p, err := gpsproc.NewProcess(pid)
 				if err != nil {
 					continue
@@ -146,6 +171,7 @@ func GetProcessList() (ProcessList, error) {
 					cur = times.User + times.System
 					if prev, ok := prevSnapshot[pid]; ok && cur >= prev {
 						pi.CPUPercent = cur - prev
+						pi.CPUPercentRaw = pi.CPUPercent
 					}
 				}
 				out <- entry{pi: pi, cur: cur}
@@ -164,12 +190,16 @@ func GetProcessList() (ProcessList, error) {
 		list.Processes = append(list.Processes, e.pi)
 	}
 
+	list.TotalCount = len(list.Processes)
+	if ctx.Err() != nil {
+		list.Partial = true
+		return list, nil
+	}
+
 	prevProcCPUMu.Lock()
 	prevProcCPU = newPrev
 	prevProcCPUMu.Unlock()
 
-	list.TotalCount = len(list.Processes)
-
 	processListMu.Lock()
 	processListCache = list
 	processListCachedAt = time.Now()
@@ -194,6 +224,7 @@ func detailedProcessInfo(p *gpsproc.Process, totalMemory uint64) ProcessInfo {
 	}
 	if cpu, err := p.CPUPercent(); err == nil {
 		pi.CPUPercent = cpu
+		pi.CPUPercentRaw = cpu
 	}
 	if mem, err := p.MemoryInfo(); err == nil && mem != nil {
 		pi.MemoryBytes = mem.RSS
@@ -247,6 +278,20 @@ func GetProcessDetail(pid int) (*ProcessInfo, error) {
 
 	pi := detailedProcessInfo(p, totalMemory)
 
+	if handles, err := p.NumFDs(); err == nil {
+		pi.Handles = int(handles)
+	}
+	if environ, err := p.Environ(); err == nil {
+		for _, v := range environ {
+			parts := strings.SplitN(v, "=", 2)
+			// Skip the hidden per-drive cwd entries like "=C:=C:\\"
+			if len(parts) == 2 && parts[0] != "" {
+				pi.Environ = append(pi.Environ, ProcessEnvVar{Name: parts[0], Value: parts[1]})
+			}
+		}
+	}
+	pi.Modules = getProcessModules(pid)
+
 	if children, err := p.Children(); err == nil {
 		for _, c := range children {
 			pi.Children = append(pi.Children, int(c.Pid))
@@ -256,6 +301,34 @@ func GetProcessDetail(pid int) (*ProcessInfo, error) {
 	return &pi, nil
 }
 
+// GetProcessLimits returns an empty list: Windows has no per-process
+// rlimits, limits come from job objects instead
+func GetProcessLimits(pid int) ([]ProcessLimit, error) {
+	return []ProcessLimit{}, nil
+}
+
+// getProcessModules lists the modules loaded by pid. Reading them needs
+// the same rights as opening the process, so it is empty for protected
+// and other users' processes unless running elevated.
+func getProcessModules(pid int) []ProcessModule {
+	script := fmt.Sprintf(`(Get-Process -Id %d -ErrorAction Stop).Modules | ForEach-Object { "$($_.ModuleName)|$($_.FileName)|$($_.ModuleMemorySize)" }`, pid)
+	output, err := runPowerShell(script)
+	if err != nil {
+		return nil
+	}
+
+	var modules []ProcessModule
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) < 3 {
+			continue
+		}
+		size, _ := strconv.ParseUint(parts[2], 10, 64)
+		modules = append(modules, ProcessModule{Name: parts[0], Path: parts[1], Size: size})
+	}
+	return modules
+}
+
 func GetProcessesByUser(username string) ([]ProcessInfo, error) {
 	list, err := GetProcessList()
 	if err != nil {
@@ -281,15 +354,24 @@ func plainUser(s string) string {
 
 // KillProcess terminates a process on Windows.
 func KillProcess(pid int, signal syscall.Signal) error {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	if p, err := gpsproc.NewProcess(int32(pid)); err == nil {
 		if err := p.Kill(); err == nil {
 			return nil
 		}
 	}
-	cmd := exec.Command("taskkill", "/F", "/PID", strconv.Itoa(pid))
+	cmd := exec.CommandContext(ctx, "taskkill", "/F", "/PID", strconv.Itoa(pid))
 	return cmd.Run()
 }
 
+// IsKernelThread is always false: kernel threads aren't visible as
+// processes on this platform
+func IsKernelThread(pid int) bool {
+	return false
+}
+
 // ReniceProcess changes process priority on Windows.
 func ReniceProcess(pid int, priority int) error {
 	var priorityClass string
@@ -310,3 +392,8 @@ func ReniceProcess(pid int, priority int) error {
 	_, err := runPowerShell(script)
 	return err
 }
+
+// SetSchedPolicy is only supported on Linux.
+func SetSchedPolicy(pid int, policy string) error {
+	return fmt.Errorf("scheduling policies are not supported on this platform")
+}
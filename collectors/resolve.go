@@ -0,0 +1,96 @@
+package collectors
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	resolveWorkers = 16
+	resolveTimeout = 2 * time.Second // Per lookup
+)
+
+// ResolveRemoteHosts fills RemoteHost on each socket via reverse DNS. Lookups
+// run on a bounded worker pool until ctx ends; sockets whose address didn't
+// resolve in time are left as they are. skipped reports that the external
+// lookup budget ran out before every address was tried.
+func ResolveRemoteHosts(ctx context.Context, sockets []Socket) (skipped bool) {
+	// Resolve each distinct address once
+	pending := make(map[string]bool)
+	hosts := make(map[string]string)
+	for _, s := range sockets {
+		ip := net.ParseIP(s.RemoteAddr)
+		if ip == nil || ip.IsUnspecified() || ip.IsLoopback() {
+			continue
+		}
+		if host, ok := hostCache.get(s.RemoteAddr); ok {
+			hosts[s.RemoteAddr] = host.(string)
+			continue
+		}
+		pending[s.RemoteAddr] = true
+	}
+
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < resolveWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for addr := range jobs {
+				host, ok := reverseLookup(ctx, addr)
+				if !ok {
+					continue
+				}
+				hostCache.put(addr, host)
+				mu.Lock()
+				hosts[addr] = host
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for addr := range pending {
+		if !allowExternalLookup() {
+			skipped = true
+			break
+		}
+		select {
+		case jobs <- addr:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range sockets {
+		if host := hosts[sockets[i].RemoteAddr]; host != "" {
+			sockets[i].RemoteHost = host
+		}
+	}
+	return skipped
+}
+
+// reverseLookup returns the first PTR name for addr. ok is false when the
+// lookup didn't complete, so a timeout isn't cached as "no name".
+func reverseLookup(ctx context.Context, addr string) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	names, err := net.DefaultResolver.LookupAddr(ctx, addr)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return "", true
+		}
+		return "", false
+	}
+	if len(names) == 0 {
+		return "", true
+	}
+	return strings.TrimSuffix(names[0], "."), true
+}
@@ -0,0 +1,25 @@
+package collectors
+
+// SensorReading is one hwmon input. Temperatures are in °C, fans in RPM
+// and voltages in V; Max and Crit are the chip's thresholds when it has them.
+type SensorReading struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+	Max   float64 `json:"max,omitempty"`
+	Crit  float64 `json:"crit,omitempty"`
+}
+
+// SensorChip groups the readings of one hwmon chip, e.g. coretemp, nvme,
+// nct6775 or acpitz
+type SensorChip struct {
+	Name         string          `json:"name"`
+	Device       string          `json:"device,omitempty"` // Underlying device, e.g. nvme0 or 0000:00:18.3
+	Temperatures []SensorReading `json:"temperatures,omitempty"`
+	Fans         []SensorReading `json:"fans,omitempty"`
+	Voltages     []SensorReading `json:"voltages,omitempty"`
+}
+
+type SensorsInfo struct {
+	Available bool         `json:"available"`
+	Chips     []SensorChip `json:"chips"`
+}
@@ -0,0 +1,9 @@
+//go:build darwin
+
+package collectors
+
+// GetSensorsInfo reports sensors as unavailable: there is no hwmon-like
+// interface to read them without vendor tools
+func GetSensorsInfo() (SensorsInfo, error) {
+	return SensorsInfo{Available: false, Chips: []SensorChip{}}, nil
+}
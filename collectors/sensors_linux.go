@@ -0,0 +1,107 @@
+//go:build linux
+
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+const hwmonPath = "/sys/class/hwmon"
+
+// sensorInputRegex matches the hwmon input files we report: tempN_input,
+// fanN_input and inN_input
+var sensorInputRegex = regexp.MustCompile(`^(temp|fan|in)(\d+)_input$`)
+
+// GetSensorsInfo reads every hwmon chip, like lm-sensors does
+func GetSensorsInfo() (SensorsInfo, error) {
+	info := SensorsInfo{Chips: []SensorChip{}}
+
+	entries, err := os.ReadDir(hwmonPath)
+	if err != nil {
+		return info, nil
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(hwmonPath, entry.Name())
+		chip := SensorChip{Name: readSysfsString(dir, "name")}
+		if chip.Name == "" {
+			chip.Name = entry.Name()
+		}
+		if target, err := filepath.EvalSymlinks(filepath.Join(dir, "device")); err == nil {
+			chip.Device = filepath.Base(target)
+		}
+
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			m := sensorInputRegex.FindStringSubmatch(f.Name())
+			if m == nil {
+				continue
+			}
+			readSensor(&chip, dir, m[1], m[1]+m[2])
+		}
+
+		if len(chip.Temperatures)+len(chip.Fans)+len(chip.Voltages) == 0 {
+			continue
+		}
+		sortSensorReadings(chip.Temperatures)
+		sortSensorReadings(chip.Fans)
+		sortSensorReadings(chip.Voltages)
+		info.Chips = append(info.Chips, chip)
+	}
+
+	sort.SliceStable(info.Chips, func(i, j int) bool {
+		return info.Chips[i].Name < info.Chips[j].Name
+	})
+	info.Available = len(info.Chips) > 0
+	return info, nil
+}
+
+// readSensor reads prefix_input and its label/thresholds, e.g. temp1_input,
+// temp1_label, temp1_max, temp1_crit
+func readSensor(chip *SensorChip, dir, kind, prefix string) {
+	raw := readSysfsString(dir, prefix+"_input")
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return // Sensor present but not readable right now
+	}
+
+	reading := SensorReading{
+		Label: readSysfsString(dir, prefix+"_label"),
+		Max:   readSysfsFloat(dir, prefix+"_max"),
+		Crit:  readSysfsFloat(dir, prefix+"_crit"),
+		Value: value,
+	}
+	if reading.Label == "" {
+		reading.Label = prefix
+	}
+
+	switch kind {
+	case "temp":
+		// Millidegrees Celsius
+		reading.Value /= 1000
+		reading.Max /= 1000
+		reading.Crit /= 1000
+		chip.Temperatures = append(chip.Temperatures, reading)
+	case "fan":
+		chip.Fans = append(chip.Fans, reading)
+	case "in":
+		// Millivolts
+		reading.Value /= 1000
+		reading.Max /= 1000
+		reading.Crit /= 1000
+		chip.Voltages = append(chip.Voltages, reading)
+	}
+}
+
+func sortSensorReadings(readings []SensorReading) {
+	sort.SliceStable(readings, func(i, j int) bool {
+		return readings[i].Label < readings[j].Label
+	})
+}
@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"fmt"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -15,7 +16,9 @@ type Service struct {
 	SubState    string `json:"subState"` // running, waiting, etc.
 	PID         int    `json:"pid,omitempty"`
 	Enabled     bool   `json:"enabled"`
-	Type        string `json:"type,omitempty"` // system, user, global
+	// Always "enabled": launchctl list only shows loaded jobs
+	EnabledState string `json:"enabledState,omitempty"`
+	Type         string `json:"type,omitempty"` // system, user, global
 }
 
 type ServiceDetail struct {
@@ -64,9 +67,36 @@ func GetServicesInfo() (ServicesInfo, error) {
 	}, nil
 }
 
+// GetServicesInfoWithStats is GetServicesInfo: per-service memory and CPU
+// only come from systemd, launchd doesn't account them per service
+func GetServicesInfoWithStats() (ServicesInfo, error) {
+	return GetServicesInfo()
+}
+
+// GetFailedServices returns the jobs whose last exit status was non-zero
+// (negative for a signal), launchd's closest thing to a failed unit
+func GetFailedServices() (ServicesInfo, error) {
+	info, err := GetServicesInfo()
+	if err != nil || !info.Available {
+		return info, err
+	}
+
+	failed := []Service{}
+	for _, svc := range info.Services {
+		if svc.SubState != "0" && svc.SubState != "-" {
+			failed = append(failed, svc)
+		}
+	}
+	info.Services = failed
+	return info, nil
+}
+
 func getLaunchdServices() ([]Service, error) {
 	// Get system services
-	cmd := exec.Command("launchctl", "list")
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "launchctl", "list")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -110,12 +140,13 @@ func getLaunchdServices() ([]Service, error) {
 		}
 
 		services = append(services, Service{
-			Name:     label,
-			State:    state,
-			SubState: status,
-			PID:      pid,
-			Enabled:  true, // launchd services are typically enabled if they appear
-			Type:     serviceType,
+			Name:         label,
+			State:        state,
+			SubState:     status,
+			PID:          pid,
+			Enabled:      true, // launchd services are typically enabled if they appear
+			EnabledState: "enabled",
+			Type:         serviceType,
 		})
 	}
 
@@ -124,11 +155,14 @@ func getLaunchdServices() ([]Service, error) {
 
 func GetServiceDetail(name string) (*ServiceDetail, error) {
 	// Try to get service info
-	cmd := exec.Command("launchctl", "print", "system/"+name)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "launchctl", "print", "system/"+name)
 	output, err := cmd.Output()
 	if err != nil {
 		// Try user domain
-		cmd = exec.Command("launchctl", "print", "user/"+strconv.Itoa(getUID())+"/"+name)
+		cmd = exec.CommandContext(ctx, "launchctl", "print", "user/"+strconv.Itoa(getUID())+"/"+name)
 		output, err = cmd.Output()
 		if err != nil {
 			// Basic fallback
@@ -140,7 +174,10 @@ func GetServiceDetail(name string) (*ServiceDetail, error) {
 }
 
 func getUID() int {
-	cmd := exec.Command("id", "-u")
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "id", "-u")
 	output, _ := cmd.Output()
 	uid, _ := strconv.Atoi(strings.TrimSpace(string(output)))
 	return uid
@@ -148,7 +185,10 @@ func getUID() int {
 
 func getBasicServiceDetail(name string) (*ServiceDetail, error) {
 	// Get basic info from launchctl list
-	cmd := exec.Command("launchctl", "list", name)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "launchctl", "list", name)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -245,7 +285,10 @@ func parseLaunchctlPrint(name string, output string) (*ServiceDetail, error) {
 }
 
 func readFile(path string) (string, error) {
-	cmd := exec.Command("cat", path)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "cat", path)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -253,9 +296,18 @@ func readFile(path string) (string, error) {
 	return string(output), nil
 }
 
-func GetServiceLogs(name string, lines int) (string, error) {
+// GetServiceLogs returns recent log lines of a service. Filters need
+// journald, so any filter is an error here.
+func GetServiceLogs(name string, lines int, filter LogFilter) (string, error) {
+	if !filter.IsZero() {
+		return "", fmt.Errorf("log filters are only supported with journald")
+	}
+
 	// macOS uses unified logging
-	cmd := exec.Command("log", "show", "--predicate", "subsystem == '"+name+"'", "--last", strconv.Itoa(lines)+"m", "--style", "compact")
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "log", "show", "--predicate", "subsystem == '"+name+"'", "--last", strconv.Itoa(lines)+"m", "--style", "compact")
 	output, err := cmd.Output()
 	if err != nil {
 		// Fallback: try to find log files
@@ -265,24 +317,32 @@ func GetServiceLogs(name string, lines int) (string, error) {
 	return string(output), nil
 }
 
+// ServiceActions lists the actions ServiceAction supports on this platform
+var ServiceActions = []string{"start", "stop", "restart", "enable", "disable"}
+
 func ServiceAction(name string, action string) error {
+	ctx, cancel := contextWithTimeout(currentTimeouts().ServiceAction)
+	defer cancel()
+
 	var cmd *exec.Cmd
 
 	switch action {
 	case "start":
-		cmd = exec.Command("launchctl", "start", name)
+		cmd = exec.CommandContext(ctx, "launchctl", "start", name)
 	case "stop":
-		cmd = exec.Command("launchctl", "stop", name)
+		cmd = exec.CommandContext(ctx, "launchctl", "stop", name)
 	case "restart":
 		// launchd doesn't have restart, so stop then start
-		exec.Command("launchctl", "stop", name).Run()
-		cmd = exec.Command("launchctl", "start", name)
+		exec.CommandContext(ctx, "launchctl", "stop", name).Run()
+		cmd = exec.CommandContext(ctx, "launchctl", "start", name)
 	case "enable":
-		cmd = exec.Command("launchctl", "load", "-w", name)
+		cmd = exec.CommandContext(ctx, "launchctl", "load", "-w", name)
 	case "disable":
-		cmd = exec.Command("launchctl", "unload", "-w", name)
+		cmd = exec.CommandContext(ctx, "launchctl", "unload", "-w", name)
+	case "reload", "mask", "unmask":
+		return fmt.Errorf("%s is not supported by launchd", action)
 	default:
-		return nil
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
 	return cmd.Run()
@@ -3,7 +3,11 @@
 package collectors
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -15,7 +19,12 @@ type Service struct {
 	SubState    string `json:"subState"`    // dead, running, exited, etc.
 	PID         int    `json:"pid,omitempty"`
 	Enabled     bool   `json:"enabled"`
-	Type        string `json:"type,omitempty"` // simple, forking, oneshot, etc.
+	// systemctl is-enabled: enabled, disabled, static, masked, indirect...
+	EnabledState string `json:"enabledState,omitempty"`
+	Type         string `json:"type,omitempty"` // simple, forking, oneshot, etc.
+	// Only filled by GetServicesInfoWithStats, for running units
+	Memory       uint64 `json:"memory,omitempty"`       // Bytes, MemoryCurrent
+	CPUUsageNSec uint64 `json:"cpuUsageNsec,omitempty"` // Total CPU time
 }
 
 type ServiceDetail struct {
@@ -50,7 +59,7 @@ func GetServicesInfo() (ServicesInfo, error) {
 		return ServicesInfo{Available: false, Manager: "systemd"}, nil
 	}
 
-	services, err := getSystemdServices()
+	services, err := getSystemdServices("--all")
 	if err != nil {
 		return ServicesInfo{Available: true, Manager: "systemd"}, err
 	}
@@ -62,24 +71,118 @@ func GetServicesInfo() (ServicesInfo, error) {
 	}, nil
 }
 
-func getSystemdServices() ([]Service, error) {
+// serviceStatsBatch is how many units go into one systemctl show call
+const serviceStatsBatch = 200
+
+// GetServicesInfoWithStats is GetServicesInfo plus the memory and CPU time
+// of every running unit
+func GetServicesInfoWithStats() (ServicesInfo, error) {
+	info, err := GetServicesInfo()
+	if err != nil || !info.Available {
+		return info, err
+	}
+	addServiceStats(info.Services)
+	return info, nil
+}
+
+// addServiceStats fills Memory and CPUUsageNSec of the running services,
+// asking systemctl show for many units at once instead of one call each
+func addServiceStats(services []Service) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	index := make(map[string]*Service)
+	var units []string
+	for i := range services {
+		if services[i].PID == 0 && services[i].SubState != "running" {
+			continue
+		}
+		unit := services[i].Name + ".service"
+		index[unit] = &services[i]
+		units = append(units, unit)
+	}
+
+	for start := 0; start < len(units); start += serviceStatsBatch {
+		batch := units[start:min(start+serviceStatsBatch, len(units))]
+		args := append([]string{"show", "--no-pager", "-p", "Id,MemoryCurrent,CPUUsageNSec"}, batch...)
+		output, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+		if err != nil {
+			return
+		}
+
+		// One block of key=value lines per unit, separated by blank lines
+		for _, block := range strings.Split(string(output), "\n\n") {
+			props := make(map[string]string)
+			for _, line := range strings.Split(block, "\n") {
+				if key, value, ok := strings.Cut(line, "="); ok {
+					props[key] = value
+				}
+			}
+			svc, ok := index[props["Id"]]
+			if !ok {
+				continue
+			}
+			// Unset values read "[not set]" or, on older systemd, 2^64-1
+			if v, err := strconv.ParseUint(props["MemoryCurrent"], 10, 64); err == nil && v != math.MaxUint64 {
+				svc.Memory = v
+			}
+			if v, err := strconv.ParseUint(props["CPUUsageNSec"], 10, 64); err == nil && v != math.MaxUint64 {
+				svc.CPUUsageNSec = v
+			}
+		}
+	}
+}
+
+// GetFailedServices returns only the units systemd reports as failed, as
+// systemctl --failed does
+func GetFailedServices() (ServicesInfo, error) {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return ServicesInfo{Available: false, Manager: "systemd"}, nil
+	}
+
+	services, err := getSystemdServices("--state=failed")
+	if err != nil {
+		return ServicesInfo{Available: true, Manager: "systemd"}, err
+	}
+	if services == nil {
+		services = []Service{}
+	}
+
+	return ServicesInfo{
+		Available: true,
+		Manager:   "systemd",
+		Services:  services,
+	}, nil
+}
+
+// getSystemdServices lists the service units matching selector, either
+// "--all" or a "--state=" filter
+func getSystemdServices(selector string) ([]Service, error) {
 	// Get all services with their status
 	// Format: UNIT|LOAD|ACTIVE|SUB|DESCRIPTION|MAINPID
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--all", "--no-pager", "--no-legend",
+	// One deadline covers the whole listing including the per-unit lookups,
+	// so a wedged systemd yields partial data instead of blocking the refresh
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "systemctl", "list-units", "--type=service", selector, "--no-pager", "--no-legend",
 		"--plain", "--output=json")
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		// Fallback to text parsing if JSON not available
-		return getSystemdServicesText()
+		return getSystemdServicesText(ctx, selector)
 	}
 
 	// Parse JSON output
-	return parseSystemdJSON(output)
+	return parseSystemdJSON(ctx, selector, output)
 }
 
-func getSystemdServicesText() ([]Service, error) {
+func getSystemdServicesText(ctx context.Context, selector string) ([]Service, error) {
 	// Fallback: use text output
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--all", "--no-pager", "--no-legend", "--plain")
+	cmd := exec.CommandContext(ctx, "systemctl", "list-units", "--type=service", selector, "--no-pager", "--no-legend", "--plain")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -110,33 +213,34 @@ func getSystemdServicesText() ([]Service, error) {
 		// Get PID for running services
 		pid := 0
 		if state == "active" && subState == "running" {
-			pid = getServicePID(fields[0])
+			pid = getServicePID(ctx, fields[0])
 		}
 
 		// Check if enabled
-		enabled := isServiceEnabled(fields[0])
+		enabledState := serviceEnabledState(ctx, fields[0])
 
 		services = append(services, Service{
-			Name:        name,
-			Description: description,
-			State:       state,
-			SubState:    subState,
-			PID:         pid,
-			Enabled:     enabled,
+			Name:         name,
+			Description:  description,
+			State:        state,
+			SubState:     subState,
+			PID:          pid,
+			Enabled:      enabledState == "enabled",
+			EnabledState: enabledState,
 		})
 	}
 
 	return services, nil
 }
 
-func parseSystemdJSON(output []byte) ([]Service, error) {
+func parseSystemdJSON(ctx context.Context, selector string, output []byte) ([]Service, error) {
 	// systemctl --output=json returns JSON array
 	// Try text fallback since JSON format varies by systemd version
-	return getSystemdServicesText()
+	return getSystemdServicesText(ctx, selector)
 }
 
-func getServicePID(unit string) int {
-	cmd := exec.Command("systemctl", "show", "-p", "MainPID", "--value", unit)
+func getServicePID(ctx context.Context, unit string) int {
+	cmd := exec.CommandContext(ctx, "systemctl", "show", "-p", "MainPID", "--value", unit)
 	output, err := cmd.Output()
 	if err != nil {
 		return 0
@@ -146,10 +250,13 @@ func getServicePID(unit string) int {
 	return pid
 }
 
-func isServiceEnabled(unit string) bool {
-	cmd := exec.Command("systemctl", "is-enabled", unit)
+// serviceEnabledState returns the unit file state as systemctl is-enabled
+// prints it. is-enabled exits non-zero for anything but enabled, so the
+// exit status is ignored.
+func serviceEnabledState(ctx context.Context, unit string) string {
+	cmd := exec.CommandContext(ctx, "systemctl", "is-enabled", unit)
 	output, _ := cmd.Output()
-	return strings.TrimSpace(string(output)) == "enabled"
+	return strings.TrimSpace(string(output))
 }
 
 func GetServiceDetail(name string) (*ServiceDetail, error) {
@@ -159,7 +266,10 @@ func GetServiceDetail(name string) (*ServiceDetail, error) {
 	}
 
 	// Get all properties at once
-	cmd := exec.Command("systemctl", "show", unit, "--no-pager")
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "systemctl", "show", unit, "--no-pager")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -181,13 +291,14 @@ func GetServiceDetail(name string) (*ServiceDetail, error) {
 
 	detail := &ServiceDetail{
 		Service: Service{
-			Name:        name,
-			Description: props["Description"],
-			State:       strings.ToLower(props["ActiveState"]),
-			SubState:    strings.ToLower(props["SubState"]),
-			PID:         pid,
-			Enabled:     props["UnitFileState"] == "enabled",
-			Type:        props["Type"],
+			Name:         name,
+			Description:  props["Description"],
+			State:        strings.ToLower(props["ActiveState"]),
+			SubState:     strings.ToLower(props["SubState"]),
+			PID:          pid,
+			Enabled:      props["UnitFileState"] == "enabled",
+			EnabledState: props["UnitFileState"],
+			Type:         props["Type"],
 		},
 		UnitFile:      props["FragmentPath"],
 		ExecStart:     cleanExecPath(props["ExecStart"]),
@@ -249,7 +360,10 @@ func cleanExecPath(s string) string {
 }
 
 func readFile(path string) (string, error) {
-	cmd := exec.Command("cat", path)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "cat", path)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -257,42 +371,47 @@ func readFile(path string) (string, error) {
 	return string(output), nil
 }
 
-func GetServiceLogs(name string, lines int) (string, error) {
+// GetServiceLogs returns the last lines journal entries of a unit that
+// pass filter
+func GetServiceLogs(name string, lines int, filter LogFilter) (string, error) {
 	unit := name
 	if !strings.HasSuffix(unit, ".service") {
 		unit = name + ".service"
 	}
 
-	cmd := exec.Command("journalctl", "-u", unit, "-n", strconv.Itoa(lines), "--no-pager", "-o", "short-iso")
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	args := append([]string{"-u", unit, "-n", strconv.Itoa(lines), "--no-pager", "-o", "short-iso"}, filter.journalArgs()...)
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
 	output, err := cmd.Output()
 	if err != nil {
+		// journalctl -g exits 1 when nothing matched
+		if filter.Grep != "" && len(output) == 0 && ctx.Err() == nil {
+			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+				return "", nil
+			}
+		}
 		return "", err
 	}
 
 	return string(output), nil
 }
 
+// ServiceActions lists the actions ServiceAction supports on this platform
+var ServiceActions = []string{"start", "stop", "restart", "enable", "disable", "reload", "mask", "unmask"}
+
 func ServiceAction(name string, action string) error {
 	unit := name
 	if !strings.HasSuffix(unit, ".service") {
 		unit = name + ".service"
 	}
 
-	var cmd *exec.Cmd
-	switch action {
-	case "start":
-		cmd = exec.Command("systemctl", "start", unit)
-	case "stop":
-		cmd = exec.Command("systemctl", "stop", unit)
-	case "restart":
-		cmd = exec.Command("systemctl", "restart", unit)
-	case "enable":
-		cmd = exec.Command("systemctl", "enable", unit)
-	case "disable":
-		cmd = exec.Command("systemctl", "disable", unit)
-	default:
-		return nil
+	if !slices.Contains(ServiceActions, action) {
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
-	return cmd.Run()
+	ctx, cancel := contextWithTimeout(currentTimeouts().ServiceAction)
+	defer cancel()
+	return exec.CommandContext(ctx, "systemctl", action, unit).Run()
 }
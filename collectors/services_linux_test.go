@@ -0,0 +1,60 @@
+//go:build linux
+
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeSlowSystemctl puts a systemctl on PATH that hangs like a unit stuck
+// stopping, and shortens the timeouts so the test does not have to wait
+func fakeSlowSystemctl(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "systemctl"), []byte("#!/bin/sh\nexec sleep 60\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	SetTimeouts(Timeouts{Services: 200 * time.Millisecond, ServiceAction: 200 * time.Millisecond})
+	t.Cleanup(func() { SetTimeouts(DefaultTimeouts) })
+}
+
+func TestGetServicesInfoTimesOut(t *testing.T) {
+	fakeSlowSystemctl(t)
+
+	start := time.Now()
+	if _, err := GetServicesInfo(); err == nil {
+		t.Error("hung systemctl listing reported success")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetServicesInfo took %v, want it cut off at the timeout", elapsed)
+	}
+}
+
+func TestServiceActionTimesOut(t *testing.T) {
+	fakeSlowSystemctl(t)
+
+	start := time.Now()
+	if err := ServiceAction("test", "restart"); err == nil {
+		t.Error("hung systemctl reported success")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ServiceAction took %v, want it cut off at the timeout", elapsed)
+	}
+}
+
+func TestServiceActionUnknown(t *testing.T) {
+	fakeSlowSystemctl(t)
+
+	start := time.Now()
+	if err := ServiceAction("test", "frobnicate"); err == nil {
+		t.Error("unknown action accepted")
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Error("unknown action ran systemctl")
+	}
+}
@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,7 +24,9 @@ type Service struct {
 	SubState    string `json:"subState"` // Running, Stopped, Paused, etc.
 	PID         int    `json:"pid,omitempty"`
 	Enabled     bool   `json:"enabled"`
-	Type        string `json:"type,omitempty"` // Win32OwnProcess, Win32ShareProcess, etc.
+	// Start mode, lowercased: auto, manual, disabled, boot or system
+	EnabledState string `json:"enabledState,omitempty"`
+	Type         string `json:"type,omitempty"` // Win32OwnProcess, Win32ShareProcess, etc.
 }
 
 type ServiceDetail struct {
@@ -67,7 +70,7 @@ func GetServicesInfo() (ServicesInfo, error) {
 	}
 	servicesMu.Unlock()
 
-	services, err := getWindowsServices()
+	services, err := getWindowsServices("")
 	if err != nil {
 		return ServicesInfo{Available: true, Manager: "windows"}, err
 	}
@@ -86,14 +89,49 @@ func GetServicesInfo() (ServicesInfo, error) {
 	return info, nil
 }
 
-func getWindowsServices() ([]Service, error) {
+// GetServicesInfoWithStats is GetServicesInfo: per-service memory and CPU
+// only come from systemd, the service manager doesn't account them per service
+func GetServicesInfoWithStats() (ServicesInfo, error) {
+	return GetServicesInfo()
+}
+
+// GetFailedServices returns the services that stopped with an error. 1077
+// (ERROR_SERVICE_NEVER_STARTED) is the exit code of services that simply
+// were never started, so it doesn't count.
+func GetFailedServices() (ServicesInfo, error) {
+	services, err := getWindowsServices("State='Stopped' AND ExitCode<>0 AND ExitCode<>1077")
+	if err != nil {
+		return ServicesInfo{Available: true, Manager: "windows"}, err
+	}
+	if services == nil {
+		services = []Service{}
+	}
+
+	return ServicesInfo{
+		Available: true,
+		Manager:   "windows",
+		Services:  services,
+	}, nil
+}
+
+// getWindowsServices lists the services matching a WQL filter, or all of
+// them when filter is empty
+func getWindowsServices(filter string) ([]Service, error) {
+	query := "Get-CimInstance Win32_Service"
+	if filter != "" {
+		query += ` -Filter "` + filter + `"`
+	}
+
 	// Single Win32_Service query — avoids one PowerShell roundtrip per service.
-	script := `Get-CimInstance Win32_Service | ForEach-Object {
+	script := query + ` | ForEach-Object {
 		$desc = if ($_.Description) { $_.Description -replace '\|', '-' -replace "\r?\n", " " } else { "" }
 		"$($_.Name)|$($_.DisplayName)|$($_.State)|$($_.ProcessId)|$($_.StartMode)|$desc|$($_.ServiceType)"
 	}`
 
-	output, err := runPowerShell(script)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	output, err := runPowerShellContext(ctx, script)
 	if err != nil {
 		return nil, err
 	}
@@ -135,13 +173,14 @@ func getWindowsServices() ([]Service, error) {
 		}
 
 		services = append(services, Service{
-			Name:        name,
-			Description: description,
-			State:       state,
-			SubState:    status,
-			PID:         pid,
-			Enabled:     enabled,
-			Type:        serviceType,
+			Name:         name,
+			Description:  description,
+			State:        state,
+			SubState:     status,
+			PID:          pid,
+			Enabled:      enabled,
+			EnabledState: strings.ToLower(startType),
+			Type:         serviceType,
 		})
 	}
 
@@ -168,7 +207,10 @@ if ($svc) {
 	"Dependencies:" + $depList
 }`
 
-	output, err := runPowerShell(script)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	output, err := runPowerShellContext(ctx, script)
 	if err != nil {
 		return nil, err
 	}
@@ -215,6 +257,7 @@ if ($svc) {
 		case "StartMode":
 			detail.StartType = value
 			detail.Enabled = value == "Auto" || value == "Automatic"
+			detail.EnabledState = strings.ToLower(value)
 		case "ServiceType":
 			detail.ServiceType = value
 			detail.Type = value
@@ -236,11 +279,20 @@ if ($svc) {
 	return detail, nil
 }
 
-func GetServiceLogs(name string, lines int) (string, error) {
+// GetServiceLogs returns recent log lines of a service. Filters need
+// journald, so any filter is an error here.
+func GetServiceLogs(name string, lines int, filter LogFilter) (string, error) {
+	if !filter.IsZero() {
+		return "", fmt.Errorf("log filters are only supported with journald")
+	}
+
 	// Get Windows Event Log entries for the service
 	script := `Get-WinEvent -FilterHashtable @{LogName='System'; ProviderName='Service Control Manager'} -MaxEvents ` + strconv.Itoa(lines*2) + ` -ErrorAction SilentlyContinue | Where-Object { $_.Message -like '*` + name + `*' } | Select-Object -First ` + strconv.Itoa(lines) + ` | ForEach-Object { "$($_.TimeCreated.ToString('yyyy-MM-dd HH:mm:ss')) $($_.LevelDisplayName): $($_.Message)" }`
 
-	output, err := runPowerShell(script)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	output, err := runPowerShellContext(ctx, script)
 	if err != nil {
 		return "", err
 	}
@@ -248,6 +300,9 @@ func GetServiceLogs(name string, lines int) (string, error) {
 	return output, nil
 }
 
+// ServiceActions lists the actions ServiceAction supports on this platform
+var ServiceActions = []string{"start", "stop", "restart", "enable", "disable"}
+
 func ServiceAction(name string, action string) error {
 	var script string
 
@@ -262,10 +317,16 @@ func ServiceAction(name string, action string) error {
 		script = `Set-Service -Name '` + name + `' -StartupType Automatic`
 	case "disable":
 		script = `Set-Service -Name '` + name + `' -StartupType Disabled`
+	case "reload", "mask", "unmask":
+		return fmt.Errorf("%s is not supported for Windows services", action)
 	default:
-		return nil
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
-	_, err := runPowerShell(script)
+	// Service start/stop can legitimately take longer than a query, so
+	// actions get their own, longer timeout
+	ctx, cancel := contextWithTimeout(currentTimeouts().ServiceAction)
+	defer cancel()
+	_, err := runPowerShellContext(ctx, script)
 	return err
 }
@@ -41,8 +41,11 @@ type UsersListInfo struct {
 }
 
 func GetSessions() (SessionsInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Use 'who' command to get active sessions
-	cmd := exec.Command("who")
+	cmd := exec.CommandContext(ctx, "who")
 	output, err := cmd.Output()
 	if err != nil {
 		return SessionsInfo{}, err
@@ -90,6 +93,9 @@ func GetSessions() (SessionsInfo, error) {
 }
 
 func GetUsersList() (UsersListInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Read /etc/passwd on macOS
 	file, err := os.Open("/etc/passwd")
 	if err != nil {
@@ -125,7 +131,7 @@ func GetUsersList() (UsersListInfo, error) {
 		}
 
 		// Get groups for this user
-		if gids, err := exec.Command("groups", user.Username).Output(); err == nil {
+		if gids, err := exec.CommandContext(ctx, "groups", user.Username).Output(); err == nil {
 			line := strings.TrimSpace(string(gids))
 			parts := strings.SplitN(line, ":", 2)
 			if len(parts) >= 2 {
@@ -41,12 +41,15 @@ type UsersListInfo struct {
 }
 
 func GetSessions() (SessionsInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Use 'who' command to get active sessions
-	cmd := exec.Command("who", "-u")
+	cmd := exec.CommandContext(ctx, "who", "-u")
 	output, err := cmd.Output()
-	if err != nil {
+	if err != nil && ctx.Err() == nil {
 		// Try without -u flag
-		cmd = exec.Command("who")
+		cmd = exec.CommandContext(ctx, "who")
 		output, err = cmd.Output()
 		if err != nil {
 			return SessionsInfo{}, err
@@ -0,0 +1,144 @@
+package collectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SmartInfo struct {
+	Device             string  `json:"device"`
+	Model              string  `json:"model,omitempty"`
+	Health             string  `json:"health"` // PASSED, FAILED
+	Temperature        float64 `json:"temperature,omitempty"`
+	PowerOnHours       uint64  `json:"powerOnHours,omitempty"`
+	ReallocatedSectors uint64  `json:"reallocatedSectors"`
+}
+
+type smartCacheEntry struct {
+	info       *SmartInfo
+	at         time.Time
+	refreshing bool // A background smartctl run is in flight
+}
+
+var (
+	smartCache    = map[string]smartCacheEntry{}
+	smartCacheMu  sync.Mutex
+	smartCacheTTL = 5 * time.Minute
+)
+
+// GetDiskSmart runs smartctl against a device and returns its health summary.
+// Returns nil without error when smartctl is missing or the device has no SMART support.
+func GetDiskSmart(device string) (*SmartInfo, error) {
+	return getDiskSmart(device, false)
+}
+
+// getDiskSmart is GetDiskSmart; with skipStandby a spun-down disk is left
+// asleep and reported as having no SMART data
+func getDiskSmart(device string, skipStandby bool) (*SmartInfo, error) {
+	if _, err := exec.LookPath("smartctl"); err != nil {
+		return nil, nil
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	args := []string{"-H", "-A", "-j"}
+	if skipStandby {
+		args = append(args, "-n", "standby")
+	}
+	args = append(args, device)
+
+	// smartctl uses its exit code as a bitmask of warnings, so only trust
+	// whether the JSON parses.
+	output, _ := exec.CommandContext(ctx, "smartctl", args...).Output()
+	if len(output) == 0 {
+		return nil, fmt.Errorf("smartctl returned no output for %s", device)
+	}
+
+	var raw struct {
+		ModelName   string `json:"model_name"`
+		SmartStatus *struct {
+			Passed bool `json:"passed"`
+		} `json:"smart_status"`
+		Temperature struct {
+			Current float64 `json:"current"`
+		} `json:"temperature"`
+		PowerOnTime struct {
+			Hours uint64 `json:"hours"`
+		} `json:"power_on_time"`
+		AtaSmartAttributes struct {
+			Table []struct {
+				ID  int `json:"id"`
+				Raw struct {
+					Value uint64 `json:"value"`
+				} `json:"raw"`
+			} `json:"table"`
+		} `json:"ata_smart_attributes"`
+	}
+
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, err
+	}
+
+	// No smart_status means the device doesn't support SMART (or we lack permissions)
+	if raw.SmartStatus == nil {
+		return nil, nil
+	}
+
+	info := &SmartInfo{
+		Device:       device,
+		Model:        strings.TrimSpace(raw.ModelName),
+		Health:       "FAILED",
+		Temperature:  raw.Temperature.Current,
+		PowerOnHours: raw.PowerOnTime.Hours,
+	}
+	if raw.SmartStatus.Passed {
+		info.Health = "PASSED"
+	}
+
+	for _, attr := range raw.AtaSmartAttributes.Table {
+		if attr.ID == 5 { // Reallocated_Sector_Ct
+			info.ReallocatedSectors = attr.Raw.Value
+			break
+		}
+	}
+
+	return info, nil
+}
+
+// getCachedDiskSmart returns the last SMART data seen for a device without
+// waiting on smartctl. Once that is older than smartCacheTTL a background
+// run refreshes it, so the disk refresh never blocks on slow devices.
+func getCachedDiskSmart(device string) *SmartInfo {
+	smartCacheMu.Lock()
+	defer smartCacheMu.Unlock()
+
+	entry, ok := smartCache[device]
+	if (!ok || time.Since(entry.at) >= smartCacheTTL) && !entry.refreshing {
+		entry.refreshing = true
+		smartCache[device] = entry
+		go refreshDiskSmart(device)
+	}
+	return entry.info
+}
+
+// refreshDiskSmart runs smartctl for getCachedDiskSmart. Disks in standby
+// are not woken; they report nothing, so the previous data is kept.
+func refreshDiskSmart(device string) {
+	info, _ := getDiskSmart(device, true)
+
+	smartCacheMu.Lock()
+	defer smartCacheMu.Unlock()
+
+	entry := smartCache[device]
+	if info != nil {
+		entry.info = info
+	}
+	entry.at = time.Now()
+	entry.refreshing = false
+	smartCache[device] = entry
+}
@@ -0,0 +1,59 @@
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetCachedDiskSmartRefreshesInBackground(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake smartctl is a shell script")
+	}
+
+	// A slow smartctl that records its arguments
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := `#!/bin/sh
+sleep 0.3
+echo "$@" > '` + argsFile + `'
+echo '{"model_name":"Fake SSD","smart_status":{"passed":true},"temperature":{"current":41}}'
+`
+	if err := os.WriteFile(filepath.Join(dir, "smartctl"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	const device = "/dev/fake-smart-test"
+	t.Cleanup(func() {
+		smartCacheMu.Lock()
+		delete(smartCache, device)
+		smartCacheMu.Unlock()
+	})
+
+	start := time.Now()
+	if info := getCachedDiskSmart(device); info != nil {
+		t.Errorf("first call returned %+v before smartctl finished", info)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("getCachedDiskSmart waited %v for smartctl", elapsed)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var info *SmartInfo
+	for info == nil && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+		info = getCachedDiskSmart(device)
+	}
+	if info == nil || info.Model != "Fake SSD" || info.Temperature != 41 {
+		t.Fatalf("cached info %+v, want the background result", info)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-n standby") {
+		t.Errorf("smartctl ran with %q, want -n standby", strings.TrimSpace(string(args)))
+	}
+}
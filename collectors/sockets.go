@@ -0,0 +1,201 @@
+package collectors
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// ProcessSockets is the socket usage of one process
+type ProcessSockets struct {
+	PID         int    `json:"pid"`
+	Name        string `json:"name"`
+	Established int    `json:"established"`
+	Listening   int    `json:"listening"`
+	OtherTCP    int    `json:"otherTcp"` // TIME_WAIT, CLOSE_WAIT, SYN_SENT...
+	UDP         int    `json:"udp"`
+	Total       int    `json:"total"`
+	RemotePeers int    `json:"remotePeers"` // Distinct remote addresses
+}
+
+type ProcessSocketsInfo struct {
+	Processes []ProcessSockets `json:"processes"`
+	// Sockets whose owner is unknown: kernel sockets, other users'
+	// processes without root, or platforms like macOS where netstat
+	// doesn't report PIDs
+	Unattributed int  `json:"unattributed"`
+	Partial      bool `json:"partial,omitempty"`
+}
+
+// AggregateSocketsByProcess counts TCP and UDP sockets per owning process,
+// busiest first
+func AggregateSocketsByProcess(tcp, udp []Socket) ProcessSocketsInfo {
+	info := ProcessSocketsInfo{Processes: []ProcessSockets{}}
+	byPID := make(map[int]*ProcessSockets)
+	peers := make(map[int]map[string]bool)
+
+	entry := func(s Socket) *ProcessSockets {
+		if s.PID <= 0 {
+			info.Unattributed++
+			return nil
+		}
+		p, ok := byPID[s.PID]
+		if !ok {
+			p = &ProcessSockets{PID: s.PID, Name: s.ProcessName}
+			byPID[s.PID] = p
+			peers[s.PID] = make(map[string]bool)
+		}
+		p.Total++
+		if s.RemoteAddr != "" && s.RemotePort != 0 {
+			peers[s.PID][s.RemoteAddr] = true
+		}
+		return p
+	}
+
+	for _, s := range tcp {
+		p := entry(s)
+		if p == nil {
+			continue
+		}
+		switch s.State {
+		case "ESTABLISHED":
+			p.Established++
+		case "LISTEN", "LISTENING":
+			p.Listening++
+		default:
+			p.OtherTCP++
+		}
+	}
+	for _, s := range udp {
+		if p := entry(s); p != nil {
+			p.UDP++
+		}
+	}
+
+	for _, p := range byPID {
+		p.RemotePeers = len(peers[p.PID])
+		info.Processes = append(info.Processes, *p)
+	}
+	sort.Slice(info.Processes, func(i, j int) bool {
+		a, b := info.Processes[i], info.Processes[j]
+		if a.Established != b.Established {
+			return a.Established > b.Established
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.PID < b.PID
+	})
+	return info
+}
+
+// SocketFilter narrows a socket listing. Zero fields match everything.
+type SocketFilter struct {
+	State string // e.g. LISTEN or TIME_WAIT, case-insensitive
+	Proto string // tcp, udp or unix; tcp also matches tcp6
+	Port  int    // Local or remote port
+	PID   int
+}
+
+func (f SocketFilter) matches(s Socket) bool {
+	if f.State != "" && !strings.EqualFold(normalizeSocketState(s.State), normalizeSocketState(f.State)) {
+		return false
+	}
+	if f.Proto != "" && !strings.HasPrefix(s.Protocol, strings.ToLower(f.Proto)) {
+		return false
+	}
+	if f.Port != 0 && s.LocalPort != f.Port && s.RemotePort != f.Port {
+		return false
+	}
+	return f.PID == 0 || s.PID == f.PID
+}
+
+// normalizeSocketState maps Windows' LISTENING to LISTEN so one filter
+// works everywhere
+func normalizeSocketState(state string) string {
+	if strings.EqualFold(state, "LISTENING") {
+		return "LISTEN"
+	}
+	return state
+}
+
+func filterSockets(sockets []Socket, f SocketFilter) []Socket {
+	filtered := []Socket{}
+	for _, s := range sockets {
+		if f.matches(s) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// countSocketStates tallies TCP sockets by state
+func countSocketStates(tcp []Socket) map[string]int {
+	counts := make(map[string]int)
+	for _, s := range tcp {
+		if s.State != "" {
+			counts[s.State]++
+		}
+	}
+	return counts
+}
+
+// ListeningPort is a socket accepting traffic: a listening TCP socket or
+// an unconnected UDP one
+type ListeningPort struct {
+	Protocol    string `json:"protocol"`
+	Address     string `json:"address"`
+	Port        int    `json:"port"`
+	PID         int    `json:"pid"`
+	ProcessName string `json:"processName"`
+	Exposed     bool   `json:"exposed"` // Bound beyond loopback
+}
+
+// ListeningPorts picks the listening sockets out of a socket listing,
+// one entry per protocol, address, port and process, sorted by port
+func ListeningPorts(tcp, udp []Socket) []ListeningPort {
+	ports := []ListeningPort{}
+	seen := make(map[string]bool)
+
+	add := func(s Socket) {
+		key := fmt.Sprintf("%s|%s|%d|%d", s.Protocol, s.LocalAddr, s.LocalPort, s.PID)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		ip := net.ParseIP(strings.Trim(s.LocalAddr, "[]"))
+		ports = append(ports, ListeningPort{
+			Protocol:    s.Protocol,
+			Address:     s.LocalAddr,
+			Port:        s.LocalPort,
+			PID:         s.PID,
+			ProcessName: s.ProcessName,
+			Exposed:     ip == nil || !ip.IsLoopback(),
+		})
+	}
+
+	for _, s := range tcp {
+		if normalizeSocketState(s.State) == "LISTEN" {
+			add(s)
+		}
+	}
+	for _, s := range udp {
+		if s.LocalPort != 0 && s.RemotePort == 0 {
+			add(s)
+		}
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.Address < b.Address
+	})
+	return ports
+}
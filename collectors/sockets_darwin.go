@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"context"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -13,6 +14,7 @@ type Socket struct {
 	LocalAddr   string `json:"localAddr"`
 	LocalPort   int    `json:"localPort"`
 	RemoteAddr  string `json:"remoteAddr"`
+	RemoteHost  string `json:"remoteHost,omitempty"` // Reverse DNS, only with ?resolve=true
 	RemotePort  int    `json:"remotePort"`
 	State       string `json:"state"`
 	PID         int    `json:"pid"`
@@ -20,26 +22,46 @@ type Socket struct {
 }
 
 type SocketInfo struct {
-	TCP         []Socket `json:"tcp"`
-	UDP         []Socket `json:"udp"`
-	Total       int      `json:"total"`
-	Listen      int      `json:"listen"`
-	Established int      `json:"established"`
+	TCP            []Socket       `json:"tcp"`
+	UDP            []Socket       `json:"udp"`
+	Total          int            `json:"total"`
+	Listen         int            `json:"listen"`
+	Established    int            `json:"established"`
+	Partial        bool           `json:"partial,omitempty"`
+	StateCounts    map[string]int `json:"stateCounts"`              // TCP sockets by state, before any filter
+	ResolveSkipped bool           `json:"resolveSkipped,omitempty"` // ?resolve=true ran out of lookup budget
+}
+
+// Filter keeps only the sockets matching f. Totals and StateCounts still
+// describe the full set.
+func (info *SocketInfo) Filter(f SocketFilter) {
+	info.TCP = filterSockets(info.TCP, f)
+	info.UDP = filterSockets(info.UDP, f)
 }
 
 func GetSocketInfo() (SocketInfo, error) {
+	return GetSocketInfoContext(context.Background())
+}
+
+// GetSocketInfoContext is GetSocketInfo bounded by ctx. If ctx ends between
+// the TCP and UDP listings, the TCP sockets are returned with Partial set.
+func GetSocketInfoContext(ctx context.Context) (SocketInfo, error) {
 	info := SocketInfo{}
 
 	// Use netstat to get socket info
 	// Note: lsof gives better info but requires root for some connections
-	out, err := exec.Command("netstat", "-an", "-p", "tcp").Output()
+	out, err := exec.CommandContext(ctx, "netstat", "-an", "-p", "tcp").Output()
 	if err == nil {
 		info.TCP = parseNetstatOutput(string(out), "tcp")
+	} else if ctx.Err() != nil {
+		return info, ctx.Err()
 	}
 
-	out, err = exec.Command("netstat", "-an", "-p", "udp").Output()
+	out, err = exec.CommandContext(ctx, "netstat", "-an", "-p", "udp").Output()
 	if err == nil {
 		info.UDP = parseNetstatOutput(string(out), "udp")
+	} else if ctx.Err() != nil {
+		info.Partial = true
 	}
 
 	// Count stats
@@ -52,6 +74,7 @@ func GetSocketInfo() (SocketInfo, error) {
 	}
 
 	info.Total = len(info.TCP) + len(info.UDP)
+	info.StateCounts = countSocketStates(info.TCP)
 
 	return info, nil
 }
@@ -110,10 +133,13 @@ func parseAddress(addr string) (string, int) {
 }
 
 func GetSocketsByPID(pid int) ([]Socket, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Use lsof to get connections for a specific PID
 	var sockets []Socket
 
-	out, err := exec.Command("lsof", "-i", "-n", "-P", "-p", strconv.Itoa(pid)).Output()
+	out, err := exec.CommandContext(ctx, "lsof", "-i", "-n", "-P", "-p", strconv.Itoa(pid)).Output()
 	if err != nil {
 		return sockets, nil // May fail without root
 	}
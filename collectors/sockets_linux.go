@@ -4,6 +4,7 @@ package collectors
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,6 +17,7 @@ type Socket struct {
 	LocalAddr  string `json:"localAddr"`
 	LocalPort  int    `json:"localPort"`
 	RemoteAddr string `json:"remoteAddr"`
+	RemoteHost string `json:"remoteHost,omitempty"` // Reverse DNS, only with ?resolve=true
 	RemotePort int    `json:"remotePort"`
 	State      string `json:"state"`
 	PID        int    `json:"pid"`
@@ -30,9 +32,27 @@ type SocketInfo struct {
 	Total  int      `json:"total"`
 	Listen int      `json:"listen"`
 	Established int `json:"established"`
+	Partial bool    `json:"partial,omitempty"` // Deadline hit while mapping sockets to processes
+	StateCounts map[string]int `json:"stateCounts"` // TCP sockets by state, before any filter
+	ResolveSkipped bool `json:"resolveSkipped,omitempty"` // ?resolve=true ran out of lookup budget
+}
+
+// Filter keeps only the sockets matching f. Totals and StateCounts still
+// describe the full set.
+func (info *SocketInfo) Filter(f SocketFilter) {
+	info.TCP = filterSockets(info.TCP, f)
+	info.UDP = filterSockets(info.UDP, f)
+	info.Unix = filterSockets(info.Unix, f)
 }
 
 func GetSocketInfo() (*SocketInfo, error) {
+	return GetSocketInfoContext(context.Background())
+}
+
+// GetSocketInfoContext is GetSocketInfo bounded by ctx. The inode walk over
+// /proc/*/fd is the slow part; if ctx ends during it, sockets are still
+// listed but some will lack their owning process and Partial is set.
+func GetSocketInfoContext(ctx context.Context) (*SocketInfo, error) {
 	info := &SocketInfo{
 		TCP:  []Socket{},
 		UDP:  []Socket{},
@@ -40,7 +60,10 @@ func GetSocketInfo() (*SocketInfo, error) {
 	}
 
 	// Build inode to PID/name mapping
-	inodeToPID := buildInodeMap()
+	inodeToPID := make(map[string]struct{ pid int; name string })
+	if enabledFeatures().SocketProcesses {
+		inodeToPID, info.Partial = buildInodeMap(ctx)
+	}
 
 	// Parse TCP sockets
 	tcpSockets := parseNetSockets("/proc/net/tcp", "tcp", inodeToPID)
@@ -59,6 +82,7 @@ func GetSocketInfo() (*SocketInfo, error) {
 
 	// Calculate totals
 	info.Total = len(info.TCP) + len(info.UDP) + len(info.Unix)
+	info.StateCounts = countSocketStates(info.TCP)
 
 	for _, s := range info.TCP {
 		if s.State == "LISTEN" {
@@ -71,15 +95,21 @@ func GetSocketInfo() (*SocketInfo, error) {
 	return info, nil
 }
 
-func buildInodeMap() map[string]struct{ pid int; name string } {
+// buildInodeMap maps socket inodes to their owning process. The bool result
+// is true when ctx ended before every process was visited.
+func buildInodeMap(ctx context.Context) (map[string]struct{ pid int; name string }, bool) {
 	inodeMap := make(map[string]struct{ pid int; name string })
 
 	entries, err := os.ReadDir("/proc")
 	if err != nil {
-		return inodeMap
+		return inodeMap, false
 	}
 
 	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return inodeMap, true
+		}
+
 		if !entry.IsDir() {
 			continue
 		}
@@ -117,7 +147,7 @@ func buildInodeMap() map[string]struct{ pid int; name string } {
 		}
 	}
 
-	return inodeMap
+	return inodeMap, false
 }
 
 func parseNetSockets(path, protocol string, inodeMap map[string]struct{ pid int; name string }) []Socket {
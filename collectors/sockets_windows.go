@@ -3,6 +3,7 @@
 package collectors
 
 import (
+	"context"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -13,6 +14,7 @@ type Socket struct {
 	LocalAddr   string `json:"localAddr"`
 	LocalPort   int    `json:"localPort"`
 	RemoteAddr  string `json:"remoteAddr"`
+	RemoteHost  string `json:"remoteHost,omitempty"` // Reverse DNS, only with ?resolve=true
 	RemotePort  int    `json:"remotePort"`
 	State       string `json:"state"`
 	PID         int    `json:"pid"`
@@ -20,19 +22,38 @@ type Socket struct {
 }
 
 type SocketInfo struct {
-	TCP         []Socket `json:"tcp"`
-	UDP         []Socket `json:"udp"`
-	Total       int      `json:"total"`
-	Listen      int      `json:"listen"`
-	Established int      `json:"established"`
+	TCP            []Socket       `json:"tcp"`
+	UDP            []Socket       `json:"udp"`
+	Total          int            `json:"total"`
+	Listen         int            `json:"listen"`
+	Established    int            `json:"established"`
+	Partial        bool           `json:"partial,omitempty"`
+	StateCounts    map[string]int `json:"stateCounts"`              // TCP sockets by state, before any filter
+	ResolveSkipped bool           `json:"resolveSkipped,omitempty"` // ?resolve=true ran out of lookup budget
+}
+
+// Filter keeps only the sockets matching f. Totals and StateCounts still
+// describe the full set.
+func (info *SocketInfo) Filter(f SocketFilter) {
+	info.TCP = filterSockets(info.TCP, f)
+	info.UDP = filterSockets(info.UDP, f)
 }
 
 func GetSocketInfo() (SocketInfo, error) {
+	return GetSocketInfoContext(context.Background())
+}
+
+// GetSocketInfoContext is GetSocketInfo bounded by ctx; netstat is killed
+// if ctx ends first.
+func GetSocketInfoContext(ctx context.Context) (SocketInfo, error) {
 	info := SocketInfo{}
 
 	// Use netstat to get connections
-	out, err := exec.Command("netstat", "-ano").Output()
+	out, err := exec.CommandContext(ctx, "netstat", "-ano").Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return info, ctx.Err()
+		}
 		return info, err
 	}
 
@@ -83,6 +104,7 @@ func GetSocketInfo() (SocketInfo, error) {
 	}
 
 	info.Total = len(info.TCP) + len(info.UDP)
+	info.StateCounts = countSocketStates(info.TCP)
 	return info, nil
 }
 
@@ -0,0 +1,38 @@
+package collectors
+
+import "sort"
+
+// maxSwapProcesses caps how many entries /api/processes/swap can return
+const maxSwapProcesses = 100
+
+// SwapProcess is a process with part of its memory swapped out
+type SwapProcess struct {
+	PID       int    `json:"pid"`
+	Name      string `json:"name"`
+	User      string `json:"user"`
+	SwapBytes uint64 `json:"swapBytes"`
+}
+
+type SwapProcessesInfo struct {
+	Available bool          `json:"available"`
+	TotalSwap uint64        `json:"totalSwap"` // Swap used by all scanned processes
+	Processes []SwapProcess `json:"processes"`
+	Partial   bool          `json:"partial,omitempty"` // Deadline hit before every PID was read
+}
+
+// topSwapProcesses sorts by swap usage, largest first, and keeps limit
+func topSwapProcesses(procs []SwapProcess, limit int) []SwapProcess {
+	sort.Slice(procs, func(i, j int) bool {
+		if procs[i].SwapBytes != procs[j].SwapBytes {
+			return procs[i].SwapBytes > procs[j].SwapBytes
+		}
+		return procs[i].PID < procs[j].PID
+	})
+	if limit <= 0 || limit > maxSwapProcesses {
+		limit = maxSwapProcesses
+	}
+	if len(procs) > limit {
+		procs = procs[:limit]
+	}
+	return procs
+}
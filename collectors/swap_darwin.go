@@ -0,0 +1,11 @@
+//go:build darwin
+
+package collectors
+
+import "context"
+
+// GetSwapProcesses reports per-process swap as unavailable: the OS does not
+// expose how much of each process is swapped out
+func GetSwapProcesses(ctx context.Context, limit int) (SwapProcessesInfo, error) {
+	return SwapProcessesInfo{Available: false, Processes: []SwapProcess{}}, nil
+}
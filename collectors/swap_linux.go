@@ -0,0 +1,71 @@
+//go:build linux
+
+package collectors
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetSwapProcesses returns the limit processes using the most swap. Only
+// /proc/<pid>/status is read per process (its VmSwap line), which is far
+// cheaper than walking smaps; kernel threads have no VmSwap and are skipped.
+func GetSwapProcesses(ctx context.Context, limit int) (SwapProcessesInfo, error) {
+	info := SwapProcessesInfo{Available: true, Processes: []SwapProcess{}}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return info, err
+	}
+
+	var procs []SwapProcess
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			info.Partial = true
+			break
+		}
+
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		proc, ok := readSwapStatus(pid)
+		if !ok || proc.SwapBytes == 0 {
+			continue
+		}
+		info.TotalSwap += proc.SwapBytes
+		procs = append(procs, proc)
+	}
+
+	info.Processes = append(info.Processes, topSwapProcesses(procs, limit)...)
+	return info, nil
+}
+
+func readSwapStatus(pid int) (SwapProcess, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/status")
+	if err != nil {
+		return SwapProcess{}, false
+	}
+
+	proc := SwapProcess{PID: pid}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "Name:":
+			proc.Name = fields[1]
+		case "Uid:":
+			uid, _ := strconv.Atoi(fields[1])
+			proc.User = getUsername(uid)
+		case "VmSwap:":
+			kb, _ := strconv.ParseUint(fields[1], 10, 64)
+			proc.SwapBytes = kb * 1024
+		}
+	}
+	return proc, true
+}
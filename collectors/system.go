@@ -0,0 +1,41 @@
+package collectors
+
+import (
+	"os"
+	"runtime"
+	"time"
+)
+
+// SystemInfo holds the static facts about the host
+type SystemInfo struct {
+	Hostname      string `json:"hostname"`
+	OS            string `json:"os"`                      // linux, darwin, windows
+	Distro        string `json:"distro,omitempty"`        // e.g. "Ubuntu 24.04.1 LTS", "macOS 14.5"
+	Kernel        string `json:"kernel,omitempty"`        // Release, e.g. "6.8.0-45-generic"
+	KernelVersion string `json:"kernelVersion,omitempty"` // Build string, e.g. "#45-Ubuntu SMP ..."
+	Architecture  string `json:"architecture"`
+	BootTime      int64  `json:"bootTime"` // Unix seconds
+	Uptime        string `json:"uptime"`
+	CPUModel      string `json:"cpuModel,omitempty"`
+	CPUs          int    `json:"cpus"` // Logical CPUs
+	PhysicalCores int    `json:"physicalCores,omitempty"`
+}
+
+// newSystemInfo fills the fields every platform gets the same way
+func newSystemInfo() SystemInfo {
+	hostname, _ := os.Hostname()
+	return SystemInfo{
+		Hostname:     hostname,
+		OS:           runtime.GOOS,
+		Architecture: runtime.GOARCH,
+		CPUs:         runtime.NumCPU(),
+	}
+}
+
+func (s *SystemInfo) setBootTime(boot int64) {
+	if boot <= 0 {
+		return
+	}
+	s.BootTime = boot
+	s.Uptime = formatCronDuration(time.Since(time.Unix(boot, 0)))
+}
@@ -0,0 +1,51 @@
+//go:build darwin
+
+package collectors
+
+import (
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// kern.boottime prints as "{ sec = 1718000000, usec = 0 } Mon Jun 10 ..."
+var bootTimeRegex = regexp.MustCompile(`sec = (\d+)`)
+
+// GetSystemInfo reads sysctl and sw_vers
+func GetSystemInfo() (SystemInfo, error) {
+	info := newSystemInfo()
+
+	if release, err := syscall.Sysctl("kern.osrelease"); err == nil {
+		info.Kernel = release
+	}
+	if version, err := syscall.Sysctl("kern.version"); err == nil {
+		info.KernelVersion = version
+	}
+	if machine, err := syscall.Sysctl("hw.machine"); err == nil && machine != "" {
+		info.Architecture = machine
+	}
+	if model, err := syscall.Sysctl("machdep.cpu.brand_string"); err == nil {
+		info.CPUModel = model
+	}
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.physicalcpu").Output(); err == nil {
+		info.PhysicalCores, _ = strconv.Atoi(strings.TrimSpace(string(out)))
+	}
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "kern.boottime").Output(); err == nil {
+		if m := bootTimeRegex.FindStringSubmatch(string(out)); m != nil {
+			boot, _ := strconv.ParseInt(m[1], 10, 64)
+			info.setBootTime(boot)
+		}
+	}
+
+	name, _ := exec.CommandContext(ctx, "sw_vers", "-productName").Output()
+	version, _ := exec.CommandContext(ctx, "sw_vers", "-productVersion").Output()
+	info.Distro = strings.TrimSpace(strings.TrimSpace(string(name)) + " " + strings.TrimSpace(string(version)))
+
+	return info, nil
+}
@@ -0,0 +1,119 @@
+//go:build linux
+
+package collectors
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// GetSystemInfo reads uname, /etc/os-release, /proc/stat and /proc/cpuinfo
+func GetSystemInfo() (SystemInfo, error) {
+	info := newSystemInfo()
+
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err == nil {
+		info.Kernel = utsString(uts.Release[:])
+		info.KernelVersion = utsString(uts.Version[:])
+		if machine := utsString(uts.Machine[:]); machine != "" {
+			info.Architecture = machine
+		}
+	}
+
+	info.Distro = readOSRelease()
+	info.setBootTime(readBootTime())
+	info.CPUModel, info.PhysicalCores = readCPUTopology()
+
+	return info, nil
+}
+
+// utsString converts a NUL-terminated utsname field. Its element type is
+// int8 or uint8 depending on the architecture.
+func utsString[T int8 | uint8](field []T) string {
+	b := make([]byte, 0, len(field))
+	for _, c := range field {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
+
+// readOSRelease returns PRETTY_NAME, or NAME VERSION when it is missing
+func readOSRelease() string {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		data, err = os.ReadFile("/usr/lib/os-release")
+		if err != nil {
+			return ""
+		}
+	}
+
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		values[key] = strings.Trim(value, `"'`)
+	}
+
+	if pretty := values["PRETTY_NAME"]; pretty != "" {
+		return pretty
+	}
+	return strings.TrimSpace(values["NAME"] + " " + values["VERSION"])
+}
+
+// readBootTime returns the btime line of /proc/stat
+func readBootTime() int64 {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			boot, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return boot
+		}
+	}
+	return 0
+}
+
+// readCPUTopology returns the CPU model and the number of distinct
+// (physical id, core id) pairs in /proc/cpuinfo
+func readCPUTopology() (string, int) {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return "", 0
+	}
+	defer f.Close()
+
+	var model, physicalID string
+	cores := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "model name", "Model":
+			if model == "" {
+				model = value
+			}
+		case "physical id":
+			physicalID = value
+		case "core id":
+			cores[physicalID+"/"+value] = true
+		}
+	}
+	return model, len(cores)
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package collectors
+
+import (
+	"strings"
+
+	gpscpu "github.com/shirou/gopsutil/v3/cpu"
+	gpshost "github.com/shirou/gopsutil/v3/host"
+)
+
+// GetSystemInfo asks gopsutil for the OS and CPU facts
+func GetSystemInfo() (SystemInfo, error) {
+	info := newSystemInfo()
+
+	if h, err := gpshost.Info(); err == nil {
+		info.Distro = strings.TrimSpace(h.Platform + " " + h.PlatformVersion)
+		info.Kernel = h.KernelVersion
+		if h.KernelArch != "" {
+			info.Architecture = h.KernelArch
+		}
+		info.setBootTime(int64(h.BootTime))
+	}
+
+	if cpus, err := gpscpu.Info(); err == nil && len(cpus) > 0 {
+		info.CPUModel = strings.TrimSpace(cpus[0].ModelName)
+	}
+	if cores, err := gpscpu.Counts(false); err == nil {
+		info.PhysicalCores = cores
+	}
+
+	return info, nil
+}
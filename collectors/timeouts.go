@@ -0,0 +1,65 @@
+package collectors
+
+import (
+	"sync"
+	"time"
+)
+
+// Timeouts bounds the external commands and requests the collectors make.
+// Hosts that are slow or heavily loaded can raise them so valid data is not
+// cut off; lowering them keeps a hung tool from stalling the SSE loops.
+type Timeouts struct {
+	Docker        time.Duration // docker ps/inspect/stats/top and the availability probe
+	DockerLogs    time.Duration // docker logs and inspect calls over every container or volume
+	DockerAction  time.Duration // start/stop/restart and other container actions
+	Whois         time.Duration
+	GeoIP         time.Duration // Remote GeoIP HTTP lookups
+	Services      time.Duration // systemctl, launchctl and journal queries
+	ServiceAction time.Duration // Starting, stopping or restarting a service
+	Commands      time.Duration // Everything else: smartctl, firewall tools, netstat...
+}
+
+// DefaultTimeouts are the durations used until SetTimeouts is called
+var DefaultTimeouts = Timeouts{
+	Docker:       5 * time.Second,
+	DockerLogs:   10 * time.Second,
+	DockerAction: 30 * time.Second,
+	Whois:        5 * time.Second,
+	GeoIP:        5 * time.Second,
+	Services:     10 * time.Second,
+	// systemd waits up to 90s for a unit to stop, and restart also starts it
+	ServiceAction: 120 * time.Second,
+	Commands:      10 * time.Second,
+}
+
+var (
+	timeouts   = DefaultTimeouts
+	timeoutsMu sync.RWMutex
+)
+
+// SetTimeouts replaces the collector timeouts. Zero fields keep their default.
+func SetTimeouts(t Timeouts) {
+	fill := func(d *time.Duration, def time.Duration) {
+		if *d <= 0 {
+			*d = def
+		}
+	}
+	fill(&t.Docker, DefaultTimeouts.Docker)
+	fill(&t.DockerLogs, DefaultTimeouts.DockerLogs)
+	fill(&t.DockerAction, DefaultTimeouts.DockerAction)
+	fill(&t.Whois, DefaultTimeouts.Whois)
+	fill(&t.GeoIP, DefaultTimeouts.GeoIP)
+	fill(&t.Services, DefaultTimeouts.Services)
+	fill(&t.ServiceAction, DefaultTimeouts.ServiceAction)
+	fill(&t.Commands, DefaultTimeouts.Commands)
+
+	timeoutsMu.Lock()
+	timeouts = t
+	timeoutsMu.Unlock()
+}
+
+func currentTimeouts() Timeouts {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+	return timeouts
+}
@@ -0,0 +1,13 @@
+package collectors
+
+type Timer struct {
+	Unit      string `json:"unit"`
+	Activates string `json:"activates,omitempty"` // Service started by the timer
+	Next      string `json:"next,omitempty"`      // RFC 3339, empty when not scheduled
+	Last      string `json:"last,omitempty"`      // RFC 3339, empty when never run
+}
+
+type TimersInfo struct {
+	Available bool    `json:"available"`
+	Timers    []Timer `json:"timers,omitempty"`
+}
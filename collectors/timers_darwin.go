@@ -0,0 +1,8 @@
+//go:build darwin
+
+package collectors
+
+// GetTimersInfo reports timers as unavailable: they are a systemd feature
+func GetTimersInfo() (TimersInfo, error) {
+	return TimersInfo{Available: false}, nil
+}
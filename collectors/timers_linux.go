@@ -0,0 +1,131 @@
+//go:build linux
+
+package collectors
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// GetTimersInfo lists systemd timers, including inactive ones
+func GetTimersInfo() (TimersInfo, error) {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return TimersInfo{Available: false}, nil
+	}
+
+	timers, err := getSystemdTimers()
+	if err != nil {
+		return TimersInfo{Available: true}, err
+	}
+
+	return TimersInfo{
+		Available: true,
+		Timers:    timers,
+	}, nil
+}
+
+func getSystemdTimers() ([]Timer, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "systemctl", "list-timers", "--all", "--no-pager", "--no-legend",
+		"--output=json").Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// Fallback to text parsing if JSON not available
+		return getSystemdTimersText()
+	}
+
+	var entries []struct {
+		Unit      string `json:"unit"`
+		Activates string `json:"activates"`
+		Next      int64  `json:"next"` // Microseconds since epoch, 0 = n/a
+		Last      int64  `json:"last"`
+	}
+	if err := json.Unmarshal(output, &entries); err != nil {
+		// Older systemd ignores --output for list-timers and prints text
+		return getSystemdTimersText()
+	}
+
+	timers := []Timer{}
+	for _, e := range entries {
+		timers = append(timers, Timer{
+			Unit:      e.Unit,
+			Activates: e.Activates,
+			Next:      formatTimerUsec(e.Next),
+			Last:      formatTimerUsec(e.Last),
+		})
+	}
+	return timers, nil
+}
+
+func formatTimerUsec(usec int64) string {
+	if usec <= 0 {
+		return ""
+	}
+	return time.UnixMicro(usec).Format(time.RFC3339)
+}
+
+// getSystemdTimersText parses the table form:
+// NEXT LEFT LAST PASSED UNIT ACTIVATES, e.g.
+// Fri 2026-10-16 12:00:00 UTC 1h 2min left Fri 2026-10-16 11:00:00 UTC 2min ago logrotate.timer logrotate.service
+func getSystemdTimersText() ([]Timer, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Services)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "systemctl", "list-timers", "--all", "--no-pager", "--no-legend").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	timers := []Timer{}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+
+		unitIdx := -1
+		for i, f := range fields {
+			if strings.HasSuffix(f, ".timer") {
+				unitIdx = i
+				break
+			}
+		}
+		if unitIdx == -1 {
+			continue
+		}
+
+		timer := Timer{Unit: fields[unitIdx]}
+		if unitIdx+1 < len(fields) {
+			timer.Activates = fields[unitIdx+1]
+		}
+
+		// NEXT is at the start, LAST follows the LEFT column ("... left")
+		timer.Next = parseTimerTimestamp(fields[:unitIdx])
+		for i, f := range fields[:unitIdx] {
+			if f == "left" {
+				timer.Last = parseTimerTimestamp(fields[i+1 : unitIdx])
+				break
+			}
+		}
+
+		timers = append(timers, timer)
+	}
+
+	return timers, nil
+}
+
+// parseTimerTimestamp reads a "Fri 2026-10-16 12:00:00 UTC" timestamp from
+// the start of fields, returning "" for n/a or anything unrecognised
+func parseTimerTimestamp(fields []string) string {
+	if len(fields) < 4 {
+		return ""
+	}
+	t, err := time.Parse("Mon 2006-01-02 15:04:05 MST", strings.Join(fields[:4], " "))
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -28,6 +28,9 @@ type UserInfo struct {
 }
 
 func GetUserInfo(usernameOrUID string) (*UserInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	var u *user.User
 	var err error
 
@@ -81,7 +84,7 @@ func GetUserInfo(usernameOrUID string) (*UserInfo, error) {
 	}
 
 	// Get last login
-	if out, err := exec.Command("last", "-1", u.Username).Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "last", "-1", u.Username).Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		if len(lines) > 0 && strings.HasPrefix(lines[0], u.Username) {
 			fields := strings.Fields(lines[0])
@@ -92,7 +95,7 @@ func GetUserInfo(usernameOrUID string) (*UserInfo, error) {
 	}
 
 	// Count sessions
-	if out, err := exec.Command("who").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "who").Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		for _, line := range lines {
 			if strings.HasPrefix(line, u.Username+" ") {
@@ -117,7 +120,10 @@ func GetUserInfo(usernameOrUID string) (*UserInfo, error) {
 }
 
 func getUserCrontab(username string) (string, string) {
-	cmd := exec.Command("crontab", "-l", "-u", username)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "crontab", "-l", "-u", username)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		outputStr := strings.TrimSpace(string(output))
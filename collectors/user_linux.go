@@ -129,7 +129,10 @@ func parsePasswdLine(fields []string) (*UserInfo, error) {
 }
 
 func getUserGroups(username string) []string {
-	cmd := exec.Command("groups", username)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "groups", username)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
@@ -151,7 +154,10 @@ func getUserGroups(username string) []string {
 }
 
 func getLastLogin(username string) string {
-	cmd := exec.Command("lastlog", "-u", username)
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "lastlog", "-u", username)
 	output, err := cmd.Output()
 	if err != nil {
 		return "Unknown"
@@ -191,7 +197,10 @@ func getLastLogin(username string) string {
 }
 
 func countCurrentSessions(username string) int {
-	cmd := exec.Command("who")
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "who")
 	output, err := cmd.Output()
 	if err != nil {
 		return 0
@@ -234,9 +243,12 @@ func getUserProcesses(username string) (int, []UserProcess) {
 }
 
 func getUserCrontab(username string) (string, string) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	// Try to read user's crontab using crontab -l -u username
 	// This requires root privileges or being the user
-	cmd := exec.Command("crontab", "-l", "-u", username)
+	cmd := exec.CommandContext(ctx, "crontab", "-l", "-u", username)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		outputStr := strings.TrimSpace(string(output))
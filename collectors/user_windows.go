@@ -26,6 +26,9 @@ type UserInfo struct {
 }
 
 func GetUserInfo(usernameOrUID string) (*UserInfo, error) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
 	var u *user.User
 	var err error
 
@@ -63,7 +66,7 @@ func GetUserInfo(usernameOrUID string) (*UserInfo, error) {
 	parts := strings.Split(u.Username, "\\")
 	username := parts[len(parts)-1]
 
-	if out, err := exec.Command("net", "user", username).Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "net", "user", username).Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		for _, line := range lines {
 			if strings.HasPrefix(line, "Last logon") {
@@ -74,7 +77,7 @@ func GetUserInfo(usernameOrUID string) (*UserInfo, error) {
 	}
 
 	// Count current sessions
-	if out, err := exec.Command("query", "user").Output(); err == nil {
+	if out, err := exec.CommandContext(ctx, "query", "user").Output(); err == nil {
 		lines := strings.Split(string(out), "\n")
 		for _, line := range lines {
 			if strings.Contains(line, username) {
@@ -0,0 +1,170 @@
+package collectors
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type WireGuardPeer struct {
+	PublicKey       string   `json:"publicKey"`
+	Endpoint        string   `json:"endpoint,omitempty"`
+	AllowedIPs      []string `json:"allowedIps,omitempty"`
+	LatestHandshake int64    `json:"latestHandshake"`
+	HandshakeAge    int64    `json:"handshakeAge,omitempty"`
+	TransferRx      uint64   `json:"transferRx"`
+	TransferTx      uint64   `json:"transferTx"`
+	Keepalive       int      `json:"keepalive,omitempty"`
+}
+
+type VPNInterface struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	IsUp       bool            `json:"isUp"`
+	Addresses  []string        `json:"addresses"`
+	PublicKey  string          `json:"publicKey,omitempty"`
+	ListenPort int             `json:"listenPort,omitempty"`
+	Peers      []WireGuardPeer `json:"peers,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type VPNInfo struct {
+	WireGuardAvailable bool           `json:"wireguardAvailable"`
+	Interfaces         []VPNInterface `json:"interfaces"`
+}
+
+// tunnelPrefixes are interface name prefixes used by tun/tap style tunnels
+var tunnelPrefixes = []string{"tun", "tap", "utun", "ppp", "ipsec"}
+
+func GetVPNInfo() (VPNInfo, error) {
+	info := VPNInfo{
+		Interfaces: []VPNInterface{},
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return info, err
+	}
+
+	wgIfaces, available := listWireGuardInterfaces()
+	info.WireGuardAvailable = available
+
+	for _, iface := range ifaces {
+		vpn := VPNInterface{
+			Name:      iface.Name,
+			IsUp:      iface.Flags&net.FlagUp != 0,
+			Addresses: []string{},
+		}
+
+		if wgIfaces[iface.Name] {
+			vpn.Type = "wireguard"
+			fillWireGuardDetail(&vpn)
+		} else if tunType := tunnelType(iface.Name); tunType != "" {
+			vpn.Type = tunType
+		} else {
+			continue
+		}
+
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, addr := range addrs {
+				vpn.Addresses = append(vpn.Addresses, addr.String())
+			}
+		}
+
+		info.Interfaces = append(info.Interfaces, vpn)
+	}
+
+	return info, nil
+}
+
+// listWireGuardInterfaces returns the interfaces reported by `wg show interfaces`.
+// The second result is false when wg is not installed or cannot be run.
+func listWireGuardInterfaces() (map[string]bool, bool) {
+	result := make(map[string]bool)
+
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "wg", "show", "interfaces").Output()
+	if err != nil {
+		return result, false
+	}
+
+	for _, name := range strings.Fields(string(out)) {
+		result[name] = true
+	}
+	return result, true
+}
+
+// tunnelType classifies generic tunnel interfaces by sysfs flags or name
+func tunnelType(name string) string {
+	// tun/tap devices expose tun_flags in sysfs on Linux
+	if _, err := os.Stat("/sys/class/net/" + name + "/tun_flags"); err == nil {
+		if strings.HasPrefix(name, "tap") {
+			return "tap"
+		}
+		return "tun"
+	}
+
+	for _, prefix := range tunnelPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// fillWireGuardDetail parses `wg show <iface> dump`. The first line describes
+// the interface, each following line is a peer.
+func fillWireGuardDetail(vpn *VPNInterface) {
+	ctx, cancel := contextWithTimeout(currentTimeouts().Commands)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "wg", "show", vpn.Name, "dump").Output()
+	if err != nil {
+		vpn.Error = "wg show failed (root privileges may be required)"
+		return
+	}
+
+	now := time.Now().Unix()
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	for i, line := range lines {
+		fields := strings.Split(line, "\t")
+
+		if i == 0 {
+			// private-key public-key listen-port fwmark
+			if len(fields) >= 3 {
+				vpn.PublicKey = fields[1]
+				vpn.ListenPort, _ = strconv.Atoi(fields[2])
+			}
+			continue
+		}
+
+		// public-key preshared-key endpoint allowed-ips latest-handshake transfer-rx transfer-tx persistent-keepalive
+		if len(fields) < 8 {
+			continue
+		}
+
+		peer := WireGuardPeer{
+			PublicKey: fields[0],
+		}
+		if fields[2] != "(none)" {
+			peer.Endpoint = fields[2]
+		}
+		if fields[3] != "(none)" {
+			peer.AllowedIPs = strings.Split(fields[3], ",")
+		}
+		peer.LatestHandshake, _ = strconv.ParseInt(fields[4], 10, 64)
+		if peer.LatestHandshake > 0 {
+			peer.HandshakeAge = now - peer.LatestHandshake
+		}
+		peer.TransferRx, _ = strconv.ParseUint(fields[5], 10, 64)
+		peer.TransferTx, _ = strconv.ParseUint(fields[6], 10, 64)
+		peer.Keepalive, _ = strconv.Atoi(fields[7])
+
+		vpn.Peers = append(vpn.Peers, peer)
+	}
+}
@@ -0,0 +1,125 @@
+package collectors
+
+import (
+	"net"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Whois holds the fields of a whois answer that are useful at a glance.
+// The registries name them differently (ARIN uses OrgName/CIDR, RIPE and
+// APNIC use org-name/inetnum), so each field is filled from whichever
+// key the answer uses.
+type Whois struct {
+	OrgName      string `json:"orgName,omitempty"`
+	NetName      string `json:"netName,omitempty"`
+	Country      string `json:"country,omitempty"`
+	CIDR         string `json:"cidr,omitempty"`
+	AbuseContact string `json:"abuseContact,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Raw          string `json:"raw,omitempty"`
+}
+
+// whoisFields maps lowercased whois keys to the field they fill
+var whoisFields = map[string]func(w *Whois) *string{
+	"orgname":       func(w *Whois) *string { return &w.OrgName },
+	"org-name":      func(w *Whois) *string { return &w.OrgName },
+	"organization":  func(w *Whois) *string { return &w.OrgName },
+	"owner":         func(w *Whois) *string { return &w.OrgName }, // LACNIC
+	"netname":       func(w *Whois) *string { return &w.NetName },
+	"country":       func(w *Whois) *string { return &w.Country },
+	"cidr":          func(w *Whois) *string { return &w.CIDR },
+	"inetnum":       func(w *Whois) *string { return &w.CIDR },
+	"inet6num":      func(w *Whois) *string { return &w.CIDR },
+	"orgabuseemail": func(w *Whois) *string { return &w.AbuseContact },
+	"abuse-mailbox": func(w *Whois) *string { return &w.AbuseContact },
+}
+
+// whoisAbuseComment matches RIPE's "% Abuse contact for '...' is 'x@y'"
+var whoisAbuseComment = regexp.MustCompile(`(?i)^%\s*abuse contact for .* is '([^']+)'`)
+
+// parseWhois extracts the structured fields from whois output. When the
+// client follows a referral the answer holds several registries' records,
+// less specific first, so later values replace earlier ones.
+func parseWhois(output string) *Whois {
+	w := &Whois{Raw: strings.TrimSpace(output)}
+	var descr []string
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if m := whoisAbuseComment.FindStringSubmatch(line); m != nil {
+			w.AbuseContact = m[1]
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "%") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if key == "descr" {
+			if !slices.Contains(descr, value) && len(descr) < 3 {
+				descr = append(descr, value)
+			}
+			continue
+		}
+		if field, ok := whoisFields[key]; ok {
+			if key == "inetnum" {
+				value = rangeToCIDR(value)
+			}
+			*field(w) = value
+		}
+	}
+
+	w.Description = strings.Join(descr, "\n")
+	if *w == (Whois{}) {
+		return nil
+	}
+	return w
+}
+
+// rangeToCIDR turns an "a.b.c.d - e.f.g.h" range into a prefix when it is
+// exactly one; other ranges are returned unchanged
+func rangeToCIDR(value string) string {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return value
+	}
+	start, err1 := netip.ParseAddr(strings.TrimSpace(from))
+	end, err2 := netip.ParseAddr(strings.TrimSpace(to))
+	if err1 != nil || err2 != nil || start.BitLen() != end.BitLen() {
+		return value
+	}
+
+	for bits := 0; bits <= start.BitLen(); bits++ {
+		prefix := netip.PrefixFrom(start, bits).Masked()
+		if prefix.Addr() != start {
+			continue
+		}
+		if lastAddr(prefix) == end {
+			return prefix.String()
+		}
+	}
+	return value
+}
+
+// lastAddr returns the highest address within a prefix
+func lastAddr(p netip.Prefix) netip.Addr {
+	ip := net.IP(p.Addr().AsSlice())
+	mask := net.CIDRMask(p.Bits(), p.Addr().BitLen())
+	for i := range ip {
+		ip[i] |= ^mask[i]
+	}
+	addr, _ := netip.AddrFromSlice(ip)
+	return addr
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
 type SSLConfig struct {
@@ -12,17 +14,84 @@ type SSLConfig struct {
 	Key     string `json:"key"`
 }
 
+// CORSConfig lets dashboards on other origins call the API. With no
+// allowed origins no CORS headers are sent and only same-origin pages work.
+type CORSConfig struct {
+	AllowedOrigins   []string `json:"allowedOrigins"` // Exact origins, or "*" for any (not with AllowCredentials)
+	AllowedMethods   []string `json:"allowedMethods"`
+	AllowCredentials bool     `json:"allowCredentials"` // Send cookies cross-origin
+}
+
 type ServerConfig struct {
-	Host string    `json:"host"`
-	Port int       `json:"port"`
-	SSL  SSLConfig `json:"ssl"`
+	Host string     `json:"host"`
+	Port int        `json:"port"`
+	SSL  SSLConfig  `json:"ssl"`
+	CORS CORSConfig `json:"cors"`
+}
+
+// LogConfig controls the application log written to stderr
+type LogConfig struct {
+	Level  string `json:"level"`  // debug, info, warn or error
+	Format string `json:"format"` // text or json
+	// Log every API request at info level instead of debug
+	AccessLog bool `json:"accessLog"`
 }
 
 type AuthConfig struct {
-	Username         string `json:"username"`
-	Password         string `json:"password"`
-	ReadOnlyUsername string `json:"readOnlyUsername"`
-	ReadOnlyPassword string `json:"readOnlyPassword"`
+	// Single read-write and read-only accounts, kept for existing configs.
+	// Users below can hold any number of named accounts
+	Username         string       `json:"username"`
+	Password         string       `json:"password"`
+	ReadOnlyUsername string       `json:"readOnlyUsername"`
+	ReadOnlyPassword string       `json:"readOnlyPassword"`
+	Users            []UserConfig `json:"users"`
+	// Brute-force protection: lock an IP out after MaxLoginAttempts failures
+	// within LoginWindow seconds, for LoginLockout seconds. 0 = no limit
+	MaxLoginAttempts int  `json:"maxLoginAttempts"`
+	LoginWindow      int  `json:"loginWindow"`
+	LoginLockout     int  `json:"loginLockout"`
+	TrustProxy       bool `json:"trustProxy"` // Use X-Forwarded-For as the client IP
+	// Session lifetime in hours. With SlidingExpiration every request
+	// extends the session by another SessionTTLHours
+	SessionTTLHours   int  `json:"sessionTtlHours"`
+	SlidingExpiration bool `json:"slidingExpiration"`
+	// Static tokens for scripts and monitoring, which send them as
+	// "Authorization: Bearer <token>" instead of logging in
+	Tokens []APITokenConfig `json:"tokens"`
+}
+
+// UserConfig is one named account. Password holds the hash printed by
+// --hash-password; Role is "readwrite" or "readonly"
+type UserConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// Roles a UserConfig can have
+const (
+	RoleReadWrite = "readwrite"
+	RoleReadOnly  = "readonly"
+)
+
+// AllUsers returns every configured account: the single username and
+// readOnlyUsername pairs, when set, followed by Users
+func (a AuthConfig) AllUsers() []UserConfig {
+	var users []UserConfig
+	if a.Username != "" && a.Password != "" {
+		users = append(users, UserConfig{Username: a.Username, Password: a.Password, Role: RoleReadWrite})
+	}
+	if a.ReadOnlyUsername != "" && a.ReadOnlyPassword != "" {
+		users = append(users, UserConfig{Username: a.ReadOnlyUsername, Password: a.ReadOnlyPassword, Role: RoleReadOnly})
+	}
+	return append(users, a.Users...)
+}
+
+// APITokenConfig is one API token. Name identifies it in logs
+type APITokenConfig struct {
+	Name      string `json:"name"`
+	Token     string `json:"token"`
+	ReadWrite bool   `json:"readWrite"`
 }
 
 type UIConfig struct {
@@ -45,11 +114,164 @@ type RefreshConfig struct {
 	Firewall  int `json:"firewall"`
 }
 
+type IPLookupConfig struct {
+	// Max external lookups (whois, GeoIP, reverse DNS) per minute. 0 = unlimited
+	RatePerMinute int `json:"ratePerMinute"`
+	// Seconds whois/GeoIP/reverse DNS results are reused, and how many IPs to keep. 0 disables
+	CacheTTL  int `json:"cacheTtl"`
+	CacheSize int `json:"cacheSize"`
+}
+
+// GeoIPConfig points at local MaxMind GeoLite2 databases. When set, GeoIP
+// lookups never leave the host.
+type GeoIPConfig struct {
+	DBPath    string `json:"dbPath"`    // GeoLite2-City.mmdb or GeoLite2-Country.mmdb
+	ASNDBPath string `json:"asnDbPath"` // GeoLite2-ASN.mmdb, optional
+}
+
+type SecurityConfig struct {
+	// Services that cannot be stopped, restarted or disabled through the API
+	ProtectedServices []string `json:"protectedServices"`
+	// Processes that cannot be signalled through the API. Kernel threads
+	// are recognised by the PF_KTHREAD process flag and only exist on Linux
+	ProtectedPIDs        []int `json:"protectedPids"`
+	ProtectKernelThreads bool  `json:"protectKernelThreads"`
+}
+
+// MaintenanceConfig sets how often (in seconds) the background routine
+// purges expired sessions, expired cache entries and stale rate samples
+type MaintenanceConfig struct {
+	SessionInterval int `json:"sessionInterval"`
+	CacheInterval   int `json:"cacheInterval"`
+	SampleInterval  int `json:"sampleInterval"`
+}
+
+// HistoryConfig controls the in-memory metric history behind /api/history
+type HistoryConfig struct {
+	Interval int `json:"interval"` // Seconds between samples, 0 disables history
+	Samples  int `json:"samples"`  // Samples kept per metric
+	// Containers also records CPU and memory per running container,
+	// served at /api/docker/{id}/stats/history
+	Containers bool `json:"containers"`
+}
+
+// CommandConfig is an operational command that can be run from the
+// dashboard. Only these exact command lines can ever be executed.
+type CommandConfig struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	Cmd            string   `json:"cmd"`
+	Args           []string `json:"args,omitempty"`
+	RequireConfirm bool     `json:"requireConfirm"`
+	Timeout        int      `json:"timeout,omitempty"` // Seconds, defaults to 30
+}
+
+// PeerConfig is a remote syspeek instance polled in aggregator mode.
+// Password is the plain-text password, since it is sent to the peer's login.
+type PeerConfig struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Username    string `json:"username,omitempty"`
+	Password    string `json:"password,omitempty"`
+	InsecureTLS bool   `json:"insecureTls,omitempty"` // Accept self-signed certificates
+}
+
+type ClusterConfig struct {
+	Peers        []PeerConfig `json:"peers"`
+	Timeout      int          `json:"timeout"`      // Per-peer request timeout in seconds
+	CacheSeconds int          `json:"cacheSeconds"` // How long a peer result is reused
+}
+
+// HealthScoreConfig holds the weights of each factor in /api/health-score
+// and the score thresholds for the warn/crit status.
+type HealthScoreConfig struct {
+	CPUWeight    float64 `json:"cpuWeight"`
+	LoadWeight   float64 `json:"loadWeight"`
+	MemoryWeight float64 `json:"memoryWeight"`
+	SwapWeight   float64 `json:"swapWeight"`
+	DiskWeight   float64 `json:"diskWeight"`
+	IOWaitWeight float64 `json:"iowaitWeight"`
+	Warn         float64 `json:"warn"`
+	Crit         float64 `json:"crit"`
+}
+
+// CollectorsConfig toggles expensive sub-features of individual collectors.
+// Everything defaults to enabled.
+type CollectorsConfig struct {
+	CPU       CPUCollectorConfig     `json:"cpu"`
+	Disk      DiskCollectorConfig    `json:"disk"`
+	Processes ProcessCollectorConfig `json:"processes"`
+	Sockets   SocketCollectorConfig  `json:"sockets"`
+	Docker    DockerCollectorConfig  `json:"docker"`
+}
+
+type CPUCollectorConfig struct {
+	IncludeTemps bool `json:"includeTemps"`
+}
+
+// DiskCollectorConfig adds non-block-device mounts to the disk list.
+// ?includeNetwork= and ?includeVirtual= override it per request
+type DiskCollectorConfig struct {
+	IncludeNetwork bool `json:"includeNetwork"` // nfs, cifs, sshfs...
+	IncludeVirtual bool `json:"includeVirtual"` // zfs, overlay, fuse
+}
+
+type ProcessCollectorConfig struct {
+	IncludeFDs bool `json:"includeFds"`
+	// Count sockets per process in the list too, at one readlink per open
+	// descriptor on every refresh. Process detail always has the count.
+	IncludeSocketCounts bool `json:"includeSocketCounts"`
+	Timeout             int  `json:"timeout"` // Seconds before /api/processes returns what it has
+	// Report cpuPercent as a share of the whole machine (0-100) instead of
+	// one core. ?normalizeCpu= overrides it per request
+	NormalizeCPU bool `json:"normalizeCpu"`
+}
+
+type SocketCollectorConfig struct {
+	IncludeProcesses bool `json:"includeProcesses"` // inode to PID mapping
+	Timeout          int  `json:"timeout"`          // Seconds before /api/sockets returns what it has
+}
+
+type DockerCollectorConfig struct {
+	IncludeStats  bool `json:"includeStats"`
+	ExecTimeout   int  `json:"execTimeout"`   // Seconds before docker exec is killed
+	ExecMaxOutput int  `json:"execMaxOutput"` // Bytes of exec output streamed back
+}
+
+// TimeoutsConfig bounds, in seconds, the external commands and lookups the
+// collectors run. Raise them on slow hosts; 0 keeps the default.
+type TimeoutsConfig struct {
+	Docker        int `json:"docker"`       // docker ps, inspect, stats, top
+	DockerLogs    int `json:"dockerLogs"`   // docker logs and bulk inspect
+	DockerAction  int `json:"dockerAction"` // start, stop, restart...
+	Whois         int `json:"whois"`
+	GeoIP         int `json:"geoip"`
+	Services      int `json:"services"`      // systemctl, launchctl, journalctl
+	ServiceAction int `json:"serviceAction"` // start, stop, restart a service
+	Commands      int `json:"commands"`      // Other tools: smartctl, iw, nft, netstat...
+}
+
 type Config struct {
-	Server  ServerConfig  `json:"server"`
-	Auth    AuthConfig    `json:"auth"`
-	UI      UIConfig      `json:"ui"`
-	Refresh RefreshConfig `json:"refresh"`
+	Server      ServerConfig      `json:"server"`
+	Log         LogConfig         `json:"log"`
+	Auth        AuthConfig        `json:"auth"`
+	UI          UIConfig          `json:"ui"`
+	Refresh     RefreshConfig     `json:"refresh"`
+	IPLookup    IPLookupConfig    `json:"ipLookup"`
+	GeoIP       GeoIPConfig       `json:"geoip"`
+	Health      HealthScoreConfig `json:"healthScore"`
+	Collectors  CollectorsConfig  `json:"collectors"`
+	Timeouts    TimeoutsConfig    `json:"timeouts"`
+	Security    SecurityConfig    `json:"security"`
+	Cluster     ClusterConfig     `json:"cluster"`
+	Maintenance MaintenanceConfig `json:"maintenance"`
+	History     HistoryConfig     `json:"history"`
+	Commands    []CommandConfig   `json:"commands"`
+
+	// Files that contributed to this config, in merge order
+	Sources []string `json:"-"`
+	// Environment variables that overrode file values
+	EnvOverrides []string `json:"-"`
 }
 
 func DefaultConfig() *Config {
@@ -67,12 +289,31 @@ func DefaultConfig() *Config {
 				Cert:    "",
 				Key:     "",
 			},
+			CORS: CORSConfig{
+				AllowedOrigins:   []string{},
+				AllowedMethods:   []string{"GET", "POST"},
+				AllowCredentials: false,
+			},
+		},
+		Log: LogConfig{
+			Level:     "info",
+			Format:    "text",
+			AccessLog: false,
 		},
 		Auth: AuthConfig{
 			Username:         "",
 			Password:         "",
 			ReadOnlyUsername: "",
 			ReadOnlyPassword: "",
+			Users:            []UserConfig{},
+			MaxLoginAttempts: 5,
+			LoginWindow:      300,
+			LoginLockout:     900,
+			TrustProxy:       false,
+			// Sessions last 24h from login unless slidingExpiration is set
+			SessionTTLHours:   24,
+			SlidingExpiration: false,
+			Tokens:            []APITokenConfig{},
 		},
 		UI: UIConfig{
 			Title:       hostname,
@@ -92,28 +333,183 @@ func DefaultConfig() *Config {
 			Sockets:   5000,
 			Firewall:  10000,
 		},
+		IPLookup: IPLookupConfig{
+			RatePerMinute: 60,
+			CacheTTL:      3600,
+			CacheSize:     1000,
+		},
+		Health: HealthScoreConfig{
+			CPUWeight:    0.25,
+			LoadWeight:   0.15,
+			MemoryWeight: 0.25,
+			SwapWeight:   0.10,
+			DiskWeight:   0.15,
+			IOWaitWeight: 0.10,
+			Warn:         60,
+			Crit:         85,
+		},
+		Collectors: CollectorsConfig{
+			CPU:       CPUCollectorConfig{IncludeTemps: true},
+			Disk:      DiskCollectorConfig{IncludeNetwork: false, IncludeVirtual: false},
+			Processes: ProcessCollectorConfig{IncludeFDs: true, Timeout: 10, NormalizeCPU: false},
+			Sockets:   SocketCollectorConfig{IncludeProcesses: true, Timeout: 10},
+			Docker: DockerCollectorConfig{
+				IncludeStats:  true,
+				ExecTimeout:   30,
+				ExecMaxOutput: 1024 * 1024,
+			},
+		},
+		Timeouts: TimeoutsConfig{
+			Docker:        5,
+			DockerLogs:    10,
+			DockerAction:  30,
+			Whois:         5,
+			GeoIP:         5,
+			Services:      10,
+			ServiceAction: 120,
+			Commands:      10,
+		},
+		Security: SecurityConfig{
+			ProtectedServices:    []string{"sshd", "ssh", "NetworkManager", "systemd-networkd", "firewalld", "ufw"},
+			ProtectedPIDs:        []int{1},
+			ProtectKernelThreads: true,
+		},
+		Cluster: ClusterConfig{
+			Peers:        []PeerConfig{},
+			Timeout:      5,
+			CacheSeconds: 10,
+		},
+		Maintenance: MaintenanceConfig{
+			SessionInterval: 600,
+			CacheInterval:   300,
+			SampleInterval:  300,
+		},
+		History: HistoryConfig{
+			Interval: 5,
+			Samples:  360,
+		},
+		Commands: []CommandConfig{},
 	}
 }
 
+// LoadConfig reads a config file, or a config directory, then applies
+// SYSPEEK_* environment variables on top (see applyEnv) and validates the
+// result. For a directory, every *.json in it and then in its conf.d
+// subdirectory is deep-merged in lexical order, later files overriding
+// keys from earlier ones.
 func LoadConfig(path string) (*Config, error) {
+	cfg, err := loadConfigFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.EnvOverrides, err = applyEnv(cfg, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadConfigFiles reads the defaults overridden by the file or directory
+// at path, if any
+func loadConfigFiles(path string) (*Config, error) {
 	cfg := DefaultConfig()
 
 	if path == "" {
 		return cfg, nil
 	}
 
-	data, err := os.ReadFile(path)
+	stat, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(data, cfg); err != nil {
+	if !stat.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+
+		cfg.Sources = []string{path}
+		return cfg, nil
+	}
+
+	files, err := configDirFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]interface{}{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var layer map[string]interface{}
+		if err := json.Unmarshal(data, &layer); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		mergeJSON(merged, layer)
+	}
+
+	// Round-trip through JSON so the merged document is type-checked
+	// against Config exactly like a single file would be
+	data, err := json.Marshal(merged)
+	if err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("merged config from %s: %v", path, err)
+	}
 
+	cfg.Sources = files
 	return cfg, nil
 }
 
+// configDirFiles lists dir/*.json then dir/conf.d/*.json, each sorted
+func configDirFiles(dir string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{
+		filepath.Join(dir, "*.json"),
+		filepath.Join(dir, "conf.d", "*.json"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.json config files in %s", dir)
+	}
+	return files, nil
+}
+
+// mergeJSON deep-merges src into dst. Objects merge key by key; any other
+// value, including arrays, replaces what was there.
+func mergeJSON(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				mergeJSON(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
 func (c *Config) ToJSON() (string, error) {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
@@ -123,15 +519,24 @@ func (c *Config) ToJSON() (string, error) {
 }
 
 func (c *Config) HasAuth() bool {
-	return c.Auth.Username != "" && c.Auth.Password != ""
+	return c.hasRole(RoleReadWrite)
 }
 
 func (c *Config) HasReadOnlyAuth() bool {
-	return c.Auth.ReadOnlyUsername != "" && c.Auth.ReadOnlyPassword != ""
+	return c.hasRole(RoleReadOnly)
+}
+
+func (c *Config) hasRole(role string) bool {
+	for _, u := range c.Auth.AllUsers() {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Config) HasAnyAuth() bool {
-	return c.HasAuth() || c.HasReadOnlyAuth()
+	return c.HasAuth() || c.HasReadOnlyAuth() || len(c.Auth.Tokens) > 0
 }
 
 func (c *Config) GetAddress() string {
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// envPrefix starts every environment variable that overrides the config
+const envPrefix = "SYSPEEK"
+
+// applyEnv overrides cfg with environment variables named after the JSON
+// path of each setting: server.port is SYSPEEK_SERVER_PORT and
+// auth.readOnlyUsername is SYSPEEK_AUTH_READ_ONLY_USERNAME. Lists take
+// comma-separated values. Lists of objects (commands, cluster peers)
+// can only be set in a file. It returns the names of the variables used.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) ([]string, error) {
+	var applied []string
+	err := applyEnvStruct(reflect.ValueOf(cfg).Elem(), envPrefix, lookup, &applied)
+	return applied, err
+}
+
+func applyEnvStruct(v reflect.Value, prefix string, lookup func(string) (string, bool), applied *[]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + "_" + envName(tag)
+		field := v.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := applyEnvStruct(field, name, lookup, applied); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		set, err := setEnvValue(field, value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if set {
+			*applied = append(*applied, name)
+		}
+	}
+	return nil
+}
+
+// setEnvValue parses value into field. It reports false for field types
+// that can't be set from a string.
+func setEnvValue(field reflect.Value, value string) (bool, error) {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return false, fmt.Errorf("%q is not an integer", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return false, fmt.Errorf("%q is not a boolean", value)
+		}
+		field.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return false, fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		elem := field.Type().Elem().Kind()
+		if elem != reflect.String && elem != reflect.Int {
+			return false, nil
+		}
+		list := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			e := reflect.New(field.Type().Elem()).Elem()
+			if _, err := setEnvValue(e, item); err != nil {
+				return false, err
+			}
+			list = reflect.Append(list, e)
+		}
+		field.Set(list)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// envName turns a camelCase JSON key into UPPER_SNAKE_CASE
+func envName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rune(key[i-1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MinRefreshInterval is the shortest refresh interval accepted, in ms
+const MinRefreshInterval = 250
+
+// MinAPITokenLength keeps API tokens long enough not to be guessed
+const MinAPITokenLength = 16
+
+// themes are the values ui.theme can take; empty uses the default
+var themes = map[string]bool{"": true, "dark": true, "light": true}
+
+// Validate checks the values that would otherwise fail later or crash
+// the server (a zero refresh interval panics the SSE ticker). Every
+// problem is reported, one per line, named by its JSON path.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port: %d is not between 1 and 65535", c.Server.Port))
+	}
+	if (c.Server.SSL.Cert == "") != (c.Server.SSL.Key == "") {
+		errs = append(errs, errors.New("server.ssl: cert and key must be set together"))
+	}
+	// Any site could then make requests carrying the user's session cookie
+	if c.Server.CORS.AllowCredentials && slices.Contains(c.Server.CORS.AllowedOrigins, "*") {
+		errs = append(errs, errors.New(`server.cors: allowedOrigins "*" cannot be combined with allowCredentials; list the origins`))
+	}
+
+	for _, r := range []struct {
+		name  string
+		value int
+	}{
+		{"cpu", c.Refresh.CPU},
+		{"memory", c.Refresh.Memory},
+		{"disk", c.Refresh.Disk},
+		{"network", c.Refresh.Network},
+		{"gpu", c.Refresh.GPU},
+		{"processes", c.Refresh.Processes},
+		{"sockets", c.Refresh.Sockets},
+		{"firewall", c.Refresh.Firewall},
+	} {
+		if r.value < MinRefreshInterval {
+			errs = append(errs, fmt.Errorf("refresh.%s: %d ms is below the minimum of %d ms", r.name, r.value, MinRefreshInterval))
+		}
+	}
+
+	usernames := make(map[string]bool)
+	for _, u := range c.Auth.AllUsers() {
+		switch {
+		case u.Username == "":
+			errs = append(errs, errors.New("auth.users: username is required"))
+		case u.Password == "":
+			errs = append(errs, fmt.Errorf("auth.users (%s): password is required", u.Username))
+		case u.Role != RoleReadWrite && u.Role != RoleReadOnly:
+			errs = append(errs, fmt.Errorf("auth.users (%s): role %q is not %q or %q", u.Username, u.Role, RoleReadWrite, RoleReadOnly))
+		case usernames[u.Username]:
+			errs = append(errs, fmt.Errorf("auth.users (%s): username is configured more than once", u.Username))
+		}
+		usernames[u.Username] = true
+	}
+
+	seen := make(map[string]bool)
+	for i, t := range c.Auth.Tokens {
+		switch {
+		case t.Name == "":
+			errs = append(errs, fmt.Errorf("auth.tokens[%d]: name is required", i))
+		case len(t.Token) < MinAPITokenLength:
+			errs = append(errs, fmt.Errorf("auth.tokens[%d] (%s): token must be at least %d characters", i, t.Name, MinAPITokenLength))
+		case seen[t.Token]:
+			errs = append(errs, fmt.Errorf("auth.tokens[%d] (%s): token is already used by another entry", i, t.Name))
+		}
+		seen[t.Token] = true
+	}
+
+	if !themes[c.UI.Theme] {
+		errs = append(errs, fmt.Errorf("ui.theme: %q is not \"dark\" or \"light\"", c.UI.Theme))
+	}
+
+	return errors.Join(errs...)
+}
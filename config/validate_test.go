@@ -0,0 +1,112 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	token := strings.Repeat("t", MinAPITokenLength)
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string // Start of the error line; empty for a valid config
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"full valid config", func(c *Config) {
+			c.Server.SSL = SSLConfig{Enabled: true, Cert: "cert.pem", Key: "key.pem"}
+			c.Auth.Users = []UserConfig{
+				{Username: "admin", Password: "hash", Role: RoleReadWrite},
+				{Username: "viewer", Password: "hash", Role: RoleReadOnly},
+			}
+			c.Auth.Tokens = []APITokenConfig{{Name: "ci", Token: token}}
+			c.Refresh.CPU = MinRefreshInterval
+			c.UI.Theme = "light"
+		}, ""},
+		{"CORS wildcard without credentials", func(c *Config) {
+			c.Server.CORS.AllowedOrigins = []string{"*"}
+		}, ""},
+		{"CORS origins with credentials", func(c *Config) {
+			c.Server.CORS.AllowedOrigins = []string{"https://dash.example.com"}
+			c.Server.CORS.AllowCredentials = true
+		}, ""},
+
+		{"port zero", func(c *Config) { c.Server.Port = 0 }, "server.port"},
+		{"port too high", func(c *Config) { c.Server.Port = 65536 }, "server.port"},
+		{"cert without key", func(c *Config) { c.Server.SSL.Cert = "cert.pem" }, "server.ssl"},
+		{"key without cert", func(c *Config) { c.Server.SSL.Key = "key.pem" }, "server.ssl"},
+		{"refresh.cpu", func(c *Config) { c.Refresh.CPU = 0 }, "refresh.cpu"},
+		{"refresh.memory", func(c *Config) { c.Refresh.Memory = -1 }, "refresh.memory"},
+		{"refresh.disk", func(c *Config) { c.Refresh.Disk = MinRefreshInterval - 1 }, "refresh.disk"},
+		{"refresh.network", func(c *Config) { c.Refresh.Network = 0 }, "refresh.network"},
+		{"refresh.gpu", func(c *Config) { c.Refresh.GPU = 0 }, "refresh.gpu"},
+		{"refresh.processes", func(c *Config) { c.Refresh.Processes = 0 }, "refresh.processes"},
+		{"refresh.sockets", func(c *Config) { c.Refresh.Sockets = 0 }, "refresh.sockets"},
+		{"refresh.firewall", func(c *Config) { c.Refresh.Firewall = 0 }, "refresh.firewall"},
+		{"user without name", func(c *Config) {
+			c.Auth.Users = []UserConfig{{Password: "hash", Role: RoleReadOnly}}
+		}, "auth.users: username"},
+		{"user without password", func(c *Config) {
+			c.Auth.Users = []UserConfig{{Username: "admin", Role: RoleReadOnly}}
+		}, "auth.users (admin): password"},
+		{"user with unknown role", func(c *Config) {
+			c.Auth.Users = []UserConfig{{Username: "admin", Password: "hash", Role: "root"}}
+		}, "auth.users (admin): role"},
+		{"duplicate user", func(c *Config) {
+			c.Auth.Username, c.Auth.Password = "admin", "hash"
+			c.Auth.Users = []UserConfig{{Username: "admin", Password: "hash", Role: RoleReadOnly}}
+		}, "auth.users (admin): username is configured more than once"},
+		{"token without name", func(c *Config) {
+			c.Auth.Tokens = []APITokenConfig{{Token: token}}
+		}, "auth.tokens[0]: name"},
+		{"short token", func(c *Config) {
+			c.Auth.Tokens = []APITokenConfig{{Name: "ci", Token: "short"}}
+		}, "auth.tokens[0] (ci): token must be"},
+		{"duplicate token", func(c *Config) {
+			c.Auth.Tokens = []APITokenConfig{{Name: "ci", Token: token}, {Name: "cd", Token: token}}
+		}, "auth.tokens[1] (cd): token is already used"},
+		{"unknown theme", func(c *Config) { c.UI.Theme = "solarized" }, "ui.theme"},
+		{"CORS wildcard with credentials", func(c *Config) {
+			c.Server.CORS.AllowedOrigins = []string{"https://dash.example.com", "*"}
+			c.Server.CORS.AllowCredentials = true
+		}, "server.cors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			tt.modify(c)
+			err := c.Validate()
+
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("rejected: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("accepted, want %s error", tt.want)
+			}
+			// Exactly the one problem, reported by its JSON path
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != 1 || !strings.HasPrefix(lines[0], tt.want) {
+				t.Errorf("error %q, want one line starting with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	c := DefaultConfig()
+	c.Server.Port = 0
+	c.Refresh.CPU = 0
+	c.UI.Theme = "solarized"
+
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("accepted")
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 3 {
+		t.Errorf("%d problems reported, want 3: %v", len(lines), err)
+	}
+}
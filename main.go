@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -13,23 +14,29 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"log/slog"
 	"math/big"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"syspeek/api"
 	"syspeek/auth"
+	"syspeek/collectors"
 	"syspeek/config"
 )
 
 const (
-	maxPortRetries = 50
-	Version        = "1.3.0"
+	maxPortRetries  = 50
+	shutdownTimeout = 10 * time.Second // Grace period for in-flight requests on exit
+	Version         = "1.3.0"
 )
 
 //go:embed static templates
@@ -38,8 +45,10 @@ var embeddedFS embed.FS
 func main() {
 	// Parse flags
 	serve := flag.Bool("serve", false, "Run in server mode (don't open browser)")
-	configFile := flag.String("config-file", "", "Path to config file")
+	configFile := flag.String("config-file", "", "Path to config file or config directory")
 	printConfig := flag.Bool("print-config-file", false, "Print default config and exit")
+	hashPasswordFlag := flag.Bool("hash-password", false, "Read a password from stdin or a prompt, print its hash for the config and exit")
+	hashAlgo := flag.String("hash-algo", "bcrypt", "Algorithm for --hash-password: bcrypt or md5 (legacy)")
 	port := flag.Int("port", 0, "Override port from config")
 	host := flag.String("host", "", "Override host from config")
 	https := flag.Bool("https", false, "Enable HTTPS with auto-generated self-signed certificate")
@@ -86,6 +95,20 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle --hash-password
+	if *hashPasswordFlag {
+		password, err := readPassword()
+		if err != nil {
+			log.Fatalf("Error reading password: %v", err)
+		}
+		hash, err := hashPassword(password, *hashAlgo)
+		if err != nil {
+			log.Fatalf("Error hashing password: %v", err)
+		}
+		fmt.Println(hash)
+		os.Exit(0)
+	}
+
 	// Determine config file path
 	cfgPath := *configFile
 	if cfgPath == "" {
@@ -104,6 +127,15 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
+	if err := setupLogging(cfg.Log); err != nil {
+		log.Fatalf("Error in log config: %v", err)
+	}
+	for _, src := range cfg.Sources {
+		slog.Info("Config loaded", "path", src)
+	}
+	for _, name := range cfg.EnvOverrides {
+		slog.Info("Config overridden from environment", "var", name)
+	}
 
 	// Override with flags
 	if *port != 0 {
@@ -129,19 +161,36 @@ func main() {
 		// Will generate self-signed certificate
 	}
 
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	applyCollectorConfig(cfg)
+	if err := collectors.SetGeoIPDatabase(cfg.GeoIP.DBPath, cfg.GeoIP.ASNDBPath); err != nil {
+		log.Fatalf("Error loading GeoIP database: %v", err)
+	}
+
 	// Setup auth manager
-	authMgr := auth.NewAuthManager(
-		cfg.Auth.Username, cfg.Auth.Password,
-		cfg.Auth.ReadOnlyUsername, cfg.Auth.ReadOnlyPassword,
-		*public, *admin,
+	authMgr := auth.NewAuthManager(authUsers(cfg.Auth), *public, *admin)
+	authMgr.SetLoginLimits(
+		cfg.Auth.MaxLoginAttempts,
+		time.Duration(cfg.Auth.LoginWindow)*time.Second,
+		time.Duration(cfg.Auth.LoginLockout)*time.Second,
 	)
+	authMgr.SetTrustProxy(cfg.Auth.TrustProxy)
+	authMgr.SetSessionTTL(time.Duration(cfg.Auth.SessionTTLHours)*time.Hour, cfg.Auth.SlidingExpiration)
+	authMgr.SetAPITokens(apiTokens(cfg.Auth.Tokens))
 
 	// Validate: if no auth configured and no public/admin mode, abort
 	if !authMgr.IsEnabled() && !*public && !*admin {
 		log.Fatalf("No users configured. Run with -p for public read-only mode or -a for public admin mode.")
 	}
 
-	authMgr.StartCleanupRoutine()
+	startMaintenance(cfg.Maintenance, authMgr)
+	collectors.StartHistory(time.Duration(cfg.History.Interval)*time.Second, cfg.History.Samples)
+	if cfg.History.Containers {
+		collectors.StartContainerHistory(time.Duration(cfg.History.Interval)*time.Second, cfg.History.Samples)
+	}
 
 	// Setup API
 	apiHandler := api.NewAPI(cfg, authMgr, *serve)
@@ -149,12 +198,13 @@ func main() {
 	// Store service PID and try to set higher priority
 	pid := os.Getpid()
 	api.SetServicePID(pid)
+	api.SetVersion(Version)
 
 	// Try to set higher priority (nice -5) for the service
 	// This requires appropriate permissions
 	if err := SetProcessPriority(-5); err != nil {
 		// Not critical if it fails - just log it
-		log.Printf("Note: Could not set service priority (requires elevated permissions)")
+		slog.Info("Could not set service priority (requires elevated permissions)")
 	}
 
 	// Setup routes
@@ -166,7 +216,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error getting static fs: %v", err)
 	}
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
+	mux.Handle("/static/", staticCacheHandler(http.StripPrefix("/static/", http.FileServer(http.FS(staticFS)))))
 
 	// Serve index.html for root and SPA routes
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -205,7 +255,7 @@ func main() {
 		if err == nil {
 			cfg.Server.Port = tryPort
 			if i > 0 && !portSpecified {
-				fmt.Printf("Port %d busy, using %d\n", startPort, tryPort)
+				slog.Info("Port busy, using next free port", "busy", startPort, "port", tryPort)
 			}
 			break
 		}
@@ -223,34 +273,37 @@ func main() {
 
 	url := fmt.Sprintf("%s://%s:%d", scheme, displayHost, cfg.Server.Port)
 
-	// Print startup info
-	fmt.Printf("Syspeek starting...\n")
-	fmt.Printf("URL: %s\n", url)
-
-	// Print auth status
+	// Log startup info and auth status
+	mode := "no authentication configured"
 	if authMgr.IsAdminMode() {
-		fmt.Printf("Mode: admin (no authentication required)\n")
+		mode = "admin (no authentication required)"
 	} else if authMgr.IsPublic() {
 		if authMgr.HasReadWriteAuth() {
-			fmt.Printf("Mode: public read-only (login for read-write)\n")
+			mode = "public read-only (login for read-write)"
 		} else {
-			fmt.Printf("Mode: public read-only (no admin configured)\n")
+			mode = "public read-only (no admin configured)"
 		}
 	} else if authMgr.IsEnabled() {
-		fmt.Printf("Mode: login required\n")
-	} else {
-		fmt.Printf("Mode: no authentication configured\n")
+		mode = "login required"
 	}
+	slog.Info("Syspeek starting", "version", Version, "url", url, "mode", mode)
 
 	// Open browser if not in serve mode
 	if !*serve {
-		fmt.Printf("Opening browser...\n")
+		slog.Info("Opening browser")
 		openBrowser(url)
 	}
 
 	// Start server using the listener we already have
+	server := newServer(mux)
+	shutdown := make(chan struct{})
+	var shutdownOnce sync.Once
+	apiHandler.SetShutdownFunc(func() {
+		shutdownOnce.Do(func() { close(shutdown) })
+	})
+
 	if useHTTPS {
-		fmt.Printf("Starting HTTPS server on %s:%d\n", cfg.Server.Host, cfg.Server.Port)
+		slog.Info("Starting HTTPS server", "host", cfg.Server.Host, "port", cfg.Server.Port)
 
 		var tlsConfig *tls.Config
 		if cfg.Server.SSL.Cert != "" && cfg.Server.SSL.Key != "" {
@@ -262,36 +315,159 @@ func main() {
 			tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
 		} else {
 			// Generate self-signed certificate
-			fmt.Println("Generating self-signed certificate...")
+			slog.Info("Generating self-signed certificate")
 			cert, err := generateSelfSignedCert(cfg.Server.Host, displayHost)
 			if err != nil {
 				log.Fatalf("Error generating certificate: %v", err)
 			}
 			tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
-			fmt.Println("Warning: Using self-signed certificate. Browser will show security warning.")
+			slog.Warn("Using self-signed certificate. Browser will show security warning.")
 		}
 
-		tlsListener := tls.NewListener(listener, tlsConfig)
-		err = http.Serve(tlsListener, mux)
+		listener = tls.NewListener(listener, tlsConfig)
 	} else {
-		fmt.Printf("Starting HTTP server on %s:%d\n", cfg.Server.Host, cfg.Server.Port)
-		err = http.Serve(listener, mux)
+		slog.Info("Starting HTTP server", "host", cfg.Server.Host, "port", cfg.Server.Port)
 	}
 
-	if err != nil {
+	watchReload(func() { reloadConfig(cfgPath, apiHandler, authMgr) })
+
+	if err := runServer(server, listener, shutdown); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
 
+// applyCollectorConfig passes the collector settings of cfg on to the
+// collectors package; used at startup and on reload
+func applyCollectorConfig(cfg *config.Config) {
+	// Limit external IP enrichment (whois, GeoIP, reverse DNS). Both keep
+	// the budget and cached results when a reload leaves them unchanged.
+	collectors.SetIPLookupRate(cfg.IPLookup.RatePerMinute)
+	collectors.SetIPLookupCache(time.Duration(cfg.IPLookup.CacheTTL)*time.Second, cfg.IPLookup.CacheSize)
+	collectors.SetFeatures(collectors.Features{
+		CPUTemps:            cfg.Collectors.CPU.IncludeTemps,
+		ProcessFDs:          cfg.Collectors.Processes.IncludeFDs,
+		ProcessSocketCounts: cfg.Collectors.Processes.IncludeSocketCounts,
+		SocketProcesses:     cfg.Collectors.Sockets.IncludeProcesses,
+		DockerStats:         cfg.Collectors.Docker.IncludeStats,
+	})
+	collectors.SetDiskFilter(collectors.DiskFilter{
+		Network: cfg.Collectors.Disk.IncludeNetwork,
+		Virtual: cfg.Collectors.Disk.IncludeVirtual,
+	})
+	collectors.SetTimeouts(collectors.Timeouts{
+		Docker:        time.Duration(cfg.Timeouts.Docker) * time.Second,
+		DockerLogs:    time.Duration(cfg.Timeouts.DockerLogs) * time.Second,
+		DockerAction:  time.Duration(cfg.Timeouts.DockerAction) * time.Second,
+		Whois:         time.Duration(cfg.Timeouts.Whois) * time.Second,
+		GeoIP:         time.Duration(cfg.Timeouts.GeoIP) * time.Second,
+		Services:      time.Duration(cfg.Timeouts.Services) * time.Second,
+		ServiceAction: time.Duration(cfg.Timeouts.ServiceAction) * time.Second,
+		Commands:      time.Duration(cfg.Timeouts.Commands) * time.Second,
+	})
+}
+
+// authUsers converts the configured accounts for the auth manager
+func authUsers(cfg config.AuthConfig) []auth.User {
+	var users []auth.User
+	for _, u := range cfg.AllUsers() {
+		users = append(users, auth.User{Username: u.Username, Password: u.Password, Role: auth.Role(u.Role)})
+	}
+	return users
+}
+
+// apiTokens converts the configured API tokens for the auth manager
+func apiTokens(tokens []config.APITokenConfig) []auth.APIToken {
+	out := make([]auth.APIToken, len(tokens))
+	for i, t := range tokens {
+		out[i] = auth.APIToken{Name: t.Name, Token: t.Token, ReadWrite: t.ReadWrite}
+	}
+	return out
+}
+
+// reloadConfig re-reads the config file and applies what can change
+// without a restart. A file that fails to load leaves everything as it
+// was.
+func reloadConfig(cfgPath string, apiHandler *api.API, authMgr *auth.AuthManager) {
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		slog.Error("Config reload failed, keeping the current config", "err", err)
+		return
+	}
+	if !cfg.HasAnyAuth() && !authMgr.IsPublic() && !authMgr.IsAdminMode() {
+		slog.Error("Config reload failed, keeping the current config", "err", "no users configured")
+		return
+	}
+
+	applyCollectorConfig(cfg)
+	authMgr.SetUsers(authUsers(cfg.Auth))
+	authMgr.SetLoginLimits(
+		cfg.Auth.MaxLoginAttempts,
+		time.Duration(cfg.Auth.LoginWindow)*time.Second,
+		time.Duration(cfg.Auth.LoginLockout)*time.Second,
+	)
+	authMgr.SetTrustProxy(cfg.Auth.TrustProxy)
+	authMgr.SetSessionTTL(time.Duration(cfg.Auth.SessionTTLHours)*time.Hour, cfg.Auth.SlidingExpiration)
+	authMgr.SetAPITokens(apiTokens(cfg.Auth.Tokens))
+	apiHandler.ReloadConfig(cfg)
+
+	slog.Info("Config reloaded", "sources", cfg.Sources)
+}
+
+// newServer wraps mux in an http.Server whose request contexts are all
+// cancelled when shutdown starts, so SSE streams and log tails return
+// instead of holding Shutdown open until the grace period runs out.
+func newServer(mux http.Handler) *http.Server {
+	baseCtx, cancel := context.WithCancel(context.Background())
+	server := &http.Server{
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+	server.RegisterOnShutdown(cancel)
+	return server
+}
+
+// runServer serves on listener until it fails, SIGINT/SIGTERM arrives or
+// shutdown is closed, then drains in-flight requests for up to
+// shutdownTimeout before closing whatever is left.
+func runServer(server *http.Server, listener net.Listener, shutdown <-chan struct{}) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case sig := <-signals:
+		slog.Info("Shutting down", "signal", sig.String())
+	case <-shutdown:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("Graceful shutdown incomplete", "err", err)
+		server.Close()
+	}
+	return nil
+}
+
 func serveIndex(w http.ResponseWriter, r *http.Request, cfg *config.Config) {
 	// Read the template
-	tmpl, err := embeddedFS.ReadFile("templates/index.html")
+	tmpl, err := versionedIndex()
 	if err != nil {
 		http.Error(w, "Internal error", http.StatusInternalServerError)
 		return
 	}
 
+	// Always revalidate the HTML so upgrades pick up the new asset URLs
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
 	w.Write(tmpl)
 }
 
@@ -310,8 +486,7 @@ func openBrowser(url string) {
 	}
 
 	if err != nil {
-		fmt.Printf("Could not open browser: %v\n", err)
-		fmt.Printf("Please open %s manually\n", url)
+		slog.Warn("Could not open browser, please open the URL manually", "url", url, "err", err)
 	}
 }
 
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"time"
+
+	"syspeek/auth"
+	"syspeek/collectors"
+	"syspeek/config"
+)
+
+// startMaintenance runs the periodic cleanup of sessions, caches and
+// previous-sample maps so long-running instances don't grow unbounded.
+// An interval <= 0 disables that task.
+func startMaintenance(cfg config.MaintenanceConfig, authMgr *auth.AuthManager) {
+	tasks := []struct {
+		interval int
+		run      func()
+	}{
+		{cfg.SessionInterval, authMgr.CleanupExpiredSessions},
+		{cfg.CacheInterval, collectors.PurgeExpiredCaches},
+		{cfg.SampleInterval, collectors.PurgeStaleSamples},
+	}
+
+	for _, task := range tasks {
+		if task.interval <= 0 {
+			continue
+		}
+		go func(interval time.Duration, run func()) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				run()
+			}
+		}(time.Duration(task.interval)*time.Second, task.run)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"syspeek/auth"
+)
+
+// readPassword reads the password to hash from stdin. On a terminal it
+// prompts on stderr and turns echo off where the platform allows it;
+// otherwise it takes the first line of the piped input.
+func readPassword() (string, error) {
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := readHiddenLine()
+		fmt.Fprintln(os.Stderr)
+		return password, err
+	}
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return "", err
+	}
+	return strings.TrimRight(password, "\r\n"), nil
+}
+
+// hashPassword returns the stored form of password for the config
+func hashPassword(password, algo string) (string, error) {
+	if password == "" {
+		return "", errors.New("empty password")
+	}
+	switch algo {
+	case "bcrypt":
+		return auth.HashPasswordBcrypt(password)
+	case "md5":
+		return auth.HashPassword(password), nil
+	default:
+		return "", fmt.Errorf("unknown algorithm %q, use bcrypt or md5", algo)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// readHiddenLine reads a line from the console. Echo stays on: turning it
+// off needs the console API, and piping the password in avoids it.
+func readHiddenLine() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchReload calls reload every time the process receives SIGHUP
+func watchReload(reload func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			slog.Info("Reloading config", "signal", "SIGHUP")
+			reload()
+		}
+	}()
+}
@@ -0,0 +1,6 @@
+//go:build windows
+
+package main
+
+// watchReload is a no-op on Windows, which has no SIGHUP
+func watchReload(reload func()) {}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+)
+
+// Static assets are embedded, so they can only change together with the
+// binary. Asset URLs in the index carry ?v=<Version>; those requests are
+// cached for good, anything else revalidates against an ETag.
+
+var (
+	staticRefRegex = regexp.MustCompile(`(href|src)="/static/([^"?]+)"`)
+
+	indexOnce sync.Once
+	indexHTML []byte
+	indexErr  error
+)
+
+// versionedIndex returns index.html with ?v=<Version> appended to every
+// literal /static/ reference
+func versionedIndex() ([]byte, error) {
+	indexOnce.Do(func() {
+		tmpl, err := embeddedFS.ReadFile("templates/index.html")
+		if err != nil {
+			indexErr = err
+			return
+		}
+		indexHTML = staticRefRegex.ReplaceAll(tmpl, []byte(`$1="/static/$2?v=`+Version+`"`))
+	})
+	return indexHTML, indexErr
+}
+
+// staticCacheHandler adds cache headers to the embedded file server.
+// http.FileServer honours If-None-Match against the ETag set here.
+func staticCacheHandler(h http.Handler) http.Handler {
+	etag := `"` + Version + `"`
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if r.URL.Query().Get("v") == Version {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -146,6 +146,7 @@ const app = createApp({
         const containerLogsTail = ref(100);
         const containerTop = ref([]);
         const containerTopLoading = ref(false);
+        const containerHistory = ref(null);
         const containerInspect = ref('');
         const showInspectModal = ref(false);
 
@@ -159,6 +160,8 @@ const app = createApp({
         const serviceLogs = ref('');
         const serviceLogsLoading = ref(false);
         const serviceLogLines = ref(100);
+        const serviceLogPriority = ref('');
+        const serviceLogGrep = ref('');
         const servicesLoading = ref(false);
 
         // Service PID (to prevent self-kill)
@@ -297,8 +300,8 @@ const app = createApp({
 
             // Sort
             svcs = [...svcs].sort((a, b) => {
-                let aVal = a[serviceSortKey.value];
-                let bVal = b[serviceSortKey.value];
+                let aVal = a[serviceSortKey.value] ?? 0;
+                let bVal = b[serviceSortKey.value] ?? 0;
                 if (typeof aVal === 'string') aVal = aVal.toLowerCase();
                 if (typeof bVal === 'string') bVal = bVal.toLowerCase();
                 if (aVal < bVal) return serviceSortAsc.value ? -1 : 1;
@@ -672,6 +675,29 @@ const app = createApp({
             }
         };
 
+        const addUserToGroup = async (groupname) => {
+            const username = prompt(`Add which user to group ${groupname}?`)?.trim();
+            if (!username) return;
+
+            try {
+                const res = await fetch(`/api/group/${encodeURIComponent(groupname)}/add`, {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ username })
+                });
+                const data = await res.json();
+                if (data.success) {
+                    showToast(`Added ${username} to ${groupname}`, 'success');
+                    selectedGroup.value = { ...selectedGroup.value, members: data.members };
+                    groupMembers.value = data.members;
+                } else {
+                    showToast(data.message || 'Failed to add user to group', 'error');
+                }
+            } catch (e) {
+                showToast('Error: ' + e.message, 'error');
+            }
+        };
+
         // User modification
         const modifyUser = async (username, field, value) => {
             try {
@@ -762,6 +788,7 @@ const app = createApp({
                     // Auto-load processes and logs if container is running
                     if (selectedContainer.value.state === 'running') {
                         fetchContainerTop(containerId);
+                        fetchContainerHistory(containerId);
                     }
                     fetchContainerLogs(containerId);
                 } else {
@@ -776,7 +803,7 @@ const app = createApp({
         };
 
         const dockerAction = async (containerId, action) => {
-            const actionNames = { stop: 'Stop', start: 'Start', restart: 'Restart', kill: 'Kill', pause: 'Pause', unpause: 'Unpause' };
+            const actionNames = { stop: 'Stop', start: 'Start', restart: 'Restart', kill: 'Kill', pause: 'Pause', unpause: 'Unpause', remove: 'Remove' };
             if (!confirm(`${actionNames[action]} container ${containerId.substring(0, 12)}?`)) return;
 
             try {
@@ -785,8 +812,11 @@ const app = createApp({
                 });
                 const data = await res.json();
                 if (data.success) {
-                    showToast(`Container ${action}ed successfully`, 'success');
-                    if (selectedContainer.value) {
+                    showToast(action === 'remove' ? 'Container removed' : `Container ${action}ed successfully`, 'success');
+                    if (action === 'remove') {
+                        selectedContainer.value = null;
+                        resetContainerExtended();
+                    } else if (selectedContainer.value) {
                         showContainerDetail(containerId);
                     }
                 } else {
@@ -851,6 +881,30 @@ const app = createApp({
             }
         };
 
+        // Fetch CPU/memory history; it is only recorded when
+        // history.containers is enabled, so failures are silent
+        const fetchContainerHistory = async (containerId) => {
+            try {
+                const res = await fetch(`/api/docker/${encodeURIComponent(containerId)}/stats/history?window=10m`);
+                if (res.ok) {
+                    containerHistory.value = await res.json();
+                }
+            } catch (e) {
+                console.error('Failed to get container history:', e);
+            }
+        };
+
+        // SVG polyline points for samples, scaled to a 100x30 viewBox
+        const sparklinePoints = (samples) => {
+            if (!samples || samples.length < 2) return '';
+            const first = samples[0].time;
+            const span = (samples[samples.length - 1].time - first) || 1;
+            const peak = Math.max(...samples.map(s => s.value)) || 1;
+            return samples.map(s =>
+                `${((s.time - first) / span * 100).toFixed(2)},${(30 - s.value / peak * 28).toFixed(2)}`
+            ).join(' ');
+        };
+
         // Fetch raw inspect JSON
         const fetchContainerInspect = async (containerId) => {
             try {
@@ -872,6 +926,7 @@ const app = createApp({
             containerLogs.value = '';
             containerLogsTail.value = 100;
             containerTop.value = [];
+            containerHistory.value = null;
             containerInspect.value = '';
             showInspectModal.value = false;
         };
@@ -902,7 +957,7 @@ const app = createApp({
         const refreshServices = async () => {
             servicesLoading.value = true;
             try {
-                const res = await fetch('/api/services');
+                const res = await fetch('/api/services?withStats=true');
                 if (res.ok) {
                     services.value = await res.json();
                 }
@@ -918,6 +973,8 @@ const app = createApp({
             serviceLoading.value = true;
             serviceLogs.value = '';
             serviceLogLines.value = 100;
+            serviceLogPriority.value = '';
+            serviceLogGrep.value = '';
 
             try {
                 const res = await fetch(`/api/service/${encodeURIComponent(serviceName)}`);
@@ -966,7 +1023,10 @@ const app = createApp({
             serviceLogsLoading.value = true;
 
             try {
-                const res = await fetch(`/api/service/${encodeURIComponent(serviceName)}/logs?lines=${serviceLogLines.value}`);
+                const params = new URLSearchParams({ lines: serviceLogLines.value });
+                if (serviceLogPriority.value) params.set('priority', serviceLogPriority.value);
+                if (serviceLogGrep.value) params.set('grep', serviceLogGrep.value);
+                const res = await fetch(`/api/service/${encodeURIComponent(serviceName)}/logs?${params}`);
                 if (res.ok) {
                     const data = await res.json();
                     serviceLogs.value = data.logs || '';
@@ -978,7 +1038,8 @@ const app = createApp({
                         }
                     }, 50);
                 } else {
-                    showToast('Failed to fetch service logs', 'error');
+                    const data = await res.json().catch(() => ({}));
+                    showToast(data.message || 'Failed to fetch service logs', 'error');
                 }
             } catch (e) {
                 showToast('Error: ' + e.message, 'error');
@@ -1409,6 +1470,7 @@ const app = createApp({
             containerLogsTail,
             containerTop,
             containerTopLoading,
+            containerHistory,
             containerInspect,
             showInspectModal,
 
@@ -1423,6 +1485,8 @@ const app = createApp({
             selectedService,
             serviceLoading,
             serviceLogs,
+            serviceLogPriority,
+            serviceLogGrep,
             serviceLogsLoading,
             serviceLogLines,
             servicesLoading,
@@ -1461,6 +1525,7 @@ const app = createApp({
             isServiceProcess,
             showGroupInfo,
             removeUserFromGroup,
+            addUserToGroup,
             modifyUser,
             quickKillProcess,
             toggleProcessPause,
@@ -1482,6 +1547,8 @@ const app = createApp({
             fetchContainerLogs,
             loadMoreLogs,
             fetchContainerTop,
+            fetchContainerHistory,
+            sparklinePoints,
             fetchContainerInspect,
             resetContainerExtended,
 